@@ -219,6 +219,38 @@ const (
 	WarningHealthReason = "Warning"
 	// CriticalHealthReason is the reason used when BMC reports critical errors.
 	CriticalHealthReason = "CriticalError"
+
+	// ImageAuthInUseCondition documents whether registry credentials from
+	// the image's OCI auth secret are passed on when provisioning.
+	ImageAuthInUseCondition = "ImageAuthInUse"
+	// ImageAuthCredentialsInjectedReason is the reason used when
+	// credentials from the OCI auth secret are passed on to the provisioner.
+	ImageAuthCredentialsInjectedReason = "CredentialsInjected"
+	// ImageAuthNotApplicableReason is the reason used when the host image is
+	// not an OCI image, so registry authentication does not apply.
+	ImageAuthNotApplicableReason = "NotApplicable"
+	// ImageAuthNotRequiredReason is the reason used when the host image is an
+	// OCI image without an auth secret.
+	ImageAuthNotRequiredReason = "NotRequired"
+	// ImageAuthNoAuthExpectedReason is the reason used when the host image
+	// is an OCI image deliberately pulled without authentication.
+	ImageAuthNoAuthExpectedReason = "NoAuthExpected"
+	// ImageAuthValidationSkippedReason is the reason used when image auth
+	// validation is disabled for the host, so no credentials are passed on.
+	ImageAuthValidationSkippedReason = "ValidationSkipped"
+
+	// ImageAuthHealthyCondition rolls up every image authentication check.
+	// It is True only when ImageAuthInUse is True and validation reported no
+	// warnings, so unlike ImageAuthInUse it also turns False on advisory
+	// problems, such as weak or stale credentials, that do not block
+	// provisioning. It is not set when no auth secret is used.
+	ImageAuthHealthyCondition = "ImageAuthHealthy"
+	// ImageAuthHealthyReason is the reason used when the credentials are in
+	// use without warnings.
+	ImageAuthHealthyReason = "Healthy"
+	// ImageAuthWarningsReason is the reason used when the credentials are in
+	// use but validation reported warnings.
+	ImageAuthWarningsReason = "Warnings"
 )
 
 // OperationalStatus represents the state of the host.
@@ -683,6 +715,24 @@ type CredentialsStatus struct {
 	Version   string                  `json:"credentialsVersion,omitempty"`
 }
 
+// ImageAuthStatus records the inputs of the last successful image
+// authentication validation, so that it is not repeated while they are
+// unchanged.
+type ImageAuthStatus struct {
+	// ImageURL is the image the credentials were validated for.
+	ImageURL string `json:"imageURL"`
+	// SecretResourceVersion is the resourceVersion of the auth secret the
+	// credentials were read from.
+	SecretResourceVersion string `json:"secretResourceVersion"`
+	// InputsHash is a hash of the other inputs that change the outcome of
+	// the validation: the host registry mirrors, secret UID pin and
+	// registry CA secret, including the version of the latter.
+	InputsHash string `json:"inputsHash,omitempty"`
+	// CredentialFingerprint is a salted hash of the validated credentials.
+	// The salt changes when the controller restarts.
+	CredentialFingerprint string `json:"credentialFingerprint"`
+}
+
 // RebootMode defines known variations of reboot modes.
 type RebootMode string
 
@@ -794,6 +844,11 @@ type BareMetalHostStatus struct {
 	// The last credentials we sent to the provisioning backend.
 	TriedCredentials CredentialsStatus `json:"triedCredentials,omitempty"`
 
+	// The last image authentication validation that succeeded, recorded
+	// when the controller is configured to reuse valid results.
+	// +optional
+	ImageAuth *ImageAuthStatus `json:"imageAuth,omitempty"`
+
 	// The last error message reported by the provisioning subsystem.
 	ErrorMessage string `json:"errorMessage"`
 
@@ -307,6 +307,11 @@ func (in *BareMetalHostStatus) DeepCopyInto(out *BareMetalHostStatus) {
 	in.Provisioning.DeepCopyInto(&out.Provisioning)
 	in.GoodCredentials.DeepCopyInto(&out.GoodCredentials)
 	in.TriedCredentials.DeepCopyInto(&out.TriedCredentials)
+	if in.ImageAuth != nil {
+		in, out := &in.ImageAuth, &out.ImageAuth
+		*out = new(ImageAuthStatus)
+		**out = **in
+	}
 	in.OperationHistory.DeepCopyInto(&out.OperationHistory)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1504,6 +1509,21 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageAuthStatus) DeepCopyInto(out *ImageAuthStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageAuthStatus.
+func (in *ImageAuthStatus) DeepCopy() *ImageAuthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageAuthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LLDP) DeepCopyInto(out *LLDP) {
 	*out = *in
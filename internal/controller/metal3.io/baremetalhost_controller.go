@@ -24,6 +24,7 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -39,6 +40,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -47,6 +49,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -56,6 +59,10 @@ const (
 	preprovImageRetryDelay        = time.Minute * 5
 	provisionerNotReadyRetryDelay = time.Second * 30
 	subResourceNotReadyRetryDelay = time.Second * 60
+	imageAuthSecretPendingDelay   = time.Second * 15
+	imageAuthTruncatedDataDelay   = time.Second * 5
+	imageAuthDeferredDelay        = time.Second * 30
+	imageAuthNotDecryptedDelay    = time.Second * 15
 	clarifySoftPoweroffFailure    = "Continuing with hard poweroff after soft poweroff fails. More details: "
 	hardwareDataFinalizer         = metal3api.BareMetalHostFinalizer + "/hardwareData"
 	NotReady                      = "Not ready"
@@ -68,6 +75,90 @@ type BareMetalHostReconciler struct {
 	ProvisionerFactory provisioner.Factory
 	APIReader          client.Reader
 	Recorder           record.EventRecorder
+
+	// ImageAuthRegistryChecker, when set, enables live validation of image
+	// auth credentials against the registry.
+	ImageAuthRegistryChecker RegistryChecker
+	// ImageAuthRevalidationInterval, when positive and live validation is
+	// enabled, periodically re-checks the image auth credentials of
+	// provisioned hosts so that credentials revoked by the registry are
+	// detected even though the secret did not change.
+	ImageAuthRevalidationInterval time.Duration
+	// ImageAuthReportNotApplicable sets the ImageAuthInUse condition for
+	// hosts that do not use registry credentials as well, with a reason
+	// telling non-OCI images apart from OCI images without an auth secret.
+	ImageAuthReportNotApplicable bool
+	// ImageAuthWatchSecrets requeues the hosts referencing an image auth
+	// secret whenever the type or data of that secret changes, so that they
+	// are validated again.
+	ImageAuthWatchSecrets bool
+	// ImageAuthDeferCredentialHelpers waits for credentials, instead of
+	// failing the host, when the image auth secret only names a credential
+	// helper for the image registry.
+	ImageAuthDeferCredentialHelpers bool
+	// ImageAuthSecretMapping, when set, names a ConfigMap mapping registry
+	// hosts to image auth secrets, used for hosts whose image does not set
+	// an OCI auth secret.
+	ImageAuthSecretMapping *types.NamespacedName
+	// ImageAuthDNSCheck, when set, checks that the registry host resolves
+	// before the image auth secret is validated.
+	ImageAuthDNSCheck *RegistryDNSCheck
+	// ImageAuthMaxCredentialAge, when set, flags image auth secrets whose
+	// rotation annotation is older than this.
+	ImageAuthMaxCredentialAge time.Duration
+	// ImageAuthReuseValidResult records valid image auth results in the
+	// host status and skips validating the image auth secret again while
+	// the image URL, the secret and the other inputs are unchanged.
+	ImageAuthReuseValidResult bool
+	// ImageAuthInjectionEvents emits an event naming the secret
+	// resourceVersion whenever credentials from a new version of the image
+	// auth secret are injected, to correlate provisioning with rotations.
+	ImageAuthInjectionEvents bool
+	// ImageAuthCrossNamespaceSecrets lists the namespaces OCI auth secret
+	// names of the form "namespace/name" may reference a secret in, besides
+	// the host namespace. Empty disables cross-namespace references.
+	ImageAuthCrossNamespaceSecrets []string
+	// ImageAuthRecordValidatedAt records when image auth credentials are put
+	// in use on the host, so that a secret created since then is noted in
+	// the ImageAuthInUse condition.
+	ImageAuthRecordValidatedAt bool
+	// ImageAuthFreshSecretRead reads image auth secrets from the API server
+	// instead of the cache, trading load for freshness after rotations.
+	ImageAuthFreshSecretRead bool
+	// ImageAuthEventsPerHostPerMinute caps the image auth events emitted for
+	// a single host per minute. Zero uses the default of 5, and a negative
+	// value disables the cap.
+	ImageAuthEventsPerHostPerMinute int
+	// ImageAuthTrustedRegistries, when not empty, is the allowlist of
+	// registries host images may be pulled from with credentials. Hosts
+	// whose image is pulled from another registry are not provisioned.
+	ImageAuthTrustedRegistries []string
+	// ImageAuthExtractOptions tunes how image auth credentials are located
+	// in the secret.
+	ImageAuthExtractOptions secretutils.ExtractOptions
+	// ImageAuthSecretLinkPolicy controls how image auth secrets that are not
+	// explicitly linked to their host are treated.
+	ImageAuthSecretLinkPolicy SecretLinkPolicy
+	// ImageAuthStrictStructure rejects image auth secrets whose Docker config
+	// holds unexpected keys or types.
+	ImageAuthStrictStructure bool
+	// ImageAuthCredentialStrength, when set, checks image auth credentials
+	// against a policy rejecting weak or placeholder passwords.
+	ImageAuthCredentialStrength *CredentialStrengthPolicy
+	// ImageAuthTagPolicy, when set, checks that OCI images are pinned to a
+	// tag other than "latest" or to a digest.
+	ImageAuthTagPolicy *ImageTagPolicy
+
+	// imageAuthRecorder is a sampled view of Recorder used for image auth
+	// events, which can otherwise flood the API on every reconcile.
+	imageAuthRecorder record.EventRecorder
+	// imageAuthSecretMapping is the ImageAuthSecretMapping lookup shared
+	// across reconciles, so that its ConfigMap reads are cached.
+	imageAuthSecretMapping *ImageAuthSecretMapping
+	// imageAuthLastChecked records, per host, when the image auth
+	// credentials were last re-validated. Entries are dropped once the
+	// host is gone or no longer re-validated.
+	imageAuthLastChecked sync.Map
 }
 
 // Instead of passing a zillion arguments to the action of a phase,
@@ -93,6 +184,7 @@ func (info *reconcileInfo) publishEvent(reason, message string) {
 // +kubebuilder:rbac:groups=metal3.io,resources=hardwaredata,verbs=get;list;watch;create;delete;patch;update
 // +kubebuilder:rbac:groups=metal3.io,resources=hardware/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;update;patch
 
 // Allow for managing hostfirmwaresettings, firmwareschema, bmceventsubscriptions and hostfirmwarecomponents
@@ -132,6 +224,7 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 			// reconcile request.  Owned objects are automatically
 			// garbage collected. For additional cleanup logic use
 			// finalizers.  Return and don't requeue
+			r.imageAuthLastChecked.Delete(request.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -233,19 +326,31 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 		return ctrl.Result{Requeue: true, RequeueAfter: provisionerNotReadyRetryDelay}, nil
 	}
 
+	// Conditions are compared with their value before the state machine
+	// runs, since actions such as the image auth checks set them without
+	// marking the result dirty.
+	conditionsBefore := slices.Clone(host.GetConditions())
+
 	stateMachine := newHostStateMachine(host, r, prov, haveCreds)
 	actResult := stateMachine.ReconcileState(ctx, info)
 	result, err = actResult.Result()
 
 	if err != nil {
 		err = fmt.Errorf("action %q failed: %w", initialState, err)
+		if imageAuthConditionsChanged(conditionsBefore, host) {
+			// Report why the image cannot be pulled even though the
+			// action is retried as an error.
+			if saveErr := r.saveHostStatus(ctx, host); saveErr != nil {
+				info.log.Info("failed to save image auth conditions", "error", saveErr.Error())
+			}
+		}
 		return result, err
 	}
+	result = r.requeueForImageAuthRevalidation(host, result)
 
 	// Always compute conditions since some (e.g. Healthy) can change
 	// based on external state from Ironic regardless of state machine
 	// transitions.
-	conditionsBefore := slices.Clone(host.GetConditions())
 	computeConditions(ctx, host, prov)
 	conditionsChanged := !reflect.DeepEqual(conditionsBefore, host.GetConditions())
 
@@ -1326,7 +1431,7 @@ func (r *BareMetalHostReconciler) actionProvisioning(ctx context.Context, prov p
 	// Extract OCI auth secret credentials if needed
 	authSecret, err := r.getImageAuthSecret(ctx, info.host, &image)
 	if err != nil {
-		return recordActionFailure(info, metal3api.ProvisioningError, err.Error())
+		return imageAuthFailureResult(info, err)
 	}
 
 	provResult, err := prov.Provision(ctx, provisioner.ProvisionData{
@@ -1932,6 +2037,8 @@ func (r *BareMetalHostReconciler) actionManageSteadyState(ctx context.Context, p
 		return result
 	}
 
+	r.revalidateImageAuth(ctx, info)
+
 	return r.manageHostPower(ctx, prov, info)
 }
 
@@ -2425,21 +2532,249 @@ func (r *BareMetalHostReconciler) getBMCSecretAndSetOwner(ctx context.Context, r
 	return bmcCredsSecret, nil
 }
 
+// imageAuthFailureResult decides how provisioning reacts to an image auth
+// validation failure.
+func imageAuthFailureResult(info *reconcileInfo, err error) actionResult {
+	reason, _ := imageAuthReason(err)
+	switch reason {
+	case ReasonSecretForbidden, ReasonRegistryUnreachable:
+		// Not a problem with the host itself; keep retrying until
+		// the RBAC is fixed or the registry is reachable again.
+		return actionError{err}
+	case ReasonSecretPending:
+		info.log.Info("waiting for image auth secret to be synced", "reason", err.Error())
+		return actionContinue{imageAuthSecretPendingDelay}
+	case ReasonTruncatedData:
+		info.log.Info("image auth secret data is truncated, retrying shortly", "reason", err.Error())
+		return actionContinue{imageAuthTruncatedDataDelay}
+	case ReasonSecretNotDecrypted:
+		info.log.Info("image auth secret is not decrypted yet, retrying shortly", "reason", err.Error())
+		return actionContinue{imageAuthNotDecryptedDelay}
+	case ReasonDeferred:
+		info.log.Info("waiting for image auth credentials from a credential helper", "reason", err.Error())
+		return actionContinue{imageAuthDeferredDelay}
+	default:
+		return recordActionFailure(info, metal3api.ProvisioningError, err.Error())
+	}
+}
+
 // getImageAuthSecret validates and extracts the OCI registry credentials for the image.
 // It returns the base64-encoded credentials in the format expected by Ironic, or an empty
 // string if no auth secret is configured.
 func (r *BareMetalHostReconciler) getImageAuthSecret(ctx context.Context, host *metal3api.BareMetalHost, image *metal3api.Image) (string, error) {
 	if image == nil || !image.IsOCI() {
+		r.setImageAuthNotUsed(host, metal3api.ImageAuthNotApplicableReason, "host image is not an OCI image")
 		return "", nil
 	}
 
-	if image.OCIAuthSecretName == nil || *image.OCIAuthSecretName == "" {
+	noSecret := image.OCIAuthSecretName == nil || *image.OCIAuthSecretName == ""
+	if noSecret && noImageAuthExpected(host) {
+		r.setImageAuthNotUsed(host, metal3api.ImageAuthNoAuthExpectedReason, "host image is pulled without authentication as intended")
+		return "", nil
+	}
+	if noSecret && r.ImageAuthSecretMapping == nil {
+		r.setImageAuthNotUsed(host, metal3api.ImageAuthNotRequiredReason, "host image has no OCI auth secret")
+		return "", nil
+	}
+	if skipImageAuthValidation(host) {
+		r.setImageAuthNotUsed(host, metal3api.ImageAuthValidationSkippedReason, "image auth validation is disabled for the host")
 		return "", nil
 	}
 
 	secretManager := r.secretManager(ctx, r.Log)
-	validator := NewImageAuthValidator(r.Recorder)
-	return validator.Validate(ctx, host, secretManager)
+	recorder := r.imageAuthRecorder
+	if recorder == nil {
+		recorder = r.Recorder
+	}
+	validator := r.imageAuthValidator(recorder)
+	result, err := aggregateImageAuthOutcomes(
+		validator.EvaluateImages(ctx, host, imagesToValidate(host, image), secretManager))
+	if err != nil {
+		host.Status.ImageAuth = nil
+		conditions.Set(host, metav1.Condition{
+			Type:    metal3api.ImageAuthInUseCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(result.Reason),
+			Message: result.Message,
+		})
+	}
+	setImageAuthHealthy(host, result, err)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.recordImageAuthRegistry(ctx, host, recorder, result); err != nil {
+		return "", err
+	}
+
+	if result.Credentials == "" {
+		// Clear the conditions of credentials injected before, such as
+		// when the auth secret was removed from the image.
+		message := "no registry credentials are used for the host image"
+		if noSecret {
+			message = "host image has no OCI auth secret and the secret mapping has none for its registry"
+		}
+		r.setImageAuthNotUsed(host, metal3api.ImageAuthNotRequiredReason, message)
+		return "", nil
+	}
+
+	message := r.imageAuthInUseMessage(result)
+	previous := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	injected := previous == nil || previous.Status != metav1.ConditionTrue || previous.Message != message
+	if r.ImageAuthRecordValidatedAt && (previous == nil || previous.Status != metav1.ConditionTrue) {
+		if err := r.recordImageAuthValidatedAt(ctx, host, time.Now()); err != nil {
+			return "", err
+		}
+	}
+	conditions.Set(host, metav1.Condition{
+		Type:    metal3api.ImageAuthInUseCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  metal3api.ImageAuthCredentialsInjectedReason,
+		Message: message,
+	})
+	if r.ImageAuthInjectionEvents && injected && recorder != nil {
+		recorder.Eventf(host, corev1.EventTypeNormal, EventAuthCredentialsInjected,
+			"Injected credentials for registry %s from secret %s/%s at resourceVersion %s",
+			result.RegistryHost, result.SecretNamespace, result.SecretName, result.SecretResourceVersion)
+	}
+	if r.ImageAuthReuseValidResult {
+		recordImageAuthResult(host, image, result)
+	}
+	return result.Credentials, nil
+}
+
+// imageAuthInUseMessage describes the credentials in use in the
+// ImageAuthInUse condition.
+func (r *BareMetalHostReconciler) imageAuthInUseMessage(result *ImageAuthResult) string {
+	message := result.Provenance()
+	if r.ImageAuthInjectionEvents {
+		message += ", resourceVersion " + result.SecretResourceVersion
+	}
+	if result.StaleCredentials {
+		message += "; the secret exceeds the maximum credential age and should be rotated"
+	}
+	if result.NewerSecretNote != "" {
+		message += "; " + result.NewerSecretNote
+	}
+	return message
+}
+
+// imageAuthValidator returns an image auth validator configured from the
+// reconciler options.
+func (r *BareMetalHostReconciler) imageAuthValidator(recorder record.EventRecorder) *ImageAuthValidator {
+	validator := NewImageAuthValidator(recorder)
+	validator.ExtractOptions = r.ImageAuthExtractOptions
+	validator.SecretLinkPolicy = r.ImageAuthSecretLinkPolicy
+	validator.StrictStructure = r.ImageAuthStrictStructure
+	validator.CredentialStrength = r.ImageAuthCredentialStrength
+	validator.TagPolicy = r.ImageAuthTagPolicy
+	validator.RegistryChecker = r.ImageAuthRegistryChecker
+	validator.DeferCredentialHelpers = r.ImageAuthDeferCredentialHelpers
+	validator.DNSCheck = r.ImageAuthDNSCheck
+	validator.MaxCredentialAge = r.ImageAuthMaxCredentialAge
+	validator.ReuseValidResult = r.ImageAuthReuseValidResult
+	validator.CrossNamespaceSecrets = r.ImageAuthCrossNamespaceSecrets
+	validator.FreshSecretRead = r.ImageAuthFreshSecretRead
+	validator.TrustedRegistries = r.ImageAuthTrustedRegistries
+	validator.SecretMapping = r.imageAuthSecretMapping
+	if validator.SecretMapping == nil && r.ImageAuthSecretMapping != nil {
+		validator.SecretMapping = &ImageAuthSecretMapping{Reader: r.APIReader, ConfigMap: *r.ImageAuthSecretMapping}
+	}
+	return validator
+}
+
+// setImageAuthHealthy derives the ImageAuthHealthy condition from the
+// outcome of image auth validation. The condition is removed when no
+// credentials are used.
+func setImageAuthHealthy(host *metal3api.BareMetalHost, result *ImageAuthResult, err error) {
+	switch {
+	case err != nil:
+		conditions.Set(host, metav1.Condition{
+			Type:    metal3api.ImageAuthHealthyCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(result.Reason),
+			Message: result.Message,
+		})
+	case result.Credentials == "":
+		conditions.Delete(host, metal3api.ImageAuthHealthyCondition)
+	case len(result.Warnings) > 0:
+		conditions.Set(host, metav1.Condition{
+			Type:    metal3api.ImageAuthHealthyCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  metal3api.ImageAuthWarningsReason,
+			Message: strings.Join(result.Warnings, "; "),
+		})
+	default:
+		conditions.Set(host, metav1.Condition{
+			Type:    metal3api.ImageAuthHealthyCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  metal3api.ImageAuthHealthyReason,
+			Message: result.Provenance(),
+		})
+	}
+}
+
+// setImageAuthNotUsed records why no registry credentials are used for the
+// host image, if ImageAuthReportNotApplicable is enabled. Otherwise the image
+// auth conditions left from a previous image, such as an OCI image the host
+// moved away from, are cleared.
+func (r *BareMetalHostReconciler) setImageAuthNotUsed(host *metal3api.BareMetalHost, reason, message string) {
+	host.Status.ImageAuth = nil
+	conditions.Delete(host, metal3api.ImageAuthHealthyCondition)
+	if !r.ImageAuthReportNotApplicable {
+		conditions.Delete(host, metal3api.ImageAuthInUseCondition)
+		return
+	}
+	conditions.Set(host, metav1.Condition{
+		Type:    metal3api.ImageAuthInUseCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// recordImageAuthRegistry compares the registry the image auth credentials
+// were resolved for with the one recorded on the host during the previous
+// reconcile. When the image moved to another registry behind the same secret,
+// an event is emitted, and the new registry is recorded on the host.
+func (r *BareMetalHostReconciler) recordImageAuthRegistry(ctx context.Context, host *metal3api.BareMetalHost, recorder record.EventRecorder, result *ImageAuthResult) error {
+	if result.RegistryHost == "" {
+		return nil
+	}
+	previous := host.Annotations[ImageAuthRegistryAnnotation]
+	if previous == result.RegistryHost {
+		return nil
+	}
+
+	if previous != "" && recorder != nil {
+		recorder.Eventf(host, corev1.EventTypeNormal, EventAuthRegistryChanged,
+			"Image registry changed from %s to %s, credentials re-extracted from secret %s",
+			previous, result.RegistryHost, result.SecretName)
+	}
+
+	// Patch a copy so that pending status changes on the host are not
+	// overwritten by the server response.
+	updated := host.DeepCopy()
+	metav1.SetMetaDataAnnotation(&updated.ObjectMeta, ImageAuthRegistryAnnotation, result.RegistryHost)
+	if err := r.Patch(ctx, updated, client.MergeFrom(host)); err != nil {
+		return fmt.Errorf("failed to record image auth registry: %w", err)
+	}
+	host.Annotations = updated.Annotations
+	host.ResourceVersion = updated.ResourceVersion
+	return nil
+}
+
+// recordImageAuthValidatedAt records on the host when its image auth
+// credentials were put in use.
+func (r *BareMetalHostReconciler) recordImageAuthValidatedAt(ctx context.Context, host *metal3api.BareMetalHost, now time.Time) error {
+	updated := host.DeepCopy()
+	metav1.SetMetaDataAnnotation(&updated.ObjectMeta, ImageAuthValidatedAtAnnotation, now.UTC().Format(time.RFC3339))
+	if err := r.Patch(ctx, updated, client.MergeFrom(host)); err != nil {
+		return fmt.Errorf("failed to record image auth validation time: %w", err)
+	}
+	host.Annotations = updated.Annotations
+	host.ResourceVersion = updated.ResourceVersion
+	return nil
 }
 
 func credentialsFromSecret(bmcCredsSecret *corev1.Secret) *bmc.Credentials {
@@ -2528,6 +2863,18 @@ func (r *BareMetalHostReconciler) updateEventHandler(e event.UpdateEvent) bool {
 // SetupWithManager registers the reconciler to be run by the manager.
 func (r *BareMetalHostReconciler) SetupWithManager(mgr ctrl.Manager, preprovImgEnable bool, maxConcurrentReconcile int) error {
 	r.Recorder = mgr.GetEventRecorderFor("baremetalhost-controller")
+	eventsPerMinute := r.ImageAuthEventsPerHostPerMinute
+	if eventsPerMinute == 0 {
+		eventsPerMinute = defaultImageAuthEventsPerHostPerMinute
+	}
+	r.imageAuthRecorder = NewSamplingEventRecorder(r.Recorder, eventsPerMinute)
+	if r.ImageAuthSecretMapping != nil {
+		r.imageAuthSecretMapping = &ImageAuthSecretMapping{
+			Reader:    r.APIReader,
+			ConfigMap: *r.ImageAuthSecretMapping,
+			CacheTTL:  defaultImageAuthSecretMappingTTL,
+		}
+	}
 
 	controller := ctrl.NewControllerManagedBy(mgr).
 		For(&metal3api.BareMetalHost{}).
@@ -2538,6 +2885,16 @@ func (r *BareMetalHostReconciler) SetupWithManager(mgr ctrl.Manager, preprovImgE
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconcile}).
 		Owns(&corev1.Secret{}, builder.MatchEveryOwner)
 
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &metal3api.BareMetalHost{},
+		dependentSecretsIndexField, indexDependentSecrets); err != nil {
+		return err
+	}
+	if r.ImageAuthWatchSecrets {
+		controller.Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findBMHsForAuthSecret),
+			builder.WithPredicates(predicate.Funcs{UpdateFunc: imageAuthSecretChanged}))
+	}
+
 	if preprovImgEnable {
 		// We use SetControllerReference() to set the owner reference, so no
 		// need to pass MatchEveryOwner
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"testing"
 	"time"
@@ -23,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -140,7 +143,8 @@ func newDefaultHost(t *testing.T) *metal3api.BareMetalHost {
 
 func newTestReconcilerWithFixture(t *testing.T, fix *fixture.Fixture, initObjs ...runtime.Object) *BareMetalHostReconciler {
 	t.Helper()
-	clientBuilder := fakeclient.NewClientBuilder().WithRuntimeObjects(initObjs...)
+	clientBuilder := fakeclient.NewClientBuilder().WithRuntimeObjects(initObjs...).
+		WithIndex(&metal3api.BareMetalHost{}, dependentSecretsIndexField, indexDependentSecrets)
 	for _, v := range initObjs {
 		object, ok := v.(client.Object)
 		require.True(t, ok, "failed to cast object to client.Object")
@@ -3431,6 +3435,111 @@ func TestGetImageAuthSecret_OCIImageWithValidSecret(t *testing.T) {
 	assert.Equal(t, "testuser:testpass", string(decoded))
 }
 
+// TestGetImageAuthSecret_ProvenanceCondition tests that the ImageAuthInUse
+// condition records where the injected credentials came from.
+func TestGetImageAuthSecret_ProvenanceCondition(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+
+	credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	require.NotEmpty(t, credentials)
+
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond, "ImageAuthInUse condition should be set")
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, metal3api.ImageAuthCredentialsInjectedReason, cond.Reason)
+	assert.Equal(t, "credentials from secret "+namespace+"/oci-auth-secret, key registry.example.com", cond.Message)
+}
+
+// TestGetImageAuthSecret_ProvenanceDockerHubKey tests that the provenance
+// names a Docker Hub auths key as stored in the secret rather than its
+// canonical form.
+func TestGetImageAuthSecret_ProvenanceDockerHubKey(t *testing.T) {
+	host := newDefaultHost(t)
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://docker.io/library/busybox:latest",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"https://index.docker.io/v1/": {"username": "hubuser", "password": "hubpass"},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond, "ImageAuthInUse condition should be set")
+	assert.Equal(t, "credentials from secret "+namespace+"/oci-auth-secret, key https://index.docker.io/v1/", cond.Message)
+}
+
+// TestGetImageAuthSecret_RegistryChanged tests that moving the image to
+// another registry behind the same secret is detected and that the credentials
+// are re-extracted for the new registry.
+func TestGetImageAuthSecret_RegistryChanged(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://quay.io/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"quay.io": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+		"registry.redhat.io": {
+			"username": "rhuser",
+			"password": "rhpass",
+		},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+
+	credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("quayuser:quaypass")), credentials)
+	assert.Equal(t, "quay.io", host.Annotations[ImageAuthRegistryAnnotation])
+	assert.Empty(t, recorder.Events, "no event expected on first resolution")
+
+	host.Spec.Image.URL = "oci://registry.redhat.io/repo/image:tag"
+	credentials, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("rhuser:rhpass")), credentials)
+
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, EventAuthRegistryChanged)
+		assert.Contains(t, event, "from quay.io to registry.redhat.io")
+	default:
+		t.Error("expected a registry changed event")
+	}
+
+	stored := &metal3api.BareMetalHost{}
+	require.NoError(t, r.Get(t.Context(), client.ObjectKeyFromObject(host), stored))
+	assert.Equal(t, "registry.redhat.io", stored.Annotations[ImageAuthRegistryAnnotation])
+}
+
 // TestGetImageAuthSecret_OCIImageWithoutSecret tests that no error occurs
 // when an OCI image does not have an auth secret configured.
 func TestGetImageAuthSecret_OCIImageWithoutSecret(t *testing.T) {
@@ -3500,6 +3609,392 @@ func TestGetImageAuthSecret_OCIImageWithMissingSecret(t *testing.T) {
 	assert.Empty(t, credentials, "expected empty credentials for missing secret")
 }
 
+// TestGetImageAuthSecret_ExternallyManagedSecretPending tests that a missing
+// secret managed by an external controller is retried shortly instead of
+// failing provisioning.
+func TestGetImageAuthSecret_ExternallyManagedSecretPending(t *testing.T) {
+	for _, managed := range []bool{false, true} {
+		host := newDefaultHost(t)
+		host.Spec.Online = true
+		ociAuthSecretName := "synced-secret"
+		host.Spec.Image = &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &ociAuthSecretName,
+		}
+		if managed {
+			host.Annotations = map[string]string{ImageAuthSecretManagedByAnnotation: "external-secrets"}
+		}
+
+		r := newTestReconciler(t, host)
+
+		_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+		require.Error(t, err)
+
+		actResult := imageAuthFailureResult(makeReconcileInfo(host), err)
+		if !managed {
+			assert.IsType(t, actionFailed{}, actResult)
+			continue
+		}
+
+		reason, _ := imageAuthReason(err)
+		assert.Equal(t, ReasonSecretPending, reason)
+		assert.Contains(t, err.Error(), "external-secrets")
+		require.IsType(t, actionContinue{}, actResult)
+		result, resultErr := actResult.Result()
+		require.NoError(t, resultErr)
+		assert.Equal(t, imageAuthSecretPendingDelay, result.RequeueAfter)
+		assert.Empty(t, host.Status.ErrorMessage, "pending secret must not put the host in error")
+	}
+}
+
+// TestGetImageAuthSecret_TruncatedSecretData tests that a secret whose docker
+// config was truncated mid-update is retried shortly instead of failing the
+// host.
+func TestGetImageAuthSecret_TruncatedSecretData(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "truncated-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	data := ociSecret.Data[corev1.DockerConfigJsonKey]
+	ociSecret.Data[corev1.DockerConfigJsonKey] = data[:len(data)-5]
+
+	r := newTestReconciler(t, host, ociSecret)
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.Error(t, err)
+	reason, _ := imageAuthReason(err)
+	assert.Equal(t, ReasonTruncatedData, reason)
+	assert.Contains(t, err.Error(), "middle of an update")
+
+	actResult := imageAuthFailureResult(makeReconcileInfo(host), err)
+	require.IsType(t, actionContinue{}, actResult)
+	result, resultErr := actResult.Result()
+	require.NoError(t, resultErr)
+	assert.Equal(t, imageAuthTruncatedDataDelay, result.RequeueAfter)
+	assert.Empty(t, host.Status.ErrorMessage, "truncated data must not put the host in error")
+}
+
+// TestGetImageAuthSecret_SecretNotDecrypted tests that a secret still
+// holding ciphertext, such as a sealed secret not yet decrypted, is retried
+// shortly instead of failing the host.
+func TestGetImageAuthSecret_SecretNotDecrypted(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "sealed-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	ociSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ociAuthSecretName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: {0x85, 0x02, 0x0c, 0x03, 0xa7, 0x1f, 0x00, 0xd4, 0x9b, 0x11, 0xfe, 0x42},
+		},
+	}
+
+	r := newTestReconciler(t, host, ociSecret)
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.Error(t, err)
+	reason, _ := imageAuthReason(err)
+	assert.Equal(t, ReasonSecretNotDecrypted, reason)
+	assert.Contains(t, err.Error(), "looks encrypted")
+
+	actResult := imageAuthFailureResult(makeReconcileInfo(host), err)
+	require.IsType(t, actionContinue{}, actResult)
+	result, resultErr := actResult.Result()
+	require.NoError(t, resultErr)
+	assert.Equal(t, imageAuthNotDecryptedDelay, result.RequeueAfter)
+	assert.Empty(t, host.Status.ErrorMessage, "encrypted data must not put the host in error")
+}
+
+// TestGetImageAuthSecret_ReportNotApplicable tests that, when enabled, the
+// ImageAuthInUse condition tells non-OCI images apart from OCI images without
+// an auth secret.
+// TestImageAuthFailurePersistsConditions tests that the image auth
+// conditions are saved to the host status when provisioning waits on, or
+// retries after, an image auth failure without putting the host in error.
+func TestImageAuthFailurePersistsConditions(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	validSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	encryptedSecret := validSecret.DeepCopy()
+	encryptedSecret.Data = map[string][]byte{
+		corev1.DockerConfigJsonKey: {0x85, 0x02, 0x0c, 0x03, 0xa7, 0x1f, 0x00, 0xd4, 0x9b, 0x11, 0xfe, 0x42},
+	}
+
+	testCases := []struct {
+		name           string
+		secret         *corev1.Secret
+		checkErr       error
+		expectErr      bool
+		expectedReason ImageAuthReason
+	}{
+		{
+			name:           "retried later",
+			secret:         encryptedSecret,
+			expectedReason: ReasonSecretNotDecrypted,
+		},
+		{
+			name:           "retried as an error",
+			secret:         validSecret,
+			checkErr:       &RegistryUnreachableError{Host: "registry.example.com", Err: errors.New("connection refused")},
+			expectErr:      true,
+			expectedReason: ReasonRegistryUnreachable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := newDefaultHost(t)
+			host.Spec.Online = true
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &ociAuthSecretName,
+			}
+			host.Finalizers = []string{metal3api.BareMetalHostFinalizer}
+			host.Status.Provisioning.State = metal3api.StateProvisioning
+
+			r := newTestReconciler(t, host, tc.secret)
+			if tc.checkErr != nil {
+				r.ImageAuthRegistryChecker = &fakeRegistryChecker{err: tc.checkErr}
+			}
+
+			// Reconcile until the host is registered and provisioning
+			// reaches the image auth checks.
+			var err error
+			persisted := &metal3api.BareMetalHost{}
+			for range 10 {
+				_, err = r.Reconcile(t.Context(), newRequest(host))
+				require.NoError(t, r.Get(t.Context(), newRequest(host).NamespacedName, persisted))
+				if err != nil || conditions.Has(persisted, metal3api.ImageAuthInUseCondition) {
+					break
+				}
+			}
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, conditionType := range []string{metal3api.ImageAuthInUseCondition, metal3api.ImageAuthHealthyCondition} {
+				condition := conditions.Get(persisted, conditionType)
+				require.NotNil(t, condition, "condition %s was not saved", conditionType)
+				assert.Equal(t, metav1.ConditionFalse, condition.Status)
+				assert.Equal(t, string(tc.expectedReason), condition.Reason)
+			}
+			assert.Empty(t, persisted.Status.ErrorMessage)
+			assert.Equal(t, metal3api.StateProvisioning, persisted.Status.Provisioning.State)
+		})
+	}
+}
+
+func TestGetImageAuthSecret_ReportNotApplicable(t *testing.T) {
+	testCases := []struct {
+		name           string
+		image          *metal3api.Image
+		annotations    map[string]string
+		report         bool
+		expectedReason string
+	}{
+		{
+			name:           "non-OCI image",
+			image:          &metal3api.Image{URL: "http://example.com/image.qcow2"},
+			report:         true,
+			expectedReason: metal3api.ImageAuthNotApplicableReason,
+		},
+		{
+			name:           "OCI image without secret",
+			image:          &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"},
+			report:         true,
+			expectedReason: metal3api.ImageAuthNotRequiredReason,
+		},
+		{
+			name:           "OCI image marked as not needing auth",
+			image:          &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"},
+			annotations:    map[string]string{ImageAuthNotExpectedAnnotation: "true"},
+			report:         true,
+			expectedReason: metal3api.ImageAuthNoAuthExpectedReason,
+		},
+		{
+			name:  "reporting disabled",
+			image: &metal3api.Image{URL: "http://example.com/image.qcow2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := newDefaultHost(t)
+			host.Spec.Image = tc.image
+			host.Annotations = tc.annotations
+			r := newTestReconciler(t, host)
+			r.ImageAuthReportNotApplicable = tc.report
+
+			credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			assert.Empty(t, credentials)
+
+			cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+			if tc.expectedReason == "" {
+				assert.Nil(t, cond, "no condition expected when reporting is disabled")
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, tc.expectedReason, cond.Reason)
+		})
+	}
+}
+
+// TestGetImageAuthSecret_OCIToNonOCITransition tests that moving the host
+// from an OCI image to a non-OCI one does not leave the image auth
+// conditions of the OCI image behind.
+func TestGetImageAuthSecret_OCIToNonOCITransition(t *testing.T) {
+	for _, report := range []bool{false, true} {
+		t.Run(fmt.Sprintf("report not applicable %v", report), func(t *testing.T) {
+			host := newDefaultHost(t)
+			ociAuthSecretName := "oci-auth-secret"
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &ociAuthSecretName,
+			}
+			ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+				"registry.example.com": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			})
+			r := newTestReconciler(t, host, ociSecret)
+			r.ImageAuthReportNotApplicable = report
+
+			_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			require.True(t, conditions.IsTrue(host, metal3api.ImageAuthInUseCondition))
+			require.True(t, conditions.IsTrue(host, metal3api.ImageAuthHealthyCondition))
+
+			host.Spec.Image.URL = "https://example.com/images/image.qcow2"
+			credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			assert.Empty(t, credentials)
+
+			assert.Nil(t, conditions.Get(host, metal3api.ImageAuthHealthyCondition))
+			cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+			if !report {
+				assert.Nil(t, cond, "stale ImageAuthInUse condition should be cleared")
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, metal3api.ImageAuthNotApplicableReason, cond.Reason)
+		})
+	}
+}
+
+// TestGetImageAuthSecret_SkipValidationTransition tests that disabling image
+// auth validation on a host whose credentials were injected does not leave
+// the ImageAuthInUse condition of those credentials behind.
+func TestGetImageAuthSecret_SkipValidationTransition(t *testing.T) {
+	for _, report := range []bool{false, true} {
+		t.Run(fmt.Sprintf("report not applicable %v", report), func(t *testing.T) {
+			host := newDefaultHost(t)
+			ociAuthSecretName := "oci-auth-secret"
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &ociAuthSecretName,
+			}
+			ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+				"registry.example.com": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			})
+			r := newTestReconciler(t, host, ociSecret)
+			r.ImageAuthReportNotApplicable = report
+
+			_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			require.True(t, conditions.IsTrue(host, metal3api.ImageAuthInUseCondition))
+
+			host.Annotations = map[string]string{SkipImageAuthValidationAnnotation: "true"}
+			credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			assert.Empty(t, credentials)
+
+			assert.Nil(t, conditions.Get(host, metal3api.ImageAuthHealthyCondition))
+			assert.False(t, SummarizeImageAuth(host).Valid, "skipped validation should not be summarized as valid")
+			cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+			if !report {
+				assert.Nil(t, cond, "stale ImageAuthInUse condition should be cleared")
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, metal3api.ImageAuthValidationSkippedReason, cond.Reason)
+		})
+	}
+}
+
+// TestGetImageAuthSecret_SecretRemovedWithMapping tests that the conditions
+// of injected credentials are cleared when the auth secret is removed from
+// the image and the secret mapping has none for its registry.
+func TestGetImageAuthSecret_SecretRemovedWithMapping(t *testing.T) {
+	for _, report := range []bool{false, true} {
+		t.Run(fmt.Sprintf("report not applicable %v", report), func(t *testing.T) {
+			host := newDefaultHost(t)
+			ociAuthSecretName := "oci-auth-secret"
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &ociAuthSecretName,
+			}
+			ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+				"registry.example.com": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			})
+			r := newTestReconciler(t, host, ociSecret)
+			r.ImageAuthReportNotApplicable = report
+			r.ImageAuthSecretMapping = &types.NamespacedName{Namespace: namespace, Name: "image-auth-mapping"}
+
+			_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			require.True(t, conditions.IsTrue(host, metal3api.ImageAuthInUseCondition))
+
+			host.Spec.Image.OCIAuthSecretName = nil
+			credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+			assert.Empty(t, credentials)
+
+			assert.Nil(t, conditions.Get(host, metal3api.ImageAuthHealthyCondition))
+			cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+			if !report {
+				assert.Nil(t, cond, "stale ImageAuthInUse condition should be cleared")
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, metal3api.ImageAuthNotRequiredReason, cond.Reason)
+		})
+	}
+}
+
 // TestGetImageAuthSecret_NonOCIImageWithAuthSecret tests that auth secrets
 // are ignored for non-OCI images.
 func TestGetImageAuthSecret_NonOCIImageWithAuthSecret(t *testing.T) {
@@ -3568,6 +4063,121 @@ func TestGetImageAuthSecret_RegistryMismatch(t *testing.T) {
 	assert.Empty(t, credentials, "expected empty credentials when registry doesn't match")
 }
 
+// TestGetImageAuthSecret_TrustedRegistries tests that the trusted registries
+// configured on the reconciler gate the credentials of the host image.
+func TestGetImageAuthSecret_TrustedRegistries(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	r.ImageAuthTrustedRegistries = []string{"quay.io"}
+
+	credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.Error(t, err)
+	assert.Empty(t, credentials)
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond)
+	assert.Equal(t, string(ReasonRegistryNotAllowed), cond.Reason)
+
+	r.ImageAuthTrustedRegistries = []string{"quay.io", "*.example.com"}
+	credentials, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("testuser:testpass")), credentials)
+}
+
+// TestGetImageAuthSecret_ExtractOptions tests that the extract options
+// configured on the reconciler are used to select the credentials.
+func TestGetImageAuthSecret_ExtractOptions(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.internal/quay/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.internal": {
+			"username": "defaultuser",
+			"password": "defaultpass",
+		},
+		"registry.internal/quay": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("defaultuser:defaultpass")), credentials)
+
+	r.ImageAuthExtractOptions.PathPrefixMatching = true
+	credentials, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("quayuser:quaypass")), credentials)
+}
+
+// TestGetImageAuthSecret_ReuseValidResult tests that a valid result is
+// recorded in the host status and reused, without validating the credentials
+// against the registry again, until the validation inputs change.
+func TestGetImageAuthSecret_ReuseValidResult(t *testing.T) {
+	host := newDefaultHost(t)
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {"username": "testuser", "password": "testpass"},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	checker := &fakeRegistryChecker{}
+	r.ImageAuthRegistryChecker = checker
+	r.ImageAuthReuseValidResult = true
+
+	expected := base64.StdEncoding.EncodeToString([]byte("testuser:testpass"))
+	credentials, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, expected, credentials)
+	require.NotNil(t, host.Status.ImageAuth)
+	assert.Equal(t, host.Spec.Image.URL, host.Status.ImageAuth.ImageURL)
+	assert.NotEmpty(t, host.Status.ImageAuth.SecretResourceVersion)
+	assert.NotEmpty(t, host.Status.ImageAuth.CredentialFingerprint)
+	assert.Equal(t, 1, checker.calls)
+
+	credentials, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, expected, credentials)
+	assert.Equal(t, 1, checker.calls, "expected the recorded result to be reused")
+	assert.True(t, conditions.IsTrue(host, metal3api.ImageAuthInUseCondition))
+
+	host.Annotations = map[string]string{ImageAuthMirrorsAnnotation: "mirror.example.com"}
+	_, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Equal(t, 2, checker.calls, "expected a new mirrors annotation to validate again")
+
+	checker.err = &RegistryAuthRejectedError{Host: "registry.example.com", StatusCode: http.StatusUnauthorized}
+	r.ImageAuthRegistryChecker = checker
+	host.Annotations = nil
+	_, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.Error(t, err)
+	assert.Nil(t, host.Status.ImageAuth, "expected a failure to clear the recorded result")
+}
+
 // Helper function to create a dockerconfigjson secret for testing.
 func createDockerConfigJSONSecretForTest(t *testing.T, name, ns string, auths map[string]map[string]string) *corev1.Secret {
 	t.Helper()
@@ -3599,3 +4209,528 @@ func createDockerConfigJSONSecretForTest(t *testing.T, name, ns string, auths ma
 		},
 	}
 }
+
+// TestImageAuthRevalidationRequeue tests that hosts with successfully
+// validated image auth credentials are requeued within the re-validation
+// interval, and only when live validation is enabled.
+func TestImageAuthRevalidationRequeue(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	newHost := func() *metal3api.BareMetalHost {
+		host := newDefaultHost(t)
+		host.Spec.Image = &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &ociAuthSecretName,
+		}
+		host.Status.Provisioning.Image = *host.Spec.Image
+		return host
+	}
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	interval := time.Minute * 30
+
+	testCases := []struct {
+		name            string
+		checker         RegistryChecker
+		interval        time.Duration
+		initial         ctrl.Result
+		expectedRequeue time.Duration
+	}{
+		{
+			name:            "interval configured",
+			checker:         &fakeRegistryChecker{},
+			interval:        interval,
+			expectedRequeue: interval,
+		},
+		{
+			name:            "shorter requeue kept",
+			checker:         &fakeRegistryChecker{},
+			interval:        interval,
+			initial:         ctrl.Result{RequeueAfter: time.Minute},
+			expectedRequeue: time.Minute,
+		},
+		{
+			name:            "longer requeue shortened",
+			checker:         &fakeRegistryChecker{},
+			interval:        interval,
+			initial:         ctrl.Result{RequeueAfter: time.Hour},
+			expectedRequeue: interval,
+		},
+		{
+			name:     "live validation disabled",
+			interval: interval,
+		},
+		{
+			name:    "no interval",
+			checker: &fakeRegistryChecker{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := newHost()
+			r := newTestReconciler(t, host, ociSecret)
+			r.ImageAuthRegistryChecker = tc.checker
+			r.ImageAuthRevalidationInterval = tc.interval
+
+			_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			require.NoError(t, err)
+
+			result := r.requeueForImageAuthRevalidation(host, tc.initial)
+			assert.Equal(t, tc.expectedRequeue, result.RequeueAfter)
+		})
+	}
+}
+
+// TestImageAuthRevalidationPersistsConditions tests that the outcome of the
+// periodic re-validation of a provisioned host is saved to its status.
+func TestImageAuthRevalidationPersistsConditions(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	host.Status.PoweredOn = true
+	host.Status.Provisioning.State = metal3api.StateProvisioned
+	host.Status.Provisioning.Image = *host.Spec.Image
+
+	checker := &fakeRegistryChecker{}
+	r := newTestReconciler(t, host, ociSecret)
+	r.ImageAuthRegistryChecker = checker
+	r.ImageAuthRevalidationInterval = time.Nanosecond
+
+	tryReconcile(t, r, host,
+		func(host *metal3api.BareMetalHost, _ reconcile.Result) bool {
+			return conditions.IsTrue(host, metal3api.ImageAuthInUseCondition)
+		},
+	)
+
+	// Let the host settle so that nothing but the re-validation changes.
+	for range 3 {
+		_, err := r.Reconcile(t.Context(), newRequest(host))
+		require.NoError(t, err)
+	}
+
+	checker.err = &RegistryAuthRejectedError{Host: "registry.example.com", StatusCode: http.StatusUnauthorized}
+	_, err := r.Reconcile(t.Context(), newRequest(host))
+	require.NoError(t, err)
+
+	persisted := &metal3api.BareMetalHost{}
+	require.NoError(t, r.Get(t.Context(), newRequest(host).NamespacedName, persisted))
+	inUse := conditions.Get(persisted, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, inUse)
+	assert.Equal(t, string(ReasonAuthRejected), inUse.Reason)
+	assert.Equal(t, metal3api.StateProvisioned, persisted.Status.Provisioning.State)
+}
+
+// TestImageAuthRevalidationUsesProvisionedImage tests that re-validation
+// checks the image the host was provisioned with rather than its spec image,
+// without recording the registry of either on the host.
+func TestImageAuthRevalidationUsesProvisionedImage(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	host := newDefaultHost(t)
+	host.Annotations = map[string]string{ImageAuthRegistryAnnotation: "registry.example.com"}
+	host.Status.Provisioning.State = metal3api.StateProvisioned
+	host.Status.Provisioning.Image = metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	// The spec moved to another registry, but the host was not provisioned
+	// again.
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://quay.io/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+
+	checker := &fakeRegistryChecker{}
+	r := newTestReconciler(t, host, ociSecret)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+	r.ImageAuthRegistryChecker = checker
+	r.ImageAuthRevalidationInterval = time.Minute
+
+	info := &reconcileInfo{host: host, log: testLogger(t)}
+	r.revalidateImageAuth(t.Context(), info)
+
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status, cond.Message)
+	assert.Contains(t, cond.Message, "key registry.example.com")
+	assert.Equal(t, "registry.example.com", host.Annotations[ImageAuthRegistryAnnotation])
+	select {
+	case event := <-recorder.Events:
+		assert.NotContains(t, event, EventAuthRegistryChanged)
+	default:
+	}
+}
+
+// TestImageAuthRevalidationForgetsDeletedHosts tests that the re-validation
+// bookkeeping of a host is dropped once the host is gone.
+func TestImageAuthRevalidationForgetsDeletedHosts(t *testing.T) {
+	host := newDefaultHost(t)
+	r := newTestReconciler(t)
+	key := newRequest(host).NamespacedName
+	r.imageAuthLastChecked.Store(key, time.Now())
+
+	_, err := r.Reconcile(t.Context(), newRequest(host))
+	require.NoError(t, err)
+
+	_, found := r.imageAuthLastChecked.Load(key)
+	assert.False(t, found)
+}
+
+func TestImageAuthSecretWatch(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+
+	referencing := newHost("referencing", &metal3api.BareMetalHostSpec{
+		Image: &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &ociAuthSecretName,
+		},
+	})
+	otherSecretName := "other-secret"
+	other := newHost("other", &metal3api.BareMetalHostSpec{
+		Image: &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &otherSecretName,
+		},
+	})
+	noAuth := newHost("no-auth", &metal3api.BareMetalHostSpec{
+		Image: &metal3api.Image{URL: "http://example.com/image.qcow2"},
+	})
+
+	retyped := ociSecret.DeepCopy()
+	retyped.Type = corev1.SecretTypeOpaque
+	relabeled := ociSecret.DeepCopy()
+	relabeled.Labels = map[string]string{"foo": "bar"}
+	redata := ociSecret.DeepCopy()
+	redata.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)}
+
+	assert.True(t, imageAuthSecretChanged(event.UpdateEvent{ObjectOld: ociSecret, ObjectNew: retyped}))
+	assert.True(t, imageAuthSecretChanged(event.UpdateEvent{ObjectOld: ociSecret, ObjectNew: redata}))
+	assert.False(t, imageAuthSecretChanged(event.UpdateEvent{ObjectOld: ociSecret, ObjectNew: relabeled}))
+
+	r := newTestReconciler(t, ociSecret, referencing, other, noAuth)
+	requests := r.findBMHsForAuthSecret(t.Context(), retyped)
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "referencing"}},
+	}, requests)
+}
+
+// TestImageAuthSecretWatch_OtherSecretReferences tests that changes to the
+// other secrets a host references, such as its BMC credentials or user data,
+// do not requeue it through the image auth secret watch.
+func TestImageAuthSecretWatch_OtherSecretReferences(t *testing.T) {
+	ociAuthSecretName := "oci-auth-secret"
+	host := newHost("multi-secret", &metal3api.BareMetalHostSpec{
+		BMC: metal3api.BMCDetails{
+			Address:         "ipmi://192.168.122.1:6233",
+			CredentialsName: "bmc-secret",
+		},
+		Image: &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &ociAuthSecretName,
+		},
+		UserData:                       &corev1.SecretReference{Name: "user-data", Namespace: namespace},
+		MetaData:                       &corev1.SecretReference{Name: "meta-data", Namespace: namespace},
+		NetworkData:                    &corev1.SecretReference{Name: "network-data", Namespace: namespace},
+		PreprovisioningNetworkDataName: "preprov-network-data",
+	})
+
+	r := newTestReconciler(t, host)
+	for _, name := range []string{"bmc-secret", "user-data", "meta-data", "network-data", "preprov-network-data"} {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		assert.Empty(t, r.findBMHsForAuthSecret(t.Context(), secret), "secret %s must not requeue the host", name)
+	}
+
+	ociSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ociAuthSecretName, Namespace: namespace}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "multi-secret"}},
+	}, r.findBMHsForAuthSecret(t.Context(), ociSecret))
+}
+
+// TestImageAuthSecretWatch_CrossNamespace tests that a change to an auth
+// secret requeues the hosts in other namespaces referencing it.
+func TestImageAuthSecretWatch_CrossNamespace(t *testing.T) {
+	crossNamespaceSecret := namespace + "/shared-pull-secret"
+	elsewhere := newHost("elsewhere", &metal3api.BareMetalHostSpec{
+		Image: &metal3api.Image{
+			URL:               "oci://registry.example.com/repo/image:tag",
+			OCIAuthSecretName: &crossNamespaceSecret,
+		},
+	})
+	elsewhere.Namespace = "other-namespace"
+
+	r := newTestReconciler(t, elsewhere)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared-pull-secret", Namespace: namespace}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "other-namespace", Name: "elsewhere"}},
+	}, r.findBMHsForAuthSecret(t.Context(), secret))
+}
+
+// TestImageAuthCASecretWatch tests that updating a registry CA secret
+// requeues the hosts referencing it.
+func TestImageAuthCASecretWatch(t *testing.T) {
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-ca", Namespace: namespace},
+		Data:       map[string][]byte{secretutils.RegistryCABundleKey: []byte("old bundle")},
+	}
+	ociAuthSecretName := "oci-auth-secret"
+	image := &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	referencing := newHost("referencing", &metal3api.BareMetalHostSpec{Image: image.DeepCopy()})
+	referencing.Annotations = map[string]string{ImageAuthCASecretAnnotation: caSecret.Name}
+	other := newHost("other", &metal3api.BareMetalHostSpec{Image: image.DeepCopy()})
+
+	rotated := caSecret.DeepCopy()
+	rotated.Data = map[string][]byte{secretutils.RegistryCABundleKey: []byte("new bundle")}
+	assert.True(t, imageAuthSecretChanged(event.UpdateEvent{ObjectOld: caSecret, ObjectNew: rotated}))
+
+	r := newTestReconciler(t, caSecret, referencing, other)
+	referencingName := types.NamespacedName{Namespace: namespace, Name: "referencing"}
+
+	requests := r.findBMHsForAuthSecret(t.Context(), rotated)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: referencingName}}, requests)
+}
+
+// TestGetImageAuthSecret_CredentialHelperDeferred tests that a secret only
+// naming a credential helper for the registry is waited for instead of
+// failing the host.
+func TestGetImageAuthSecret_CredentialHelperDeferred(t *testing.T) {
+	host := newDefaultHost(t)
+	ociAuthSecretName := "helper-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	ociSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ociAuthSecretName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {}, "credHelpers": {"registry.example.com": "ecr-login"}}`),
+		},
+	}
+
+	r := newTestReconciler(t, host, ociSecret)
+	r.ImageAuthDeferCredentialHelpers = true
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.Error(t, err)
+	reason, _ := imageAuthReason(err)
+	assert.Equal(t, ReasonDeferred, reason)
+	assert.Contains(t, err.Error(), "ecr-login")
+
+	actResult := imageAuthFailureResult(makeReconcileInfo(host), err)
+	require.IsType(t, actionContinue{}, actResult)
+	result, resultErr := actResult.Result()
+	require.NoError(t, resultErr)
+	assert.Equal(t, imageAuthDeferredDelay, result.RequeueAfter)
+	assert.Empty(t, host.Status.ErrorMessage, "deferred credentials must not put the host in error")
+}
+
+// TestGetImageAuthSecret_InjectionEvent tests that, when enabled, injecting
+// credentials emits an event naming the secret resourceVersion, once per
+// version of the secret.
+func TestGetImageAuthSecret_InjectionEvent(t *testing.T) {
+	host := newDefaultHost(t)
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+
+	r := newTestReconciler(t, host, ociSecret)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+	r.ImageAuthInjectionEvents = true
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+
+	stored := &corev1.Secret{}
+	require.NoError(t, r.Get(t.Context(), client.ObjectKeyFromObject(ociSecret), stored))
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, EventAuthCredentialsInjected)
+		assert.Contains(t, event, "resourceVersion "+stored.ResourceVersion)
+	default:
+		t.Fatal("expected a credentials injected event")
+	}
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond)
+	assert.Contains(t, cond.Message, "resourceVersion "+stored.ResourceVersion)
+
+	// Nothing changed: no new event.
+	_, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+
+	// A rotation yields a new event with the new resourceVersion.
+	stored.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths": {"registry.example.com": {"auth": "bmV3dXNlcjpuZXdwYXNz"}}}`)
+	require.NoError(t, r.Update(t.Context(), stored))
+	_, err = r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, "resourceVersion "+stored.ResourceVersion)
+	default:
+		t.Fatal("expected a credentials injected event after rotation")
+	}
+}
+
+func TestGetImageAuthSecret_SecretCreatedAfterValidation(t *testing.T) {
+	host := newDefaultHost(t)
+	ociAuthSecretName := "oci-auth-secret"
+	host.Spec.Image = &metal3api.Image{
+		URL:               "oci://registry.example.com/repo/image:tag",
+		OCIAuthSecretName: &ociAuthSecretName,
+	}
+	validatedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	host.Annotations = map[string]string{ImageAuthValidatedAtAnnotation: validatedAt.Format(time.RFC3339)}
+	// The secret went missing after the last validation.
+	conditions.Set(host, metav1.Condition{
+		Type:   metal3api.ImageAuthInUseCondition,
+		Status: metav1.ConditionFalse,
+		Reason: string(ReasonSecretNotFound),
+	})
+	ociSecret := createDockerConfigJSONSecretForTest(t, ociAuthSecretName, namespace, map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	ociSecret.CreationTimestamp = metav1.NewTime(validatedAt.Add(30 * time.Minute))
+
+	r := newTestReconciler(t, host, ociSecret)
+	r.ImageAuthRecordValidatedAt = true
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Contains(t, cond.Message, "after the last successful validation at "+validatedAt.Format(time.RFC3339))
+
+	recorded, err := time.Parse(time.RFC3339, host.Annotations[ImageAuthValidatedAtAnnotation])
+	require.NoError(t, err)
+	assert.True(t, recorded.After(validatedAt), "expected the validation time to be updated")
+}
+
+// TestGetImageAuthSecret_HealthyCondition tests that the ImageAuthHealthy
+// condition is only True when the credentials are in use without warnings.
+func TestGetImageAuthSecret_HealthyCondition(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         string
+		expectErr      bool
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "valid without warnings",
+			config:         `{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: metal3api.ImageAuthHealthyReason,
+		},
+		{
+			name:           "valid with a malformed entry warning",
+			config:         `{"auths": {"registry.example.com": "dGVzdHVzZXI6dGVzdHBhc3M="}}`,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: metal3api.ImageAuthWarningsReason,
+		},
+		{
+			name:           "invalid",
+			config:         `{"auths": {"other.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			expectErr:      true,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: string(ReasonCredentialsInvalid),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := newDefaultHost(t)
+			ociAuthSecretName := "oci-auth-secret"
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &ociAuthSecretName,
+			}
+			ociSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ociAuthSecretName,
+					Namespace: namespace,
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(tc.config)},
+			}
+
+			r := newTestReconciler(t, host, ociSecret)
+
+			_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, conditions.IsTrue(host, metal3api.ImageAuthInUseCondition))
+			}
+
+			cond := conditions.Get(host, metal3api.ImageAuthHealthyCondition)
+			require.NotNil(t, cond, "ImageAuthHealthy condition should be set")
+			assert.Equal(t, tc.expectedStatus, cond.Status)
+			assert.Equal(t, tc.expectedReason, cond.Reason)
+		})
+	}
+}
+
+// TestGetImageAuthSecret_HealthyConditionRemoved tests that the
+// ImageAuthHealthy condition is removed once no auth secret is used.
+func TestGetImageAuthSecret_HealthyConditionRemoved(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Image = &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"}
+	conditions.Set(host, metav1.Condition{
+		Type:   metal3api.ImageAuthHealthyCondition,
+		Status: metav1.ConditionTrue,
+		Reason: metal3api.ImageAuthHealthyReason,
+	})
+
+	r := newTestReconciler(t, host)
+
+	_, err := r.getImageAuthSecret(t.Context(), host, host.Spec.Image)
+	require.NoError(t, err)
+	assert.Nil(t, conditions.Get(host, metal3api.ImageAuthHealthyCondition))
+}
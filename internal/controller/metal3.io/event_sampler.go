@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// defaultImageAuthEventsPerHostPerMinute caps the image auth events emitted
+// for a single host so that reconcile storms do not overload the event API.
+const defaultImageAuthEventsPerHostPerMinute = 5
+
+// samplingEventRecorder wraps an EventRecorder and drops events once an
+// object has emitted the maximum number of events within the current window.
+// It is safe for concurrent use.
+type samplingEventRecorder struct {
+	recorder  record.EventRecorder
+	maxEvents int
+	window    time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*eventWindow
+}
+
+type eventWindow struct {
+	start time.Time
+	count int
+}
+
+// NewSamplingEventRecorder returns an EventRecorder that emits at most
+// maxEventsPerMinute events per object per minute through recorder. A nil
+// recorder is returned unchanged, and a non-positive limit disables sampling.
+func NewSamplingEventRecorder(recorder record.EventRecorder, maxEventsPerMinute int) record.EventRecorder {
+	if recorder == nil || maxEventsPerMinute <= 0 {
+		return recorder
+	}
+	return &samplingEventRecorder{
+		recorder:  recorder,
+		maxEvents: maxEventsPerMinute,
+		window:    time.Minute,
+		now:       time.Now,
+		windows:   map[string]*eventWindow{},
+	}
+}
+
+// allow reports whether another event may be emitted for the object.
+func (s *samplingEventRecorder) allow(object runtime.Object) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return true
+	}
+	key := accessor.GetNamespace() + "/" + accessor.GetName()
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.windows[key]
+	if !ok || now.Sub(w.start) >= s.window {
+		// Drop expired windows so the map does not grow without bound
+		for k, old := range s.windows {
+			if now.Sub(old.start) >= s.window {
+				delete(s.windows, k)
+			}
+		}
+		w = &eventWindow{start: now}
+		s.windows[key] = w
+	}
+	if w.count >= s.maxEvents {
+		return false
+	}
+	w.count++
+	return true
+}
+
+func (s *samplingEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if s.allow(object) {
+		s.recorder.Event(object, eventtype, reason, message)
+	}
+}
+
+func (s *samplingEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if s.allow(object) {
+		s.recorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	}
+}
+
+func (s *samplingEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if s.allow(object) {
+		s.recorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	}
+}
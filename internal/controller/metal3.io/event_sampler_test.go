@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSamplingEventRecorder_CapsValidatorFailures(t *testing.T) {
+	const limit = 3
+
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeOpaque,
+		map[string][]byte{"token": []byte("abc")},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	fakeRecorder := record.NewFakeRecorder(100)
+	validator := NewImageAuthValidator(NewSamplingEventRecorder(fakeRecorder, limit))
+	secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := validator.Validate(t.Context(), bmh, secretManager); err == nil {
+				t.Error("expected validation to fail")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if emitted := len(fakeRecorder.Events); emitted != limit {
+		t.Errorf("expected %d events to be emitted, got %d", limit, emitted)
+	}
+}
+
+func TestSamplingEventRecorder_WindowAndHosts(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(100)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler, ok := NewSamplingEventRecorder(fakeRecorder, 2).(*samplingEventRecorder)
+	if !ok {
+		t.Fatal("expected a sampling recorder")
+	}
+	sampler.now = func() time.Time { return now }
+
+	host1 := &metal3api.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "host-1", Namespace: "default"}}
+	host2 := &metal3api.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "host-2", Namespace: "default"}}
+
+	for range 5 {
+		sampler.Event(host1, corev1.EventTypeWarning, "Reason", "message")
+		sampler.Eventf(host2, corev1.EventTypeWarning, "Reason", "message %d", 1)
+	}
+	if emitted := len(fakeRecorder.Events); emitted != 4 {
+		t.Fatalf("expected 2 events per host, got %d in total", emitted)
+	}
+
+	now = now.Add(time.Minute)
+	sampler.Event(host1, corev1.EventTypeWarning, "Reason", "message")
+	if emitted := len(fakeRecorder.Events); emitted != 5 {
+		t.Errorf("expected the limit to reset after the window, got %d events", emitted)
+	}
+}
+
+func TestNewSamplingEventRecorder_Passthrough(t *testing.T) {
+	if NewSamplingEventRecorder(nil, 5) != nil {
+		t.Error("expected a nil recorder to stay nil")
+	}
+	fakeRecorder := record.NewFakeRecorder(1)
+	if NewSamplingEventRecorder(fakeRecorder, 0) != record.EventRecorder(fakeRecorder) {
+		t.Error("expected sampling to be disabled for a zero limit")
+	}
+}
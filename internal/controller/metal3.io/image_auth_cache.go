@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// imageAuthInputsHash returns a hash of the inputs of image auth validation
+// besides the image URL and the auth secret: the host annotations listing
+// registry mirrors, pinning the secret UID and naming a registry CA secret,
+// and the resourceVersion of that CA secret.
+func imageAuthInputsHash(ctx context.Context, bmh *metal3api.BareMetalHost, secretMgr secretutils.SecretManager) string {
+	annotations := bmh.GetAnnotations()
+	hash := sha256.New()
+	for _, key := range []string{ImageAuthMirrorsAnnotation, ImageAuthSecretUIDAnnotation, ImageAuthCASecretAnnotation} {
+		fmt.Fprintf(hash, "%s=%q\n", key, annotations[key])
+	}
+	if caSecretName := annotations[ImageAuthCASecretAnnotation]; caSecretName != "" {
+		// A CA secret that cannot be read has no version, which never
+		// matches one recorded after a successful validation.
+		caSecretVersion := ""
+		if sec, err := secretMgr.ReadSecret(ctx, types.NamespacedName{Namespace: bmh.Namespace, Name: caSecretName}); err == nil {
+			caSecretVersion = sec.ResourceVersion
+		}
+		fmt.Fprintf(hash, "ca-secret-version=%q\n", caSecretVersion)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordedValidResult returns a valid result for the host, without running
+// the checks again, when the host still reports its image auth credentials
+// in use and its status records a valid result for the same image URL, auth
+// secret resourceVersion, other inputs and credentials. The credentials are
+// read from the secret again, since they are never stored in the status.
+// It returns nil when the result must be validated again.
+func (v *ImageAuthValidator) recordedValidResult(bmh *metal3api.BareMetalHost, sec *corev1.Secret, imageURL string, result *ImageAuthResult) *ImageAuthResult {
+	recorded := bmh.Status.ImageAuth
+	if recorded == nil || !conditions.IsTrue(bmh, metal3api.ImageAuthInUseCondition) {
+		return nil
+	}
+	if recorded.ImageURL != imageURL || recorded.SecretResourceVersion != sec.ResourceVersion || recorded.InputsHash != result.inputsHash {
+		return nil
+	}
+	creds, err := secretutils.ResolveRegistryCredentials(sec, imageURL, v.ExtractOptions)
+	if err != nil || credentialFingerprint(creds.Credentials) != recorded.CredentialFingerprint {
+		// Credentials from a mirror, or a fingerprint salted by a
+		// previous operator process, are validated again.
+		return nil
+	}
+
+	result.Reason = ReasonValid
+	result.RegistryHost = creds.RegistryHost
+	result.MatchedAuthKey = creds.MatchedAuthKey
+	result.CanonicalAuthKey = creds.CanonicalAuthKey
+	result.MatchedAuthConfig = creds.MatchedAuthConfig
+	result.Metadata = credentialMetadata(result, creds)
+	result.Credentials = creds.Credentials
+	result.CredentialFingerprint = recorded.CredentialFingerprint
+	result.NewerSecretNote = newerSecretNote(sec, bmh)
+	// Age is the only input that changes without the secret.
+	if v.MaxCredentialAge > 0 {
+		result.StaleCredentials = staleCredentialProblem(sec, v.MaxCredentialAge, time.Now()) != ""
+	}
+	return result
+}
+
+// recordImageAuthResult records the valid result of the host image in the
+// host status, so that it is reused while its inputs are unchanged. Results
+// with warnings are not recorded, so that they are reported again.
+func recordImageAuthResult(host *metal3api.BareMetalHost, image *metal3api.Image, result *ImageAuthResult) {
+	if result.Reason != ReasonValid || len(result.Warnings) > 0 || result.Credentials == "" {
+		host.Status.ImageAuth = nil
+		return
+	}
+	imageURL := image.URL
+	if canonical, err := secretutils.CanonicalizeImageURL(image.URL); err == nil {
+		imageURL = canonical
+	}
+	host.Status.ImageAuth = &metal3api.ImageAuthStatus{
+		ImageURL:              imageURL,
+		SecretResourceVersion: result.SecretResourceVersion,
+		InputsHash:            result.inputsHash,
+		CredentialFingerprint: result.CredentialFingerprint,
+	}
+}
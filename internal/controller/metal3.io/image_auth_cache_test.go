@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestValidate_ReuseValidResult(t *testing.T) {
+	c, bmh, secret := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	server := httptest.NewTLSServer(nil)
+	server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-ca", Namespace: bmh.Namespace},
+		Data:       map[string][]byte{secretutils.RegistryCABundleKey: caBundle},
+	}
+	if err := c.Create(t.Context(), caSecret); err != nil {
+		t.Fatalf("failed to create CA secret: %v", err)
+	}
+	checker := &fakeRegistryChecker{}
+	validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+	validator.RegistryChecker = checker
+	validator.ReuseValidResult = true
+	secretMgr := secretutils.NewSecretManager(testLogger(t), c, c)
+
+	// evaluate validates the host image, records the result as the
+	// controller does, and checks the number of full validations.
+	evaluate := func(expectedCalls int) *ImageAuthResult {
+		t.Helper()
+		result, err := validator.Evaluate(t.Context(), bmh, secretMgr)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Reason != ReasonValid {
+			t.Fatalf("expected reason %q, got %q", ReasonValid, result.Reason)
+		}
+		if checker.calls != expectedCalls {
+			t.Fatalf("expected %d full validations, got %d", expectedCalls, checker.calls)
+		}
+		recordImageAuthResult(bmh, bmh.Spec.Image, result)
+		return result
+	}
+
+	first := evaluate(1)
+	if bmh.Status.ImageAuth == nil || bmh.Status.ImageAuth.SecretResourceVersion != first.SecretResourceVersion {
+		t.Fatalf("expected the secret resourceVersion to be recorded, got %+v", bmh.Status.ImageAuth)
+	}
+
+	// The host does not report the credentials in use yet.
+	evaluate(2)
+
+	conditions.Set(bmh, metav1.Condition{
+		Type:   metal3api.ImageAuthInUseCondition,
+		Status: metav1.ConditionTrue,
+		Reason: metal3api.ImageAuthCredentialsInjectedReason,
+	})
+	reused := evaluate(2)
+	if reused.Credentials != first.Credentials || reused.CredentialFingerprint != first.CredentialFingerprint {
+		t.Error("expected the reused result to carry the same credentials")
+	}
+	if reused.RegistryHost != "registry.example.com" || reused.MatchedAuthKey != "registry.example.com" {
+		t.Errorf("unexpected reused result %+v", reused)
+	}
+
+	// A new secret resourceVersion validates again.
+	if err := c.Get(t.Context(), types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	secret.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths": {"registry.example.com": {"auth": "bmV3dXNlcjpuZXdwYXNz"}}}`)
+	if err := c.Update(t.Context(), secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	evaluate(3)
+	evaluate(3)
+
+	// So does a new image URL.
+	bmh.Spec.Image.URL = "oci://registry.example.com/repo/image:other"
+	evaluate(4)
+	evaluate(4)
+
+	// And so do the annotations changing the outcome.
+	bmh.Annotations = map[string]string{ImageAuthSecretUIDAnnotation: "pinned-uid"}
+	evaluate(5)
+	evaluate(5)
+	bmh.Annotations[ImageAuthMirrorsAnnotation] = "mirror.example.com"
+	evaluate(6)
+	evaluate(6)
+	bmh.Annotations[ImageAuthCASecretAnnotation] = caSecret.Name
+	evaluate(7)
+	evaluate(7)
+
+	// As well as a new version of the CA secret.
+	if err := c.Get(t.Context(), types.NamespacedName{Namespace: caSecret.Namespace, Name: caSecret.Name}, caSecret); err != nil {
+		t.Fatalf("failed to get CA secret: %v", err)
+	}
+	caSecret.Data[secretutils.RegistryCABundleKey] = bytes.Repeat(caBundle, 2)
+	if err := c.Update(t.Context(), caSecret); err != nil {
+		t.Fatalf("failed to update CA secret: %v", err)
+	}
+	evaluate(8)
+	evaluate(8)
+
+	// Re-validation always reaches the registry.
+	validator.revalidate = true
+	evaluate(9)
+	validator.revalidate = false
+
+	// A result with warnings is not recorded.
+	result := evaluate(9)
+	result.Warnings = []string{"warning"}
+	recordImageAuthResult(bmh, bmh.Spec.Image, result)
+	if bmh.Status.ImageAuth != nil {
+		t.Error("expected a result with warnings not to be recorded")
+	}
+	evaluate(10)
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+)
+
+// defaultRegistryDNSTimeout bounds the registry host lookup when
+// RegistryDNSCheck.Timeout is not set.
+const defaultRegistryDNSTimeout = 5 * time.Second
+
+// HostResolver resolves host names. *net.Resolver implements it.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// RegistryDNSCheck confirms that the registry host name resolves, which
+// catches typos in the image URL without contacting the registry or needing
+// credentials.
+type RegistryDNSCheck struct {
+	// Resolver performs the lookups. Defaults to net.DefaultResolver.
+	Resolver HostResolver
+	// Timeout bounds each lookup. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// check resolves the host of the registry serving the image. It returns a
+// ReasonRegistryUnresolvable error when the name does not resolve and a
+// ReasonRegistryUnreachable error when the lookup itself failed, for example
+// on a timeout, as that is usually transient.
+func (c *RegistryDNSCheck) check(ctx context.Context, imageURL string) error {
+	registryHost, err := secretutils.RegistryHostForImage(imageURL)
+	if err != nil {
+		return &ImageAuthError{Reason: ReasonRegistryUnresolvable, Message: "cannot determine image registry", Err: err}
+	}
+	if secretutils.IsDockerHubHost(registryHost) {
+		registryHost = dockerHubRegistryEndpoint
+	}
+	name := registryHost
+	if host, _, splitErr := net.SplitHostPort(registryHost); splitErr == nil {
+		name = host
+	}
+	if net.ParseIP(name) != nil {
+		return nil
+	}
+
+	resolver := c.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultRegistryDNSTimeout
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err = resolver.LookupHost(lookupCtx, name)
+	if err == nil {
+		return nil
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTimeout || dnsErr.IsTemporary) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return &ImageAuthError{
+			Reason:  ReasonRegistryUnreachable,
+			Message: fmt.Sprintf("lookup of registry host %s failed", name),
+			Err:     &RegistryUnreachableError{Host: registryHost, Err: err},
+		}
+	}
+	return &ImageAuthError{
+		Reason:  ReasonRegistryUnresolvable,
+		Message: fmt.Sprintf("registry host %s does not resolve; check the image URL for typos", name),
+		Err:     err,
+	}
+}
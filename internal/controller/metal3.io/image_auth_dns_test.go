@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// fakeResolver resolves the names in addrs and fails the others with err.
+type fakeResolver struct {
+	addrs   map[string][]string
+	err     error
+	lookups []string
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.lookups = append(r.lookups, host)
+	if addrs, ok := r.addrs[host]; ok {
+		return addrs, nil
+	}
+	return nil, r.err
+}
+
+func nxdomain(host string) error {
+	return &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestRegistryDNSCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		imageURL       string
+		err            error
+		expectedReason ImageAuthReason
+		expectedLookup string
+	}{
+		{
+			name:           "resolves",
+			imageURL:       "oci://registry.example.com:5000/repo/image:tag",
+			expectedLookup: "registry.example.com",
+		},
+		{
+			name:           "NXDOMAIN",
+			imageURL:       "oci://regsitry.example.com/repo/image:tag",
+			err:            nxdomain("regsitry.example.com"),
+			expectedReason: ReasonRegistryUnresolvable,
+			expectedLookup: "regsitry.example.com",
+		},
+		{
+			name:           "lookup timeout",
+			imageURL:       "oci://slow.example.com/repo/image:tag",
+			err:            &net.DNSError{Err: "i/o timeout", Name: "slow.example.com", IsTimeout: true},
+			expectedReason: ReasonRegistryUnreachable,
+			expectedLookup: "slow.example.com",
+		},
+		{
+			name:           "docker hub alias",
+			imageURL:       "oci://docker.io/library/busybox:latest",
+			expectedLookup: dockerHubRegistryEndpoint,
+		},
+		{
+			name:     "IP address",
+			imageURL: "oci://192.0.2.10:5000/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeResolver{
+				addrs: map[string][]string{
+					"registry.example.com":    {"192.0.2.1"},
+					dockerHubRegistryEndpoint: {"192.0.2.2"},
+				},
+				err: tt.err,
+			}
+			check := &RegistryDNSCheck{Resolver: resolver, Timeout: time.Second}
+
+			err := check.check(t.Context(), tt.imageURL)
+			reason, _ := imageAuthReason(err)
+			if reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q (%v)", tt.expectedReason, reason, err)
+			}
+			if tt.expectedLookup == "" {
+				if len(resolver.lookups) != 0 {
+					t.Errorf("expected no lookup, got %v", resolver.lookups)
+				}
+			} else if len(resolver.lookups) != 1 || resolver.lookups[0] != tt.expectedLookup {
+				t.Errorf("expected a lookup of %q, got %v", tt.expectedLookup, resolver.lookups)
+			}
+		})
+	}
+}
+
+func TestValidate_RegistryUnresolvable(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"regsitry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+		"oci://regsitry.example.com/repo/image:tag",
+	)
+	recorder := record.NewFakeRecorder(10)
+
+	validator := NewImageAuthValidator(recorder)
+	validator.DNSCheck = &RegistryDNSCheck{Resolver: &fakeResolver{err: nxdomain("regsitry.example.com")}}
+	result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err == nil {
+		t.Fatal("expected an error for an unresolvable registry")
+	}
+	if result.Reason != ReasonRegistryUnresolvable {
+		t.Errorf("expected reason %q, got %q", ReasonRegistryUnresolvable, result.Reason)
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected the NXDOMAIN error to be wrapped, got %v", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventAuthRegistryUnresolvable) {
+			t.Errorf("expected a %s event, got %q", EventAuthRegistryUnresolvable, event)
+		}
+	default:
+		t.Error("expected an event for the unresolvable registry")
+	}
+}
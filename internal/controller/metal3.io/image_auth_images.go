@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+)
+
+// ImageAuthOutcome is the outcome of validating the auth secret of one of the
+// images of a host.
+type ImageAuthOutcome struct {
+	Image  *metal3api.Image
+	Result *ImageAuthResult
+	Err    error
+}
+
+// imagesToValidate returns the images of the host whose auth secrets are
+// validated, starting with primary, the image being provisioned. Only the
+// primary image exists today; this is the place to append further images
+// should the API gain them, such as an Image.AdditionalImages field.
+func imagesToValidate(_ *metal3api.BareMetalHost, primary *metal3api.Image) []*metal3api.Image {
+	return []*metal3api.Image{primary}
+}
+
+// EvaluateImages validates the auth secret of each of the images, in order.
+// A failure for one image does not stop the validation of the others.
+func (v *ImageAuthValidator) EvaluateImages(ctx context.Context, bmh *metal3api.BareMetalHost, images []*metal3api.Image, secretMgr secretutils.SecretManager) []ImageAuthOutcome {
+	outcomes := make([]ImageAuthOutcome, 0, len(images))
+	for _, img := range images {
+		result, err := v.EvaluateImage(ctx, bmh, img, secretMgr)
+		outcomes = append(outcomes, ImageAuthOutcome{Image: img, Result: result, Err: err})
+	}
+	return outcomes
+}
+
+// aggregateImageAuthOutcomes combines the outcomes for the images of a host
+// into the single result the host conditions are derived from. The first
+// failure, in image order, is reported as is. Otherwise the result of the
+// primary image, the first one, is returned with the warnings of every
+// image, since only its credentials are passed to the provisioner.
+func aggregateImageAuthOutcomes(outcomes []ImageAuthOutcome) (*ImageAuthResult, error) {
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			return outcome.Result, outcome.Err
+		}
+	}
+
+	aggregated := *outcomes[0].Result
+	aggregated.Warnings = slices.Clone(aggregated.Warnings)
+	for _, outcome := range outcomes[1:] {
+		aggregated.Warnings = append(aggregated.Warnings, outcome.Result.Warnings...)
+	}
+	return &aggregated, nil
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEvaluateImages_SingleImage(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": "dGVzdHVzZXI6dGVzdHBhc3M="}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	secretMgr := secretutils.NewSecretManager(testLogger(t), c, c)
+	validator := NewImageAuthValidator(nil)
+
+	images := imagesToValidate(bmh, bmh.Spec.Image)
+	if len(images) != 1 || images[0] != bmh.Spec.Image {
+		t.Fatalf("expected only the primary image, got %v", images)
+	}
+
+	outcomes := validator.EvaluateImages(t.Context(), bmh, images, secretMgr)
+	if len(outcomes) != 1 || outcomes[0].Image != bmh.Spec.Image {
+		t.Fatalf("expected one outcome for the primary image, got %+v", outcomes)
+	}
+	aggregated, err := aggregateImageAuthOutcomes(outcomes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	direct, err := validator.Evaluate(t.Context(), bmh, secretMgr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if aggregated.Reason != direct.Reason || aggregated.Credentials != direct.Credentials ||
+		!slices.Equal(aggregated.Warnings, direct.Warnings) {
+		t.Errorf("expected the aggregated result to match the single image result %+v, got %+v", direct, aggregated)
+	}
+	if len(aggregated.Warnings) != 1 {
+		t.Errorf("expected the malformed entry warning, got %v", aggregated.Warnings)
+	}
+}
+
+func TestAggregateImageAuthOutcomes(t *testing.T) {
+	primary := &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"}
+	additional := &metal3api.Image{URL: "oci://other.example.com/repo/image:tag"}
+	valid := func(credentials string, warnings ...string) *ImageAuthResult {
+		return &ImageAuthResult{Reason: ReasonValid, Credentials: credentials, Warnings: warnings}
+	}
+	failure := errors.New("auth secret not found")
+
+	tests := []struct {
+		name              string
+		outcomes          []ImageAuthOutcome
+		expectErr         error
+		expectReason      ImageAuthReason
+		expectCredentials string
+		expectWarnings    []string
+	}{
+		{
+			name:              "single valid image",
+			outcomes:          []ImageAuthOutcome{{Image: primary, Result: valid("primary", "weak")}},
+			expectReason:      ReasonValid,
+			expectCredentials: "primary",
+			expectWarnings:    []string{"weak"},
+		},
+		{
+			name: "single failed image",
+			outcomes: []ImageAuthOutcome{
+				{Image: primary, Result: &ImageAuthResult{Reason: ReasonSecretNotFound}, Err: failure},
+			},
+			expectErr:    failure,
+			expectReason: ReasonSecretNotFound,
+		},
+		{
+			name: "warnings of every image",
+			outcomes: []ImageAuthOutcome{
+				{Image: primary, Result: valid("primary", "weak")},
+				{Image: additional, Result: valid("additional", "stale")},
+			},
+			expectReason:      ReasonValid,
+			expectCredentials: "primary",
+			expectWarnings:    []string{"weak", "stale"},
+		},
+		{
+			name: "failure of an additional image",
+			outcomes: []ImageAuthOutcome{
+				{Image: primary, Result: valid("primary")},
+				{Image: additional, Result: &ImageAuthResult{Reason: ReasonSecretNotFound}, Err: failure},
+			},
+			expectErr:    failure,
+			expectReason: ReasonSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primaryWarnings := slices.Clone(tt.outcomes[0].Result.Warnings)
+
+			result, err := aggregateImageAuthOutcomes(tt.outcomes)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if result.Reason != tt.expectReason {
+				t.Errorf("expected reason %q, got %q", tt.expectReason, result.Reason)
+			}
+			if tt.expectErr != nil {
+				return
+			}
+			if result.Credentials != tt.expectCredentials {
+				t.Errorf("expected the credentials of the primary image, got %q", result.Credentials)
+			}
+			if !slices.Equal(result.Warnings, tt.expectWarnings) {
+				t.Errorf("expected warnings %v, got %v", tt.expectWarnings, result.Warnings)
+			}
+			if !slices.Equal(tt.outcomes[0].Result.Warnings, primaryWarnings) {
+				t.Errorf("expected the primary result to be left untouched, got %v", tt.outcomes[0].Result.Warnings)
+			}
+		})
+	}
+}
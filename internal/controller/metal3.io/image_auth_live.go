@@ -0,0 +1,494 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+)
+
+// dockerHubRegistryEndpoint is the host serving the registry API for Docker
+// Hub, whichever alias the credentials were stored under.
+const dockerHubRegistryEndpoint = "registry-1.docker.io"
+
+// RegistryChecker verifies registry credentials against the live registry.
+type RegistryChecker interface {
+	// CheckCredentials returns nil if the registry accepts the
+	// base64-encoded "username:password" credentials, a
+	// RegistryUnreachableError if the registry cannot be contacted and a
+	// RegistryAuthRejectedError if the credentials are refused.
+	CheckCredentials(ctx context.Context, registryHost, credentials string) error
+}
+
+// RateLimitReporter is optionally implemented by a RegistryChecker that can
+// report the pull rate limit advertised by the registry while checking the
+// credentials.
+type RateLimitReporter interface {
+	// CheckCredentialsWithRateLimit behaves like CheckCredentials and also
+	// returns an informational note describing the rate limit, or an empty
+	// string if the registry did not advertise one. Registries such as
+	// Docker Hub only advertise the limit on manifest requests, so the
+	// manifest of the reference in the repository is requested with HEAD,
+	// which does not count as a pull, once the credentials are accepted.
+	// An empty repository only reports a limit advertised on the ping of
+	// the registry API.
+	CheckCredentialsWithRateLimit(ctx context.Context, registryHost, repository, reference, credentials string) (string, error)
+}
+
+// CABundleChecker is optionally implemented by a RegistryChecker that can
+// trust an additional CA when connecting to the registry, for registries
+// whose CA is kept in a separate secret.
+type CABundleChecker interface {
+	// WithCABundle returns a checker that also trusts the certificates of
+	// the PEM-encoded CA bundle.
+	WithCABundle(caBundle []byte) (RegistryChecker, error)
+}
+
+// RegistryUnreachableError is returned when the registry could not be
+// contacted, for example because of a DNS or connection failure. It is
+// usually transient.
+type RegistryUnreachableError struct {
+	Host string
+	Err  error
+}
+
+func (e *RegistryUnreachableError) Error() string {
+	return fmt.Sprintf("registry %s is unreachable: %v", e.Host, e.Err)
+}
+
+func (e *RegistryUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// RegistryAuthRejectedError is returned when the registry refuses the
+// credentials.
+type RegistryAuthRejectedError struct {
+	Host       string
+	StatusCode int
+}
+
+func (e *RegistryAuthRejectedError) Error() string {
+	return fmt.Sprintf("registry %s rejected the credentials (HTTP %d)", e.Host, e.StatusCode)
+}
+
+// RegistryRetryPolicy bounds the retries of a live check that found the
+// registry unreachable, such as on a transient HTTP 503.
+type RegistryRetryPolicy struct {
+	// Retries is the number of retries after the first attempt. Zero
+	// disables retrying.
+	Retries int
+	// BaseDelay is the delay before the first retry. It doubles for every
+	// following retry.
+	BaseDelay time.Duration
+}
+
+// defaultRegistryCheckTimeout bounds each attempt of a live check, so that an
+// unresponsive registry cannot block a reconcile indefinitely whichever HTTP
+// client the checker uses.
+const defaultRegistryCheckTimeout = 10 * time.Second
+
+// defaultRegistryPingPath is the registry API version check endpoint.
+const defaultRegistryPingPath = "/v2/"
+
+// httpRegistryChecker checks credentials by pinging the registry /v2/
+// endpoint, sending them as Basic credentials on a Basic challenge and
+// following the token authentication flow on a Bearer challenge.
+type httpRegistryChecker struct {
+	client *http.Client
+	retry  RegistryRetryPolicy
+	// timeout bounds each attempt, including the token request.
+	timeout time.Duration
+	// pingPath overrides the endpoint to ping.
+	pingPath string
+	// preemptive sends the credentials with the first ping instead of
+	// waiting for a Basic challenge, and skips the manifest request.
+	preemptive bool
+}
+
+// NewHTTPRegistryChecker returns a RegistryChecker talking to registries over
+// HTTPS with the given client. A nil client uses http.DefaultClient. Each
+// check is bounded by a 10 second timeout.
+func NewHTTPRegistryChecker(client *http.Client) RegistryChecker {
+	return NewHTTPRegistryCheckerWithRetry(client, RegistryRetryPolicy{})
+}
+
+// NewHTTPRegistryCheckerWithRetry returns a RegistryChecker like
+// NewHTTPRegistryChecker that retries, with exponential backoff, checks that
+// found the registry unreachable. Retrying stops early when the next attempt
+// would start after the context deadline.
+func NewHTTPRegistryCheckerWithRetry(client *http.Client, retry RegistryRetryPolicy) RegistryChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpRegistryChecker{client: client, retry: retry, timeout: defaultRegistryCheckTimeout}
+}
+
+// NewHTTPRegistryPingChecker returns a RegistryChecker for a lightweight
+// pre-check that sends the credentials with a single GET of pingPath, "/v2/"
+// if empty. A 200 response means the credentials, or anonymous access, are
+// accepted. A 401 with a Bearer challenge is answered with the token
+// authentication flow, and any other 401 means the credentials are
+// rejected. The manifest is not requested, so only a rate limit advertised
+// on the ping is reported. A nil client uses http.DefaultClient.
+func NewHTTPRegistryPingChecker(client *http.Client, pingPath string) RegistryChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if pingPath == "" {
+		pingPath = defaultRegistryPingPath
+	} else if !strings.HasPrefix(pingPath, "/") {
+		pingPath = "/" + pingPath
+	}
+	return &httpRegistryChecker{client: client, pingPath: pingPath, preemptive: true, timeout: defaultRegistryCheckTimeout}
+}
+
+func (c *httpRegistryChecker) WithCABundle(caBundle []byte) (RegistryChecker, error) {
+	var transport *http.Transport
+	switch base := c.client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		return nil, fmt.Errorf("cannot add a CA bundle to HTTP transport %T", base)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	var pool *x509.CertPool
+	if tlsConfig.RootCAs != nil {
+		pool = tlsConfig.RootCAs.Clone()
+	} else if pool, _ = x509.SystemCertPool(); pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("CA bundle contains no PEM certificate")
+	}
+	tlsConfig.RootCAs = pool
+	transport.TLSClientConfig = tlsConfig
+
+	client := *c.client
+	client.Transport = transport
+	checker := *c
+	checker.client = &client
+	return &checker, nil
+}
+
+func (c *httpRegistryChecker) CheckCredentials(ctx context.Context, registryHost, credentials string) error {
+	_, err := c.CheckCredentialsWithRateLimit(ctx, registryHost, "", "", credentials)
+	return err
+}
+
+func (c *httpRegistryChecker) CheckCredentialsWithRateLimit(ctx context.Context, registryHost, repository, reference, credentials string) (string, error) {
+	manifest := registryManifest{repository: repository, reference: reference}
+	delay := c.retry.BaseDelay
+	for attempt := 0; ; attempt++ {
+		note, err := c.checkWithTimeout(ctx, registryHost, manifest, credentials)
+		var unreachable *RegistryUnreachableError
+		if attempt >= c.retry.Retries || !errors.As(err, &unreachable) {
+			return note, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return note, err
+		}
+		select {
+		case <-ctx.Done():
+			return note, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// registryManifest names the manifest whose rate limit headers are reported,
+// if the repository is not empty.
+type registryManifest struct {
+	repository string
+	reference  string
+}
+
+// checkWithTimeout runs check bounded by the checker timeout.
+func (c *httpRegistryChecker) checkWithTimeout(ctx context.Context, registryHost string, manifest registryManifest, credentials string) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.check(ctx, registryHost, manifest, credentials)
+}
+
+// check makes a single attempt at validating the credentials.
+func (c *httpRegistryChecker) check(ctx context.Context, registryHost string, manifest registryManifest, credentials string) (string, error) {
+	endpoint := registryHost
+	if secretutils.IsDockerHubHost(registryHost) {
+		endpoint = dockerHubRegistryEndpoint
+	}
+
+	pingPath := c.pingPath
+	if pingPath == "" {
+		pingPath = defaultRegistryPingPath
+	}
+
+	// Unless the credentials are sent preemptively, probe anonymously
+	// first and answer the challenge the registry, or the proxy in front of
+	// it, issues.
+	probe := "https://" + endpoint + pingPath
+	probeCredentials := ""
+	if c.preemptive {
+		probeCredentials = credentials
+	}
+	resp, err := c.do(ctx, http.MethodGet, probe, probeCredentials, "")
+	if err != nil {
+		return "", &RegistryUnreachableError{Host: registryHost, Err: err}
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	// The note is labelled by the request that carried the headers: on a
+	// Bearer challenge they come from the anonymous probe, as the token
+	// request goes to another host.
+	note := rateLimitNote(resp.Header, probeCredentials != "")
+	resp.Body.Close()
+
+	if !c.preemptive && resp.StatusCode == http.StatusUnauthorized && isBasicChallenge(challenge) {
+		probeCredentials = credentials
+		resp, err = c.do(ctx, http.MethodGet, probe, probeCredentials, "")
+		if err != nil {
+			return "", &RegistryUnreachableError{Host: registryHost, Err: err}
+		}
+		challenge = ""
+		note = rateLimitNote(resp.Header, probeCredentials != "")
+		resp.Body.Close()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return c.manifestRateLimitNote(ctx, endpoint, manifest, probeCredentials, "", note), nil
+	case resp.StatusCode == http.StatusUnauthorized && isBearerChallenge(challenge):
+		token, err := c.fetchToken(ctx, registryHost, challenge, manifest.repository, credentials)
+		if err != nil {
+			return note, err
+		}
+		return c.manifestRateLimitNote(ctx, endpoint, manifest, credentials, token, note), nil
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return note, &RegistryAuthRejectedError{Host: registryHost, StatusCode: resp.StatusCode}
+	default:
+		return note, &RegistryUnreachableError{
+			Host: registryHost,
+			Err:  fmt.Errorf("unexpected HTTP status %d", resp.StatusCode),
+		}
+	}
+}
+
+// manifestAcceptTypes are the manifest media types accepted by the HEAD
+// request of the manifest, so that registries do not refuse it for lack of
+// a supported type.
+var manifestAcceptTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// manifestRateLimitNote requests the manifest with HEAD, with the
+// credentials, or the token if not empty, the registry accepted, and returns
+// the note for its rate limit headers. The ping note is returned if the
+// manifest is not named, the checker only pings the registry or the request
+// fails, since the note is purely informational.
+func (c *httpRegistryChecker) manifestRateLimitNote(ctx context.Context, endpoint string, manifest registryManifest, credentials, token, pingNote string) string {
+	if manifest.repository == "" || c.preemptive {
+		return pingNote
+	}
+	repository := registryRepository(endpoint, manifest.repository)
+	reference := manifest.reference
+	if reference == "" {
+		reference = "latest"
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, "https://"+endpoint+"/v2/"+repository+"/manifests/"+reference, credentials, token)
+	if err != nil {
+		return pingNote
+	}
+	resp.Body.Close()
+	if note := rateLimitNote(resp.Header, credentials != ""); note != "" {
+		return note
+	}
+	return pingNote
+}
+
+// fetchToken requests a token from the realm advertised in a Bearer
+// challenge, authenticating with the credentials, and returns it. The token
+// is scoped to pulling from the repository, if not empty.
+func (c *httpRegistryChecker) fetchToken(ctx context.Context, registryHost, challenge, repository, credentials string) (string, error) {
+	params := parseChallengeParams(challenge)
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", &RegistryUnreachableError{Host: registryHost, Err: fmt.Errorf("invalid token realm %q", params["realm"])}
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if repository != "" {
+		query.Set("scope", "repository:"+registryRepository(registryHost, repository)+":pull")
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := c.do(ctx, http.MethodGet, realm.String(), credentials, "")
+	if err != nil {
+		return "", &RegistryUnreachableError{Host: registryHost, Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"` //nolint:tagliatelle
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil { //nolint:mnd
+			return "", &RegistryUnreachableError{Host: registryHost, Err: fmt.Errorf("invalid token response: %w", err)}
+		}
+		if token.Token != "" {
+			return token.Token, nil
+		}
+		if token.AccessToken != "" {
+			return token.AccessToken, nil
+		}
+		return "", &RegistryUnreachableError{Host: registryHost, Err: errors.New("token response contains no token")}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", &RegistryAuthRejectedError{Host: registryHost, StatusCode: resp.StatusCode}
+	default:
+		return "", &RegistryUnreachableError{
+			Host: registryHost,
+			Err:  fmt.Errorf("unexpected HTTP status %d from token endpoint", resp.StatusCode),
+		}
+	}
+}
+
+// do sends a request for the target, with the token as Bearer credentials if
+// not empty and the base64-encoded "username:password" credentials as Basic
+// credentials otherwise.
+func (c *httpRegistryChecker) do(ctx context.Context, method, target, credentials, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if decoded, decodeErr := base64.StdEncoding.DecodeString(credentials); decodeErr == nil {
+		if username, password, ok := strings.Cut(string(decoded), ":"); ok {
+			req.SetBasicAuth(username, password)
+		}
+	}
+	if method == http.MethodHead {
+		req.Header.Set("Accept", strings.Join(manifestAcceptTypes, ", "))
+	}
+	return c.client.Do(req)
+}
+
+// registryRepository returns the repository as named in the registry API,
+// where official Docker Hub images live under "library/".
+func registryRepository(registryHost, repository string) string {
+	if secretutils.IsDockerHubHost(registryHost) && !strings.Contains(repository, "/") {
+		return "library/" + repository
+	}
+	return repository
+}
+
+func isBearerChallenge(challenge string) bool {
+	scheme, _, _ := strings.Cut(challenge, " ")
+	return strings.EqualFold(scheme, "Bearer")
+}
+
+// isBasicChallenge reports whether the registry, or a proxy in front of it,
+// asks for the credentials as a Basic Authorization header.
+func isBasicChallenge(challenge string) bool {
+	scheme, _, _ := strings.Cut(challenge, " ")
+	return strings.EqualFold(scheme, "Basic")
+}
+
+// parseChallengeParams parses the comma separated key="value" parameters of
+// a WWW-Authenticate challenge.
+func parseChallengeParams(challenge string) map[string]string {
+	params := map[string]string{}
+	_, rest, _ := strings.Cut(challenge, " ")
+	for _, part := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return params
+}
+
+// rateLimitNote summarizes the RateLimit-Limit and RateLimit-Remaining
+// headers, as sent by Docker Hub in the form "100;w=21600", into a note such
+// as "authenticated pull limit: 100/6h, 76 remaining". Malformed headers are
+// ignored since the note is purely informational.
+func rateLimitNote(header http.Header, authenticated bool) string {
+	limit, window, ok := parseRateLimitHeader(header.Get("RateLimit-Limit"))
+	if !ok {
+		return ""
+	}
+
+	kind := "anonymous"
+	if authenticated {
+		kind = "authenticated"
+	}
+	note := fmt.Sprintf("%s pull limit: %d", kind, limit)
+	if window > 0 {
+		note += "/" + formatRateLimitWindow(window)
+	}
+	if remaining, _, ok := parseRateLimitHeader(header.Get("RateLimit-Remaining")); ok {
+		note += fmt.Sprintf(", %d remaining", remaining)
+	}
+	return note
+}
+
+// parseRateLimitHeader parses a "<quota>;w=<seconds>" rate limit header
+// value. The window is optional.
+func parseRateLimitHeader(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+	parts := strings.Split(value, ";")
+	quota, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	var window time.Duration
+	for _, param := range parts[1:] {
+		key, seconds, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || key != "w" {
+			continue
+		}
+		if n, err := strconv.Atoi(seconds); err == nil {
+			window = time.Duration(n) * time.Second
+		}
+	}
+	return quota, window, true
+}
+
+func formatRateLimitWindow(window time.Duration) string {
+	switch {
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%dh", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%dm", window/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", window/time.Second)
+	}
+}
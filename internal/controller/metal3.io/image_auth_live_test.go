@@ -0,0 +1,775 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func encodeTestCredentials(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// newTestRegistry starts a TLS registry that accepts testuser:testpass,
+// either directly (basic auth) or through a token endpoint (bearer auth).
+func newTestRegistry(t *testing.T, bearer bool) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	authorized := func(r *http.Request) bool {
+		username, password, ok := r.BasicAuth()
+		return ok && username == "testuser" && password == "testpass"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if bearer {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") != "test-registry" || !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "abc"})
+	})
+
+	server = httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPRegistryChecker(t *testing.T) {
+	tests := []struct {
+		name         string
+		bearer       bool
+		credentials  string
+		expectReject bool
+	}{
+		{name: "basic auth accepted", credentials: encodeTestCredentials("testuser", "testpass")},
+		{name: "basic auth rejected", credentials: encodeTestCredentials("testuser", "wrong"), expectReject: true},
+		{name: "token auth accepted", bearer: true, credentials: encodeTestCredentials("testuser", "testpass")},
+		{name: "token auth rejected", bearer: true, credentials: encodeTestCredentials("other", "testpass"), expectReject: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestRegistry(t, tt.bearer)
+			checker := NewHTTPRegistryChecker(server.Client())
+
+			err := checker.CheckCredentials(t.Context(), server.Listener.Addr().String(), tt.credentials)
+			if !tt.expectReject {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var rejected *RegistryAuthRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("expected RegistryAuthRejectedError, got %v", err)
+			}
+			if rejected.StatusCode != http.StatusUnauthorized {
+				t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rejected.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPRegistryChecker_BasicAuthProxy(t *testing.T) {
+	tests := []struct {
+		name         string
+		credentials  string
+		denyStatus   int
+		expectReject bool
+	}{
+		{name: "accepted", credentials: encodeTestCredentials("testuser", "testpass")},
+		{name: "rejected", credentials: encodeTestCredentials("testuser", "wrong"), denyStatus: http.StatusUnauthorized, expectReject: true},
+		{name: "forbidden", credentials: encodeTestCredentials("testuser", "wrong"), denyStatus: http.StatusForbidden, expectReject: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorizations []string
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization := r.Header.Get("Authorization")
+				authorizations = append(authorizations, authorization)
+				if authorization == "" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="Registry Proxy"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if username, password, _ := r.BasicAuth(); username != "testuser" || password != "testpass" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="Registry Proxy"`)
+					w.WriteHeader(tt.denyStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			err := NewHTTPRegistryChecker(server.Client()).CheckCredentials(
+				t.Context(), server.Listener.Addr().String(), tt.credentials)
+
+			if len(authorizations) != 2 || authorizations[0] != "" || !strings.HasPrefix(authorizations[1], "Basic ") {
+				t.Errorf("expected an anonymous probe followed by a Basic request, got %q", authorizations)
+			}
+			if !tt.expectReject {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var rejected *RegistryAuthRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("expected RegistryAuthRejectedError, got %v", err)
+			}
+			if rejected.StatusCode != tt.denyStatus {
+				t.Errorf("expected status %d, got %d", tt.denyStatus, rejected.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPRegistryPingChecker(t *testing.T) {
+	tests := []struct {
+		name           string
+		pingPath       string
+		anonymous      bool
+		bearer         bool
+		password       string
+		expectedReason ImageAuthReason
+	}{
+		{name: "anonymous access", anonymous: true, password: "wrong", expectedReason: ReasonValid},
+		{name: "basic auth accepted", password: "testpass", expectedReason: ReasonValid},
+		{name: "basic auth rejected", password: "wrong", expectedReason: ReasonAuthRejected},
+		{name: "token flow accepted", bearer: true, password: "testpass", expectedReason: ReasonValid},
+		{name: "token flow rejected", bearer: true, password: "wrong", expectedReason: ReasonAuthRejected},
+		{name: "custom ping path", pingPath: "healthz", password: "testpass", expectedReason: ReasonValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pingPath := "/v2/"
+			if tt.pingPath != "" {
+				pingPath = "/" + tt.pingPath
+			}
+			var server *httptest.Server
+			var pings []string
+			authorized := func(r *http.Request) bool {
+				username, password, ok := r.BasicAuth()
+				return ok && username == "testuser" && password == "testpass"
+			}
+			mux := http.NewServeMux()
+			mux.HandleFunc(pingPath, func(w http.ResponseWriter, r *http.Request) {
+				pings = append(pings, r.Header.Get("Authorization"))
+				switch {
+				case tt.anonymous:
+					w.WriteHeader(http.StatusOK)
+				case tt.bearer:
+					w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry"`)
+					w.WriteHeader(http.StatusUnauthorized)
+				case authorized(r):
+					w.WriteHeader(http.StatusOK)
+				default:
+					w.Header().Set("WWW-Authenticate", `Basic realm="test-registry"`)
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			})
+			mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+				if !authorized(r) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{"token": "abc"})
+			})
+			server = httptest.NewTLSServer(mux)
+			t.Cleanup(server.Close)
+
+			host := server.Listener.Addr().String()
+			dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+				"auths": map[string]interface{}{
+					host: map[string]interface{}{"auth": encodeTestCredentials("testuser", tt.password)},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to marshal docker config: %v", err)
+			}
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+				"oci://"+host+"/repo/image:tag",
+			)
+			validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+			validator.RegistryChecker = NewHTTPRegistryPingChecker(server.Client(), tt.pingPath)
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q (%v)", tt.expectedReason, result.Reason, err)
+			}
+			if (err == nil) != (tt.expectedReason == ReasonValid) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(pings) != 1 || !strings.HasPrefix(pings[0], "Basic ") {
+				t.Errorf("expected a single ping sending the credentials, got %q", pings)
+			}
+		})
+	}
+}
+
+func TestHTTPRegistryChecker_Unreachable(t *testing.T) {
+	credentials := encodeTestCredentials("testuser", "testpass")
+
+	t.Run("connection refused", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		host := server.Listener.Addr().String()
+		client := server.Client()
+		server.Close()
+
+		err := NewHTTPRegistryChecker(client).CheckCredentials(t.Context(), host, credentials)
+		var unreachable *RegistryUnreachableError
+		if !errors.As(err, &unreachable) {
+			t.Fatalf("expected RegistryUnreachableError, got %v", err)
+		}
+	})
+
+	t.Run("DNS failure", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, addr string) (net.Conn, error) {
+				return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+			},
+		}}
+
+		err := NewHTTPRegistryChecker(client).CheckCredentials(t.Context(), "registry.invalid", credentials)
+		var unreachable *RegistryUnreachableError
+		if !errors.As(err, &unreachable) {
+			t.Fatalf("expected RegistryUnreachableError, got %v", err)
+		}
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			t.Errorf("expected the DNS error to be preserved, got %v", err)
+		}
+	})
+
+	t.Run("unresponsive registry", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		checker, ok := NewHTTPRegistryChecker(server.Client()).(*httpRegistryChecker)
+		if !ok {
+			t.Fatal("expected an httpRegistryChecker")
+		}
+		checker.timeout = 50 * time.Millisecond
+		err := checker.CheckCredentials(t.Context(), server.Listener.Addr().String(), credentials)
+		var unreachable *RegistryUnreachableError
+		if !errors.As(err, &unreachable) {
+			t.Fatalf("expected RegistryUnreachableError, got %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the check to time out, got %v", err)
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := NewHTTPRegistryChecker(server.Client()).CheckCredentials(t.Context(), server.Listener.Addr().String(), credentials)
+		var unreachable *RegistryUnreachableError
+		if !errors.As(err, &unreachable) {
+			t.Fatalf("expected RegistryUnreachableError, got %v", err)
+		}
+	})
+}
+
+func TestHTTPRegistryChecker_RetryTransientErrors(t *testing.T) {
+	credentials := encodeTestCredentials("testuser", "testpass")
+	tests := []struct {
+		name          string
+		failures      int32
+		retry         RegistryRetryPolicy
+		timeout       time.Duration
+		expectErr     bool
+		expectAttempt int32
+	}{
+		{
+			name:          "recovers within the retries",
+			failures:      2,
+			retry:         RegistryRetryPolicy{Retries: 3, BaseDelay: time.Millisecond},
+			expectAttempt: 3,
+		},
+		{
+			name:          "retries exhausted",
+			failures:      5,
+			retry:         RegistryRetryPolicy{Retries: 2, BaseDelay: time.Millisecond},
+			expectErr:     true,
+			expectAttempt: 3,
+		},
+		{
+			name:          "retrying disabled",
+			failures:      1,
+			expectErr:     true,
+			expectAttempt: 1,
+		},
+		{
+			name:          "backoff past the context deadline",
+			failures:      1,
+			retry:         RegistryRetryPolicy{Retries: 3, BaseDelay: time.Minute},
+			timeout:       time.Second,
+			expectErr:     true,
+			expectAttempt: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if attempts.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			ctx := t.Context()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			checker := NewHTTPRegistryCheckerWithRetry(server.Client(), tt.retry)
+			err := checker.CheckCredentials(ctx, server.Listener.Addr().String(), credentials)
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectErr {
+				var unreachable *RegistryUnreachableError
+				if !errors.As(err, &unreachable) {
+					t.Fatalf("expected RegistryUnreachableError, got %v", err)
+				}
+			}
+			if got := attempts.Load(); got != tt.expectAttempt {
+				t.Errorf("expected %d attempts, got %d", tt.expectAttempt, got)
+			}
+		})
+	}
+}
+
+// fakeRegistryChecker returns a fixed error for every check.
+type fakeRegistryChecker struct {
+	err   error
+	calls int
+}
+
+func (f *fakeRegistryChecker) CheckCredentials(_ context.Context, _, _ string) error {
+	f.calls++
+	return f.err
+}
+
+func TestValidate_LiveValidationReasons(t *testing.T) {
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]interface{}{
+				"auth": encodeTestCredentials("testuser", "testpass"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		checkErr       error
+		expectedReason ImageAuthReason
+		expectedEvent  string
+	}{
+		{
+			name:           "accepted",
+			expectedReason: ReasonValid,
+		},
+		{
+			name:           "unreachable",
+			checkErr:       &RegistryUnreachableError{Host: "registry.example.com", Err: errors.New("connection refused")},
+			expectedReason: ReasonRegistryUnreachable,
+			expectedEvent:  EventAuthRegistryUnreachable,
+		},
+		{
+			name:           "rejected",
+			checkErr:       &RegistryAuthRejectedError{Host: "registry.example.com", StatusCode: http.StatusUnauthorized},
+			expectedReason: ReasonAuthRejected,
+			expectedEvent:  EventAuthRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			recorder := record.NewFakeRecorder(10)
+			checker := &fakeRegistryChecker{err: tt.checkErr}
+			validator := NewImageAuthValidator(recorder)
+			validator.RegistryChecker = checker
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if checker.calls != 1 {
+				t.Errorf("expected one live check, got %d", checker.calls)
+			}
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, result.Reason)
+			}
+			if tt.checkErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if reason, _ := imageAuthReason(err); reason != tt.expectedReason {
+				t.Errorf("expected error reason %q, got %q", tt.expectedReason, reason)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, tt.expectedEvent) {
+					t.Errorf("expected %s event, got %q", tt.expectedEvent, event)
+				}
+			default:
+				t.Error("expected warning event to be recorded")
+			}
+		})
+	}
+}
+
+func TestHTTPRegistryChecker_RateLimitNote(t *testing.T) {
+	tests := []struct {
+		name            string
+		headers         map[string]string
+		manifestHeaders map[string]string
+		challenge       string
+		repository      string
+		credentials     string
+		expectedNote    string
+	}{
+		{
+			name: "authenticated limit",
+			headers: map[string]string{
+				"RateLimit-Limit":     "200;w=21600",
+				"RateLimit-Remaining": "150;w=21600",
+			},
+			challenge:    `Basic realm="test-registry"`,
+			credentials:  encodeTestCredentials("testuser", "testpass"),
+			expectedNote: "authenticated pull limit: 200/6h, 150 remaining",
+		},
+		{
+			name:         "anonymous limit",
+			headers:      map[string]string{"RateLimit-Limit": "100;w=90"},
+			expectedNote: "anonymous pull limit: 100/90s",
+		},
+		{
+			name:         "anonymous access with credentials",
+			headers:      map[string]string{"RateLimit-Limit": "100;w=21600"},
+			credentials:  encodeTestCredentials("testuser", "testpass"),
+			expectedNote: "anonymous pull limit: 100/6h",
+		},
+		{
+			name:         "bearer challenge",
+			headers:      map[string]string{"RateLimit-Limit": "100;w=21600"},
+			challenge:    "Bearer",
+			credentials:  encodeTestCredentials("testuser", "testpass"),
+			expectedNote: "anonymous pull limit: 100/6h",
+		},
+		{
+			name: "manifest limit with bearer token",
+			manifestHeaders: map[string]string{
+				"RateLimit-Limit":     "200;w=21600",
+				"RateLimit-Remaining": "199;w=21600",
+			},
+			challenge:    "Bearer",
+			repository:   "org/image",
+			credentials:  encodeTestCredentials("testuser", "testpass"),
+			expectedNote: "authenticated pull limit: 200/6h, 199 remaining",
+		},
+		{
+			name:            "manifest limit with basic credentials",
+			manifestHeaders: map[string]string{"RateLimit-Limit": "200;w=21600"},
+			challenge:       `Basic realm="test-registry"`,
+			repository:      "org/image",
+			credentials:     encodeTestCredentials("testuser", "testpass"),
+			expectedNote:    "authenticated pull limit: 200/6h",
+		},
+		{
+			name:            "manifest limit preferred over ping limit",
+			headers:         map[string]string{"RateLimit-Limit": "100;w=21600"},
+			manifestHeaders: map[string]string{"RateLimit-Limit": "200;w=21600"},
+			challenge:       "Bearer",
+			repository:      "org/image",
+			credentials:     encodeTestCredentials("testuser", "testpass"),
+			expectedNote:    "authenticated pull limit: 200/6h",
+		},
+		{
+			name:         "ping limit kept without manifest limit",
+			headers:      map[string]string{"RateLimit-Limit": "100;w=21600"},
+			challenge:    "Bearer",
+			repository:   "org/image",
+			credentials:  encodeTestCredentials("testuser", "testpass"),
+			expectedNote: "anonymous pull limit: 100/6h",
+		},
+		{
+			name:            "manifest not requested without repository",
+			manifestHeaders: map[string]string{"RateLimit-Limit": "200;w=21600"},
+			challenge:       "Bearer",
+			credentials:     encodeTestCredentials("testuser", "testpass"),
+		},
+		{
+			name:        "no headers",
+			credentials: encodeTestCredentials("testuser", "testpass"),
+		},
+		{
+			name:        "malformed headers",
+			headers:     map[string]string{"RateLimit-Limit": "lots"},
+			credentials: encodeTestCredentials("testuser", "testpass"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/token" {
+					if scope := r.URL.Query().Get("scope"); tt.repository != "" && scope != "repository:"+tt.repository+":pull" {
+						t.Errorf("unexpected token scope %q", scope)
+					}
+					_ = json.NewEncoder(w).Encode(map[string]string{"token": "abc"})
+					return
+				}
+				_, _, authenticated := r.BasicAuth()
+				if r.URL.Path == "/v2/"+tt.repository+"/manifests/latest" {
+					if r.Method != http.MethodHead {
+						t.Errorf("expected a HEAD request of the manifest, got %s", r.Method)
+					}
+					if tt.challenge == "Bearer" && r.Header.Get("Authorization") != "Bearer abc" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					for key, value := range tt.manifestHeaders {
+						w.Header().Set(key, value)
+					}
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				for key, value := range tt.headers {
+					w.Header().Set(key, value)
+				}
+				switch {
+				case tt.challenge == "Bearer":
+					w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token"`)
+					w.WriteHeader(http.StatusUnauthorized)
+				case tt.challenge != "" && !authenticated:
+					w.Header().Set("WWW-Authenticate", tt.challenge)
+					w.WriteHeader(http.StatusUnauthorized)
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+			}))
+			defer server.Close()
+
+			checker, ok := NewHTTPRegistryChecker(server.Client()).(RateLimitReporter)
+			if !ok {
+				t.Fatal("expected the HTTP registry checker to report rate limits")
+			}
+			note, err := checker.CheckCredentialsWithRateLimit(t.Context(), server.Listener.Addr().String(), tt.repository, "latest", tt.credentials)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if note != tt.expectedNote {
+				t.Errorf("expected note %q, got %q", tt.expectedNote, note)
+			}
+		})
+	}
+}
+
+func TestValidate_RateLimitNote(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// Like Docker Hub, only advertise the limit on manifest requests.
+		if r.Method == http.MethodHead && r.URL.Path == "/v2/repo/image/manifests/tag" {
+			w.Header().Set("RateLimit-Limit", "200;w=21600")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	registryHost := server.Listener.Addr().String()
+
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			registryHost: map[string]interface{}{
+				"auth": encodeTestCredentials("testuser", "testpass"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+		"oci://"+registryHost+"/repo/image:tag",
+	)
+	validator := NewImageAuthValidator(nil)
+	validator.RegistryChecker = NewHTTPRegistryChecker(server.Client())
+
+	result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RateLimitNote != "authenticated pull limit: 200/6h" {
+		t.Errorf("unexpected rate limit note %q", result.RateLimitNote)
+	}
+}
+
+func TestHTTPRegistryChecker_CABundle(t *testing.T) {
+	server := newTestRegistry(t, false)
+	host := server.Listener.Addr().String()
+	credentials := encodeTestCredentials("testuser", "testpass")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	checker := NewHTTPRegistryChecker(&http.Client{})
+
+	var unreachable *RegistryUnreachableError
+	if err := checker.CheckCredentials(t.Context(), host, credentials); !errors.As(err, &unreachable) {
+		t.Fatalf("expected the registry CA to be untrusted, got %v", err)
+	}
+
+	withCA, err := checker.(CABundleChecker).WithCABundle(caBundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := withCA.CheckCredentials(t.Context(), host, credentials); err != nil {
+		t.Errorf("unexpected error with the CA bundle: %v", err)
+	}
+	if err := checker.CheckCredentials(t.Context(), host, credentials); !errors.As(err, &unreachable) {
+		t.Errorf("expected the original checker to be left unchanged, got %v", err)
+	}
+
+	if _, err := checker.(CABundleChecker).WithCABundle([]byte("not a certificate")); err == nil {
+		t.Error("expected an error for a bundle without certificates")
+	}
+}
+
+func TestValidate_RegistryCASecret(t *testing.T) {
+	server := newTestRegistry(t, false)
+	host := server.Listener.Addr().String()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			host: map[string]interface{}{"auth": encodeTestCredentials("testuser", "testpass")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		caData         map[string][]byte
+		expectedReason ImageAuthReason
+	}{
+		{
+			name:           "CA bundle trusted",
+			caData:         map[string][]byte{secretutils.RegistryCABundleKey: caBundle},
+			expectedReason: ReasonValid,
+		},
+		{
+			name:           "CA secret missing",
+			expectedReason: ReasonInvalidCABundle,
+		},
+		{
+			name:           "CA bundle invalid",
+			caData:         map[string][]byte{secretutils.RegistryCABundleKey: []byte("not a certificate")},
+			expectedReason: ReasonInvalidCABundle,
+		},
+		{
+			name:           "CA bundle key missing",
+			caData:         map[string][]byte{"other.crt": caBundle},
+			expectedReason: ReasonInvalidCABundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+				"oci://"+host+"/repo/image:tag",
+			)
+			bmh.Annotations = map[string]string{ImageAuthCASecretAnnotation: "registry-ca"}
+			caKey := types.NamespacedName{Namespace: bmh.Namespace, Name: "registry-ca"}
+			if tt.caData != nil {
+				caSecret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: caKey.Name, Namespace: caKey.Namespace},
+					Data:       tt.caData,
+				}
+				if err := c.Create(t.Context(), caSecret); err != nil {
+					t.Fatalf("failed to create CA secret: %v", err)
+				}
+			}
+			validator := NewImageAuthValidator(nil)
+			validator.RegistryChecker = NewHTTPRegistryChecker(&http.Client{})
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q (%v)", tt.expectedReason, result.Reason, err)
+			}
+			if (err == nil) != (tt.expectedReason == ReasonValid) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.caData == nil {
+				return
+			}
+			caSecret := &corev1.Secret{}
+			if err := c.Get(t.Context(), caKey, caSecret); err != nil {
+				t.Fatalf("failed to get CA secret: %v", err)
+			}
+			if caSecret.Labels[secretutils.LabelEnvironmentName] != secretutils.LabelEnvironmentValue {
+				t.Errorf("expected the CA secret to be labelled for the secret watch, got labels %v", caSecret.Labels)
+			}
+		})
+	}
+}
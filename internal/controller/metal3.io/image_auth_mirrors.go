@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageAuthMirrorsAnnotation may be set on a host to list, comma separated
+// and in order of preference, registry mirrors serving its image. When the
+// auth secret has no credentials for the image registry, the credentials of
+// the first mirror found in the secret are used instead. Since the image is
+// still pulled from its own registry, only mirrors configured in
+// ExtractOptions.UpstreamMirrors for that registry are considered, so that
+// the annotation cannot hand the credentials of an unrelated registry to the
+// image host.
+const ImageAuthMirrorsAnnotation = "metal3.io/image-auth-mirrors"
+
+// imageAuthMirrors returns the mirrors listed on the host, in order.
+func imageAuthMirrors(bmh *metal3api.BareMetalHost) []string {
+	var mirrors []string
+	for _, mirror := range strings.Split(bmh.GetAnnotations()[ImageAuthMirrorsAnnotation], ",") {
+		if mirror = strings.TrimSpace(mirror); mirror != "" {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	return mirrors
+}
+
+// resolveFromMirrors looks up credentials for the image on each mirror listed
+// on the host, in order, when resolveErr reports the image registry missing
+// from the secret. Mirrors that are not configured as mirrors of the image
+// registry are skipped. It returns the credentials, the mirror they were found
+// for and the image URL rewritten to that mirror, or nil if no mirror has
+// credentials.
+func (v *ImageAuthValidator) resolveFromMirrors(sec *corev1.Secret, bmh *metal3api.BareMetalHost, imageURL string, resolveErr error) (*secretutils.RegistryCredentials, string, string) {
+	var notFound *secretutils.RegistryNotFoundError
+	if !errors.As(resolveErr, &notFound) {
+		return nil, "", ""
+	}
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, "", ""
+	}
+	registryHost, err := secretutils.ResolveRegistryHost(imageURL, v.ExtractOptions)
+	if err != nil {
+		return nil, "", ""
+	}
+	for _, mirror := range imageAuthMirrors(bmh) {
+		if !secretutils.IsUpstreamMirror(mirror, registryHost, v.ExtractOptions) {
+			continue
+		}
+		mirrored := *parsed
+		mirrored.Host = mirror
+		creds, err := secretutils.ResolveRegistryCredentials(sec, mirrored.String(), v.ExtractOptions)
+		if err == nil {
+			return creds, mirror, mirrored.String()
+		}
+	}
+	return nil, "", ""
+}
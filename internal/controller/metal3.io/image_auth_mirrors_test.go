@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidate_RegistryMirrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		mirrors      string
+		expectErr    bool
+		expectHost   string
+		expectMirror string
+	}{
+		{
+			name:       "primary present",
+			config:     `{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}, "mirror1.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			mirrors:    "mirror1.example.com",
+			expectHost: "registry.example.com",
+		},
+		{
+			name:         "primary missing but mirror present",
+			config:       `{"auths": {"mirror2.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			mirrors:      "mirror1.example.com, mirror2.example.com",
+			expectHost:   "mirror2.example.com",
+			expectMirror: "mirror2.example.com",
+		},
+		{
+			name:         "first listed mirror wins",
+			config:       `{"auths": {"mirror1.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}, "mirror2.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			mirrors:      "mirror2.example.com,mirror1.example.com",
+			expectHost:   "mirror2.example.com",
+			expectMirror: "mirror2.example.com",
+		},
+		{
+			name:      "neither primary nor mirrors present",
+			config:    `{"auths": {"other.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			mirrors:   "mirror1.example.com",
+			expectErr: true,
+		},
+		{
+			name:      "listed mirror not configured for the image registry",
+			config:    `{"auths": {"quay.io": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			mirrors:   "quay.io",
+			expectErr: true,
+		},
+		{
+			name:      "no mirrors listed",
+			config:    `{"auths": {"mirror1.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(tt.config)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			if tt.mirrors != "" {
+				bmh.Annotations = map[string]string{ImageAuthMirrorsAnnotation: tt.mirrors}
+			}
+
+			validator := NewImageAuthValidator(nil)
+			validator.ExtractOptions.UpstreamMirrors = map[string][]string{
+				"registry.example.com": {"mirror1.example.com", "mirror2.example.com"},
+			}
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.RegistryHost != tt.expectHost {
+				t.Errorf("expected credentials for %s, got %s", tt.expectHost, result.RegistryHost)
+			}
+			if result.MirrorHost != tt.expectMirror {
+				t.Errorf("expected mirror %q, got %q", tt.expectMirror, result.MirrorHost)
+			}
+			if expectFallback := tt.expectMirror != ""; result.UsedFallback != expectFallback {
+				t.Errorf("expected UsedFallback %v, got %v (%s)", expectFallback, result.UsedFallback, result.FallbackReason)
+			}
+			if tt.expectMirror != "" && !strings.Contains(result.FallbackReason, tt.expectMirror) {
+				t.Errorf("expected the fallback reason to name mirror %s, got %q", tt.expectMirror, result.FallbackReason)
+			}
+		})
+	}
+}
+
+// TestValidate_RegistryMirrorsCrossHost tests that the mirrors annotation
+// cannot select the credentials of a registry that is not a configured mirror
+// of the image registry, nor of an untrusted mirror.
+func TestValidate_RegistryMirrorsCrossHost(t *testing.T) {
+	tests := []struct {
+		name       string
+		imageURL   string
+		mirrors    map[string][]string
+		trusted    []string
+		expectErr  bool
+		expectHost string
+	}{
+		{
+			name:      "image on another host",
+			imageURL:  "oci://attacker.example.net/repo/image:tag",
+			mirrors:   map[string][]string{"registry.example.com": {"quay.io"}},
+			expectErr: true,
+		},
+		{
+			name:      "mirror configured for the image host but not trusted",
+			imageURL:  "oci://registry.example.com/repo/image:tag",
+			mirrors:   map[string][]string{"registry.example.com": {"quay.io"}},
+			trusted:   []string{"registry.example.com"},
+			expectErr: true,
+		},
+		{
+			name:       "mirror configured and trusted",
+			imageURL:   "oci://registry.example.com/repo/image:tag",
+			mirrors:    map[string][]string{"registry.example.com": {"quay.io"}},
+			trusted:    []string{"registry.example.com", "quay.io"},
+			expectHost: "quay.io",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"quay.io": {"auth": "cXVheXVzZXI6cXVheXBhc3M="}}}`)},
+				tt.imageURL,
+			)
+			bmh.Annotations = map[string]string{ImageAuthMirrorsAnnotation: "quay.io"}
+
+			validator := NewImageAuthValidator(nil)
+			validator.ExtractOptions.UpstreamMirrors = tt.mirrors
+			validator.TrustedRegistries = tt.trusted
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if result.Credentials != "" {
+					t.Errorf("expected no credentials, got those for %s", result.RegistryHost)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.RegistryHost != tt.expectHost {
+				t.Errorf("expected credentials for %s, got %s", tt.expectHost, result.RegistryHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+)
+
+// ImageAuthResult describes the outcome of validating the image
+// authentication secret of a host.
+//
+// The credentials are never included when the result is marshalled to JSON,
+// so the result can safely be logged or exposed through the API. Internal
+// callers that need the full struct must use MarshalJSONWithCredentials.
+type ImageAuthResult struct {
+	// OCIRelevant is true when the host image is an OCI image with an
+	// auth secret configured.
+	OCIRelevant bool `json:"ociRelevant"`
+	// Reason summarizes the outcome.
+	Reason ImageAuthReason `json:"reason"`
+	// Message is a human readable explanation of a failure.
+	Message string `json:"message,omitempty"`
+	// SecretName and SecretNamespace identify the auth secret.
+	SecretName      string `json:"secretName,omitempty"`
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+	// SecretResourceVersion is the resourceVersion of the auth secret the
+	// result was derived from.
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+	// RegistryHost is the registry the image is pulled from.
+	RegistryHost string `json:"registryHost,omitempty"`
+	// MatchedAuthKey is the auths entry the credentials were taken from, as
+	// stored in the secret.
+	MatchedAuthKey string `json:"matchedAuthKey,omitempty"`
+	// CanonicalAuthKey is MatchedAuthKey with Docker Hub aliases reported
+	// under the canonical Docker Hub name.
+	CanonicalAuthKey string `json:"canonicalAuthKey,omitempty"`
+	// MirrorHost is the mirror, listed in ImageAuthMirrorsAnnotation, whose
+	// credentials are used because the secret has none for the image
+	// registry itself.
+	MirrorHost string `json:"mirrorHost,omitempty"`
+	// UsedFallback is true when the credentials were not found through
+	// spec.image.ociAuthSecretName for the image registry, but through a
+	// fallback such as the secret mapping or a registry mirror.
+	UsedFallback bool `json:"usedFallback,omitempty"`
+	// FallbackReason explains which fallback was used and why.
+	FallbackReason string `json:"fallbackReason,omitempty"`
+	// MatchedAuthConfig is a redacted copy of the matched auths entry, set
+	// when the validator ExtractOptions.IncludeMatchedAuthConfig is enabled.
+	MatchedAuthConfig *secretutils.RedactedAuthConfig `json:"matchedAuthConfig,omitempty"`
+	// Metadata summarizes the credentials in use for status and metrics
+	// pipelines. It is set when credentials were found.
+	Metadata *CredentialMetadata `json:"metadata,omitempty"`
+	// CredentialFingerprint is a salted hash of the credentials that can be
+	// compared across reconciles to detect credential drift without
+	// exposing them. The salt is generated per process, so fingerprints
+	// are only comparable within the lifetime of the operator process.
+	CredentialFingerprint string `json:"credentialFingerprint,omitempty"`
+	// RateLimitNote is an informational summary of the pull rate limit
+	// advertised by the registry during live validation, such as
+	// "authenticated pull limit: 200/6h". It never affects the outcome.
+	RateLimitNote string `json:"rateLimitNote,omitempty"`
+	// StaleCredentials is true when the secret exceeds the configured
+	// maximum credential age and should be rotated.
+	StaleCredentials bool `json:"staleCredentials,omitempty"`
+	// SecretRecreated is true when the auth secret UID differs from the
+	// one pinned on the host.
+	SecretRecreated bool `json:"secretRecreated,omitempty"`
+	// NewerSecretNote is an informational note set when the auth secret was
+	// created after the last successful validation recorded on the host.
+	NewerSecretNote string `json:"newerSecretNote,omitempty"`
+	// Warnings lists non-fatal advisories, such as weak credentials or a
+	// malformed but usable config, that were also emitted as events.
+	Warnings []string `json:"warnings,omitempty"`
+	// Problems lists every problem found in CollectAll mode.
+	Problems []ImageAuthProblem `json:"problems,omitempty"`
+	// Credentials is the base64-encoded "username:password" passed to
+	// Ironic. It is never marshalled by default.
+	Credentials string `json:"-"`
+
+	problemErrs []error
+	// inputsHash is the imageAuthInputsHash of the host, set when the
+	// validator reuses valid results.
+	inputsHash string
+}
+
+// CredentialKind is the kind of credentials found for a registry.
+type CredentialKind string
+
+const (
+	// CredentialKindBasic is a username and password.
+	CredentialKindBasic CredentialKind = "basic"
+	// CredentialKindToken is a token, such as an identity token or a
+	// registry refresh token.
+	CredentialKindToken CredentialKind = "token"
+)
+
+// CredentialMetadata is the non-sensitive description of the credentials a
+// host uses, for observability without access to the secret.
+type CredentialMetadata struct {
+	RegistryHost    string         `json:"registryHost"`
+	Kind            CredentialKind `json:"kind"`
+	SecretName      string         `json:"secretName"`
+	SecretNamespace string         `json:"secretNamespace"`
+	MatchedAuthKey  string         `json:"matchedAuthKey"`
+	// LegacyFormat is true when the credentials come from the legacy
+	// dockercfg key.
+	LegacyFormat bool `json:"legacyFormat"`
+}
+
+// ImageAuthProblem is a single problem found by the validator.
+type ImageAuthProblem struct {
+	Reason  ImageAuthReason `json:"reason"`
+	Message string          `json:"message"`
+}
+
+// snapshot returns a copy of the result sharing no mutable state with it.
+func (r *ImageAuthResult) snapshot() ImageAuthResult {
+	snapshot := *r
+	snapshot.Warnings = slices.Clone(r.Warnings)
+	snapshot.Problems = slices.Clone(r.Problems)
+	snapshot.problemErrs = slices.Clone(r.problemErrs)
+	if r.MatchedAuthConfig != nil {
+		matched := *r.MatchedAuthConfig
+		snapshot.MatchedAuthConfig = &matched
+	}
+	if r.Metadata != nil {
+		metadata := *r.Metadata
+		snapshot.Metadata = &metadata
+	}
+	return snapshot
+}
+
+// credentialMetadata describes the credentials resolved for the result.
+func credentialMetadata(result *ImageAuthResult, creds *secretutils.RegistryCredentials) *CredentialMetadata {
+	kind := CredentialKindBasic
+	if creds.TokenAuth {
+		kind = CredentialKindToken
+	}
+	return &CredentialMetadata{
+		RegistryHost:    creds.RegistryHost,
+		Kind:            kind,
+		SecretName:      result.SecretName,
+		SecretNamespace: result.SecretNamespace,
+		MatchedAuthKey:  creds.MatchedAuthKey,
+		LegacyFormat:    creds.LegacyFormat,
+	}
+}
+
+var (
+	fingerprintSaltOnce sync.Once
+	fingerprintSalt     []byte
+)
+
+// credentialFingerprint returns a hex-encoded HMAC-SHA256 of the credentials
+// keyed with a random per-process salt.
+func credentialFingerprint(credentials string) string {
+	fingerprintSaltOnce.Do(func() {
+		fingerprintSalt = make([]byte, sha256.Size)
+		if _, err := rand.Read(fingerprintSalt); err != nil {
+			panic(fmt.Sprintf("failed to generate credential fingerprint salt: %v", err))
+		}
+	})
+	mac := hmac.New(sha256.New, fingerprintSalt)
+	mac.Write([]byte(credentials))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// fail records the failure reason and message on the result and returns it
+// together with err.
+func (r *ImageAuthResult) fail(reason ImageAuthReason, err error) (*ImageAuthResult, error) {
+	r.Reason = reason
+	r.Message = err.Error()
+	return r, err
+}
+
+// collect records a problem found in CollectAll mode.
+func (r *ImageAuthResult) collect(reason ImageAuthReason, err error) {
+	r.Problems = append(r.Problems, ImageAuthProblem{Reason: reason, Message: err.Error()})
+	r.problemErrs = append(r.problemErrs, err)
+}
+
+// failCollected fails the result with all the collected problems, the first
+// one giving the reason.
+func (r *ImageAuthResult) failCollected() (*ImageAuthResult, error) {
+	return r.fail(r.Problems[0].Reason, errors.Join(r.problemErrs...))
+}
+
+// Provenance returns a human readable description of where the credentials
+// came from, suitable for audit trails.
+func (r *ImageAuthResult) Provenance() string {
+	return fmt.Sprintf("credentials from secret %s/%s, key %s", r.SecretNamespace, r.SecretName, r.MatchedAuthKey)
+}
+
+// MarshalJSONWithCredentials marshals the result including the credentials.
+// It must only be used by internal callers and never for API output or logs.
+func (r *ImageAuthResult) MarshalJSONWithCredentials() ([]byte, error) {
+	type plain ImageAuthResult
+	return json.Marshal(struct {
+		*plain
+		Credentials string `json:"credentials,omitempty"`
+	}{
+		plain:       (*plain)(r),
+		Credentials: r.Credentials,
+	})
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImageAuthResult_MarshalJSONOmitsCredentials(t *testing.T) {
+	const credentials = "dGVzdHVzZXI6dGVzdHBhc3M="
+	result := &ImageAuthResult{
+		OCIRelevant:     true,
+		Reason:          ReasonValid,
+		SecretName:      "my-secret",
+		SecretNamespace: "default",
+		RegistryHost:    "registry.example.com",
+		MatchedAuthKey:  "registry.example.com",
+		Credentials:     credentials,
+	}
+
+	for name, value := range map[string]interface{}{
+		"pointer": result,
+		"value":   *result,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(string(data), credentials) || strings.Contains(string(data), "credentials") {
+				t.Errorf("credentials leaked into JSON output: %s", data)
+			}
+			if !strings.Contains(string(data), `"matchedAuthKey":"registry.example.com"`) {
+				t.Errorf("expected non-sensitive fields in JSON output: %s", data)
+			}
+		})
+	}
+}
+
+func TestImageAuthResult_MarshalJSONWithCredentials(t *testing.T) {
+	result := &ImageAuthResult{
+		OCIRelevant: true,
+		Reason:      ReasonValid,
+		Credentials: "dGVzdHVzZXI6dGVzdHBhc3M=",
+	}
+
+	data, err := result.MarshalJSONWithCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded["credentials"] != result.Credentials {
+		t.Errorf("expected credentials in opt-in output, got: %s", data)
+	}
+	if decoded["reason"] != string(ReasonValid) {
+		t.Errorf("expected reason in opt-in output, got: %s", data)
+	}
+}
+
+func TestCredentialFingerprint(t *testing.T) {
+	const credentials = "dGVzdHVzZXI6dGVzdHBhc3M="
+
+	first := credentialFingerprint(credentials)
+	if first == "" {
+		t.Fatal("expected a fingerprint")
+	}
+	if again := credentialFingerprint(credentials); again != first {
+		t.Errorf("expected a stable fingerprint, got %q and %q", first, again)
+	}
+	if changed := credentialFingerprint("dGVzdHVzZXI6b3RoZXJwYXNz"); changed == first {
+		t.Error("expected the fingerprint to change with the credentials")
+	}
+	if strings.Contains(first, credentials) {
+		t.Error("fingerprint must not contain the credentials")
+	}
+}
+
+func TestValidate_CredentialMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretType corev1.SecretType
+		data       map[string][]byte
+		expected   CredentialMetadata
+	}{
+		{
+			name:       "basic credentials",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+			expected: CredentialMetadata{
+				RegistryHost:    "registry.example.com",
+				Kind:            CredentialKindBasic,
+				SecretName:      "test-secret",
+				SecretNamespace: "default",
+				MatchedAuthKey:  "registry.example.com",
+			},
+		},
+		{
+			name:       "identity token",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"https://registry.example.com": {"identitytoken": "refresh-token"}}}`)},
+			expected: CredentialMetadata{
+				RegistryHost:    "registry.example.com",
+				Kind:            CredentialKindToken,
+				SecretName:      "test-secret",
+				SecretNamespace: "default",
+				MatchedAuthKey:  "https://registry.example.com",
+			},
+		},
+		{
+			name:       "legacy format",
+			secretType: corev1.SecretTypeDockercfg,
+			data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}`)},
+			expected: CredentialMetadata{
+				RegistryHost:    "registry.example.com",
+				Kind:            CredentialKindBasic,
+				SecretName:      "test-secret",
+				SecretNamespace: "default",
+				MatchedAuthKey:  "registry.example.com",
+				LegacyFormat:    true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(t, tt.secretType, tt.data, "oci://registry.example.com/repo/image:tag")
+
+			result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Metadata == nil {
+				t.Fatal("expected credential metadata")
+			}
+			if *result.Metadata != tt.expected {
+				t.Errorf("expected metadata %+v, got %+v", tt.expected, *result.Metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// imageAuthRevalidationEnabled reports whether provisioned hosts should have
+// their image auth credentials re-checked periodically. Re-validation only
+// makes sense with live validation, as the secret itself is watched.
+func (r *BareMetalHostReconciler) imageAuthRevalidationEnabled() bool {
+	return r.ImageAuthRegistryChecker != nil && r.ImageAuthRevalidationInterval > 0
+}
+
+// usesImageAuth reports whether the host image is an OCI image pulled with an
+// auth secret.
+func usesImageAuth(host *metal3api.BareMetalHost) bool {
+	return imageUsesAuth(host.Spec.Image)
+}
+
+// imageUsesAuth reports whether the image is an OCI image pulled with an auth
+// secret.
+func imageUsesAuth(image *metal3api.Image) bool {
+	return image != nil && image.IsOCI() && image.OCIAuthSecretName != nil && *image.OCIAuthSecretName != ""
+}
+
+// provisionedImage returns the image the host was provisioned with, which is
+// the one whose credentials are re-validated. The spec image may have changed
+// since without the host being provisioned again.
+func provisionedImage(host *metal3api.BareMetalHost) *metal3api.Image {
+	return &host.Status.Provisioning.Image
+}
+
+// revalidateImageAuth re-checks the image auth credentials of the image a
+// provisioned host was provisioned with, once the re-validation interval has
+// elapsed. The outcome is reported through the image auth conditions and the
+// validation events only: the host is already provisioned, so a failure must
+// not change its state, and the registry recorded for the host is left alone.
+func (r *BareMetalHostReconciler) revalidateImageAuth(ctx context.Context, info *reconcileInfo) {
+	key := types.NamespacedName{Namespace: info.host.Namespace, Name: info.host.Name}
+	image := provisionedImage(info.host)
+	if !r.imageAuthRevalidationEnabled() || !imageUsesAuth(image) || skipImageAuthValidation(info.host) {
+		r.imageAuthLastChecked.Delete(key)
+		return
+	}
+
+	now := time.Now()
+	if last, ok := r.imageAuthLastChecked.Load(key); ok && now.Sub(last.(time.Time)) < r.ImageAuthRevalidationInterval {
+		return
+	}
+	r.imageAuthLastChecked.Store(key, now)
+
+	recorder := r.imageAuthRecorder
+	if recorder == nil {
+		recorder = r.Recorder
+	}
+	validator := r.imageAuthValidator(recorder)
+	// Re-validation must reach the registry even when nothing changed.
+	validator.revalidate = true
+	result, err := aggregateImageAuthOutcomes(validator.EvaluateImages(
+		ctx, info.host, imagesToValidate(info.host, image), r.secretManager(ctx, info.log)))
+	setImageAuthHealthy(info.host, result, err)
+	if err != nil {
+		info.log.Info("image auth re-validation failed", "reason", err.Error())
+		info.host.Status.ImageAuth = nil
+		conditions.Set(info.host, metav1.Condition{
+			Type:    metal3api.ImageAuthInUseCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(result.Reason),
+			Message: result.Message,
+		})
+		return
+	}
+	if result.Credentials != "" {
+		conditions.Set(info.host, metav1.Condition{
+			Type:    metal3api.ImageAuthInUseCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  metal3api.ImageAuthCredentialsInjectedReason,
+			Message: r.imageAuthInUseMessage(result),
+		})
+	}
+}
+
+// imageAuthConditionsChanged reports whether the image auth conditions of the
+// host differ from those in before.
+func imageAuthConditionsChanged(before []metav1.Condition, host *metal3api.BareMetalHost) bool {
+	for _, conditionType := range []string{metal3api.ImageAuthInUseCondition, metal3api.ImageAuthHealthyCondition} {
+		if !reflect.DeepEqual(meta.FindStatusCondition(before, conditionType), conditions.Get(host, conditionType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// requeueForImageAuthRevalidation makes sure a host whose image auth
+// credentials were validated successfully is reconciled again within the
+// re-validation interval.
+func (r *BareMetalHostReconciler) requeueForImageAuthRevalidation(host *metal3api.BareMetalHost, result ctrl.Result) ctrl.Result {
+	if !r.imageAuthRevalidationEnabled() || !imageUsesAuth(provisionedImage(host)) {
+		return result
+	}
+	if !conditions.IsTrue(host, metal3api.ImageAuthInUseCondition) {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > r.ImageAuthRevalidationInterval {
+		result.RequeueAfter = r.ImageAuthRevalidationInterval
+	}
+	return result
+}
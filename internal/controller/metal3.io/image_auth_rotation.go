@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageAuthRotatedAtAnnotation may be set on an image auth secret to record,
+// in RFC 3339 format, when its credentials were last rotated. It is compared
+// against the validator MaxCredentialAge.
+const ImageAuthRotatedAtAnnotation = "metal3.io/image-auth-rotated-at"
+
+// staleCredentialProblem returns a description of why the credentials in the
+// secret are due for rotation, or an empty string if they are not or the
+// secret does not record its rotation time.
+func staleCredentialProblem(sec *corev1.Secret, maxAge time.Duration, now time.Time) string {
+	value, ok := sec.Annotations[ImageAuthRotatedAtAnnotation]
+	if !ok {
+		return ""
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Sprintf("the %s annotation %q is not an RFC 3339 time", ImageAuthRotatedAtAnnotation, value)
+	}
+	if age := now.Sub(rotatedAt); age > maxAge {
+		return fmt.Sprintf("credentials were last rotated at %s, %s ago, exceeding the maximum age of %s",
+			rotatedAt.Format(time.RFC3339), age.Truncate(time.Minute), maxAge)
+	}
+	return ""
+}
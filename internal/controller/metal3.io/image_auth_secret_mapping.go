@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImageAuthSecretMapping locates a ConfigMap mapping registry hosts to the
+// name of the image auth secret to use for them. It is consulted for hosts
+// that do not set spec.image.ociAuthSecretName. The secret is looked up in
+// the namespace of the host.
+//
+// Keys are registry patterns as accepted by secretutils.RegistryMatches, such
+// as "registry.example.com" or "*.example.com"; an exact key wins over a
+// pattern.
+type ImageAuthSecretMapping struct {
+	Reader    client.Reader
+	ConfigMap types.NamespacedName
+	// CacheTTL is how long a read of the ConfigMap is reused before it is
+	// read again. Zero reads it on every lookup.
+	CacheTTL time.Duration
+
+	mu       sync.Mutex
+	cached   map[string]string
+	cachedAt time.Time
+}
+
+// defaultImageAuthSecretMappingTTL is the CacheTTL of the secret mapping used
+// by the controller, so that hosts without an auth secret do not read the
+// ConfigMap from the API server on every reconcile.
+const defaultImageAuthSecretMappingTTL = time.Minute
+
+// mappingData returns the data of the ConfigMap, reusing the previous read
+// while it is younger than CacheTTL. A missing ConfigMap has no data.
+func (m *ImageAuthSecretMapping) mappingData(ctx context.Context) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.CacheTTL > 0 && !m.cachedAt.IsZero() && time.Since(m.cachedAt) < m.CacheTTL {
+		return m.cached, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := m.Reader.Get(ctx, m.ConfigMap, cm); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to read image auth secret mapping %s: %w", m.ConfigMap, err)
+		}
+		cm.Data = nil
+	}
+	m.cached, m.cachedAt = cm.Data, time.Now()
+	return m.cached, nil
+}
+
+// secretNameFor returns the secret name mapped to the registry hosting the
+// image, or an empty string if there is none. A missing ConfigMap is treated
+// as an empty mapping.
+func (m *ImageAuthSecretMapping) secretNameFor(ctx context.Context, imageURL string) (string, error) {
+	registryHost, err := secretutils.RegistryHostForImage(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot determine image registry: %w", err)
+	}
+
+	data, err := m.mappingData(ctx)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := data[registryHost]; ok {
+		return strings.TrimSpace(name), nil
+	}
+	patterns := make([]string, 0, len(data))
+	for pattern := range data {
+		patterns = append(patterns, pattern)
+	}
+	slices.Sort(patterns)
+	for _, pattern := range patterns {
+		if secretutils.RegistryMatches(registryHost, pattern) {
+			return strings.TrimSpace(data[pattern]), nil
+		}
+	}
+	return "", nil
+}
+
+// warnMappedSecretConflict warns when the secret mapping names another secret
+// for the image registry than the one the host references, and the two hold
+// different credentials. The secret of the host takes precedence. The mapped
+// secret is only read for the warning, without being claimed, so failing to
+// read it is ignored.
+func (v *ImageAuthValidator) warnMappedSecretConflict(ctx context.Context, bmh *metal3api.BareMetalHost, result *ImageAuthResult, sec *corev1.Secret, imageURL string, secretMgr secretutils.SecretManager) {
+	mapped, err := v.SecretMapping.secretNameFor(ctx, imageURL)
+	if err != nil || mapped == "" {
+		return
+	}
+	key := types.NamespacedName{Namespace: bmh.Namespace, Name: mapped}
+	if key == client.ObjectKeyFromObject(sec) {
+		return
+	}
+	mappedSec, err := secretMgr.ReadSecret(ctx, key)
+	if err != nil {
+		return
+	}
+	conflict, err := secretutils.DetectCredentialConflict(sec, mappedSec, imageURL, v.ExtractOptions)
+	if err != nil || conflict == nil {
+		return
+	}
+	v.warn(bmh, result, EventAuthCredentialConflict, conflict.Message())
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// imageAuthSecretKey resolves the auth secret reference of the host. A
+// reference of the form "namespace/name" names a secret in another
+// namespace, which is only accepted when that namespace is listed in
+// allowedNamespaces; any other reference names a secret in the host
+// namespace.
+func imageAuthSecretKey(bmh *metal3api.BareMetalHost, ref string, allowedNamespaces []string) (types.NamespacedName, error) {
+	key, err := parseImageAuthSecretRef(bmh, ref)
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	if key.Namespace == bmh.Namespace || slices.Contains(allowedNamespaces, key.Namespace) {
+		return key, nil
+	}
+	if len(allowedNamespaces) == 0 {
+		return types.NamespacedName{}, &ImageAuthError{
+			Reason: ReasonInvalidSecretReference,
+			Message: fmt.Sprintf("auth secret reference %q points to namespace %q but cross-namespace auth secrets are not enabled; "+
+				"copy the secret to namespace %q or enable cross-namespace references", ref, key.Namespace, bmh.Namespace),
+		}
+	}
+	return types.NamespacedName{}, &ImageAuthError{
+		Reason: ReasonInvalidSecretReference,
+		Message: fmt.Sprintf("auth secret reference %q points to namespace %q, which is not one of the namespaces auth secrets may be shared from (%s)",
+			ref, key.Namespace, strings.Join(allowedNamespaces, ", ")),
+	}
+}
+
+// parseImageAuthSecretRef parses the auth secret reference of the host
+// without checking whether a reference to another namespace is allowed.
+func parseImageAuthSecretRef(bmh *metal3api.BareMetalHost, ref string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		return types.NamespacedName{Namespace: bmh.Namespace, Name: ref}, nil
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return types.NamespacedName{}, &ImageAuthError{
+			Reason:  ReasonInvalidSecretReference,
+			Message: fmt.Sprintf("auth secret reference %q is invalid; use <name> or <namespace>/<name>", ref),
+		}
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// ImageAuthCASecretAnnotation may be set on a host with an OCI image to name
+// a secret, in the host namespace, holding the registry CA bundle under
+// secretutils.RegistryCABundleKey, for registries whose CA is managed apart
+// from the auth secret. The bundle is trusted by live validation, and the CA
+// secret is watched like the auth secret, so that a CA rotation triggers
+// re-validation.
+const ImageAuthCASecretAnnotation = "metal3.io/image-auth-ca-secret"
+
+// DependentSecrets returns the secrets the host references for pulling its
+// image: its OCI auth secret and its registry CA secret. References to
+// another namespace are included whether or not they are enabled; malformed
+// ones are left out.
+func DependentSecrets(host *metal3api.BareMetalHost) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	if usesImageAuth(host) {
+		if key, err := parseImageAuthSecretRef(host, *host.Spec.Image.OCIAuthSecretName); err == nil {
+			secrets = append(secrets, key)
+		}
+	}
+	if caSecret := host.GetAnnotations()[ImageAuthCASecretAnnotation]; caSecret != "" && host.Spec.Image != nil && host.Spec.Image.IsOCI() {
+		secrets = append(secrets, types.NamespacedName{Namespace: host.Namespace, Name: caSecret})
+	}
+	return secrets
+}
+
+// registryCABundle reads the registry CA bundle from the CA secret of the
+// host. The secret is obtained through the secret manager so that it is
+// labelled, and its changes reach the secret watch.
+func registryCABundle(ctx context.Context, bmh *metal3api.BareMetalHost, name string, secretMgr secretutils.SecretManager) ([]byte, error) {
+	sec, err := secretMgr.ObtainSecret(ctx, types.NamespacedName{Namespace: bmh.Namespace, Name: name})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, &ImageAuthError{
+				Reason:  ReasonInvalidCABundle,
+				Message: fmt.Sprintf("registry CA secret %q not found in namespace %q", name, bmh.Namespace),
+				Err:     err,
+			}
+		}
+		return nil, err
+	}
+	bundle, err := secretutils.ExtractRegistryCABundle(sec)
+	if err == nil && bundle == nil {
+		err = fmt.Errorf("secret has no %s key", secretutils.RegistryCABundleKey)
+	}
+	if err != nil {
+		return nil, &ImageAuthError{
+			Reason:  ReasonInvalidCABundle,
+			Message: fmt.Sprintf("registry CA secret %q does not hold a valid CA bundle", name),
+			Err:     err,
+		}
+	}
+	return bundle, nil
+}
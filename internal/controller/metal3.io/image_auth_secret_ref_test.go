@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDependentSecrets(t *testing.T) {
+	secretRef := func(name string) *string { return &name }
+	tests := []struct {
+		name        string
+		image       *metal3api.Image
+		annotations map[string]string
+		expected    []types.NamespacedName
+	}{
+		{name: "no image"},
+		{
+			name:  "OCI image without auth secret",
+			image: &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"},
+		},
+		{
+			name:  "non-OCI image with auth secret",
+			image: &metal3api.Image{URL: "http://example.com/image.qcow2", OCIAuthSecretName: secretRef("pull-secret")},
+		},
+		{
+			name:     "OCI image with auth secret",
+			image:    &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag", OCIAuthSecretName: secretRef("pull-secret")},
+			expected: []types.NamespacedName{{Namespace: "hosts", Name: "pull-secret"}},
+		},
+		{
+			name:     "auth secret in another namespace",
+			image:    &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag", OCIAuthSecretName: secretRef("shared/pull-secret")},
+			expected: []types.NamespacedName{{Namespace: "shared", Name: "pull-secret"}},
+		},
+		{
+			name:        "auth and CA secrets",
+			image:       &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag", OCIAuthSecretName: secretRef("pull-secret")},
+			annotations: map[string]string{ImageAuthCASecretAnnotation: "registry-ca"},
+			expected: []types.NamespacedName{
+				{Namespace: "hosts", Name: "pull-secret"},
+				{Namespace: "hosts", Name: "registry-ca"},
+			},
+		},
+		{
+			name:        "CA secret for a non-OCI image",
+			image:       &metal3api.Image{URL: "http://example.com/image.qcow2"},
+			annotations: map[string]string{ImageAuthCASecretAnnotation: "registry-ca"},
+		},
+		{
+			name:  "malformed reference",
+			image: &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag", OCIAuthSecretName: secretRef("shared/")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := &metal3api.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "host", Namespace: "hosts", Annotations: tt.annotations},
+				Spec:       metal3api.BareMetalHostSpec{Image: tt.image},
+			}
+			if got := DependentSecrets(host); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
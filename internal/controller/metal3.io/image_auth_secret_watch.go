@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// imageAuthSecretChanged filters secret updates down to those that can change
+// the outcome of image auth validation: a new type, such as when the secret
+// is recreated with the wrong type, or new data.
+func imageAuthSecretChanged(e event.UpdateEvent) bool {
+	oldSecret, oldOK := e.ObjectOld.(*corev1.Secret)
+	newSecret, newOK := e.ObjectNew.(*corev1.Secret)
+	if !oldOK || !newOK {
+		return true
+	}
+	return oldSecret.Type != newSecret.Type || !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+}
+
+// dependentSecretsIndexField indexes hosts by the secrets returned by
+// DependentSecrets, in "namespace/name" form, so that the hosts depending on
+// a secret are found without listing every host.
+const dependentSecretsIndexField = "metal3.io/dependent-secrets"
+
+// indexDependentSecrets is the indexer for dependentSecretsIndexField.
+func indexDependentSecrets(obj client.Object) []string {
+	host, ok := obj.(*metal3api.BareMetalHost)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, secret := range DependentSecrets(host) {
+		keys = append(keys, secret.String())
+	}
+	return keys
+}
+
+// hostsDependingOnSecret lists the hosts, in every namespace since auth
+// secrets may be referenced across namespaces, whose DependentSecrets include
+// the secret.
+func (r *BareMetalHostReconciler) hostsDependingOnSecret(ctx context.Context, secret types.NamespacedName) ([]metal3api.BareMetalHost, error) {
+	hosts := &metal3api.BareMetalHostList{}
+	if err := r.List(ctx, hosts, client.MatchingFields{dependentSecretsIndexField: secret.String()}); err != nil {
+		return nil, err
+	}
+	return hosts.Items, nil
+}
+
+// findBMHsForAuthSecret returns a request for every host whose image depends
+// on the secret, as its OCI auth secret or its registry CA secret. Hosts are
+// matched through DependentSecrets only, so other secrets a host references,
+// such as its BMC credentials or user data, never requeue it here.
+func (r *BareMetalHostReconciler) findBMHsForAuthSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	hosts, err := r.hostsDependingOnSecret(ctx, client.ObjectKeyFromObject(secret))
+	if err != nil {
+		r.Log.Error(err, "failed to list hosts referencing image auth secret",
+			"secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range hosts {
+		name := types.NamespacedName{Namespace: hosts[i].Namespace, Name: hosts[i].Name}
+		requests = append(requests, reconcile.Request{NamespacedName: name})
+	}
+	return requests
+}
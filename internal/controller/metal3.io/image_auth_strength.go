@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CredentialStrengthPolicy configures the optional check rejecting weak or
+// placeholder registry credentials.
+type CredentialStrengthPolicy struct {
+	// MinPasswordLength is the minimum accepted password length. Zero
+	// disables the length check.
+	MinPasswordLength int
+
+	// Blocklist lists passwords that are considered weak, such as
+	// "password" or "changeme". Entries are compared case-insensitively.
+	Blocklist []string
+
+	// Strict fails validation for weak credentials. Otherwise only a
+	// warning event is emitted.
+	Strict bool
+}
+
+// weakCredentialProblem returns why the base64-encoded "username:password"
+// credentials are considered weak, or an empty string if they pass the
+// policy. The returned description never includes the credentials.
+func (p *CredentialStrengthPolicy) weakCredentialProblem(credentials string) string {
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return ""
+	}
+	// A password equal to the username is only ever warned about, through
+	// secretutils.ExtractOptions.WarnUsernameAsPassword.
+	_, password, _ := strings.Cut(string(decoded), ":")
+
+	switch {
+	case slices.ContainsFunc(p.Blocklist, func(weak string) bool { return strings.EqualFold(weak, password) }):
+		return "the password is on the weak credential blocklist"
+	case len(password) < p.MinPasswordLength:
+		return fmt.Sprintf("the password is shorter than %d characters", p.MinPasswordLength)
+	default:
+		return ""
+	}
+}
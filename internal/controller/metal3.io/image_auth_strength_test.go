@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestWeakCredentialProblem(t *testing.T) {
+	policy := &CredentialStrengthPolicy{
+		MinPasswordLength: 8,
+		Blocklist:         []string{"letmein123", "changeme"},
+	}
+
+	tests := []struct {
+		name        string
+		username    string
+		password    string
+		expectWeak  bool
+		description string
+	}{
+		{name: "blocklisted", username: "user", password: "letmein123", expectWeak: true, description: "blocklist"},
+		{name: "blocklisted case-insensitively", username: "user", password: "ChangeMe", expectWeak: true, description: "blocklist"},
+		{name: "same as username", username: "robotaccount", password: "robotaccount"},
+		{name: "too short", username: "user", password: "s3cr3t", expectWeak: true, description: "shorter than 8"},
+		{name: "acceptable", username: "robot$ci", password: "Vq8nT2xLr9Zp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := policy.weakCredentialProblem(encodeTestCredentials(tt.username, tt.password))
+			if !tt.expectWeak {
+				if problem != "" {
+					t.Errorf("expected credentials to be accepted, got %q", problem)
+				}
+				return
+			}
+			if !strings.Contains(problem, tt.description) {
+				t.Errorf("expected problem to mention %q, got %q", tt.description, problem)
+			}
+			if strings.Contains(problem, tt.password) {
+				t.Errorf("problem %q must not reveal the password", problem)
+			}
+		})
+	}
+}
+
+func TestValidate_CredentialStrength(t *testing.T) {
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]interface{}{
+				"auth": encodeTestCredentials("user", "letmein123"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+
+	for _, strict := range []bool{false, true} {
+		c, bmh, _ := getFakeClientWithSecretAndBMH(
+			t,
+			corev1.SecretTypeDockerConfigJson,
+			map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+			"oci://registry.example.com/repo/image:tag",
+		)
+		recorder := record.NewFakeRecorder(10)
+		validator := NewImageAuthValidator(recorder)
+		validator.CredentialStrength = &CredentialStrengthPolicy{
+			Blocklist: []string{"letmein123"},
+			Strict:    strict,
+		}
+
+		result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+		if strict {
+			if err == nil {
+				t.Fatal("expected error with a strict policy")
+			}
+			if result.Reason != ReasonCredentialsWeak {
+				t.Errorf("expected reason %q, got %q", ReasonCredentialsWeak, result.Reason)
+			}
+		} else if err != nil {
+			t.Errorf("unexpected error without a strict policy: %v", err)
+		}
+
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, EventAuthWeakCredentials) {
+				t.Errorf("expected %s event, got %q", EventAuthWeakCredentials, event)
+			}
+			if strings.Contains(event, "letmein123") {
+				t.Errorf("event must not reveal the password: %q", event)
+			}
+		default:
+			t.Error("expected a weak credentials event")
+		}
+	}
+}
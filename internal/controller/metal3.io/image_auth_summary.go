@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// ImageAuthSummary is a compact view of the image auth state of a host, for
+// status aggregators and printers that should not interpret the
+// ImageAuthInUse condition themselves.
+type ImageAuthSummary struct {
+	// Valid is true when registry credentials are in use for the image.
+	Valid bool `json:"valid"`
+	// Reason is the reason of the ImageAuthInUse condition, or empty if the
+	// condition is not set.
+	Reason string `json:"reason,omitempty"`
+	// RegistryHost is the registry the credentials were last resolved for.
+	RegistryHost string `json:"registryHost,omitempty"`
+	// SecretName is the auth secret referenced by the host image.
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// SummarizeImageAuth derives an ImageAuthSummary from the conditions and
+// annotations the controller maintains on the host.
+func SummarizeImageAuth(host *metal3api.BareMetalHost) ImageAuthSummary {
+	summary := ImageAuthSummary{
+		RegistryHost: host.Annotations[ImageAuthRegistryAnnotation],
+	}
+	if img := host.Spec.Image; img != nil && img.OCIAuthSecretName != nil {
+		summary.SecretName = *img.OCIAuthSecretName
+	}
+
+	cond := conditions.Get(host, metal3api.ImageAuthInUseCondition)
+	if cond == nil {
+		return summary
+	}
+	summary.Reason = cond.Reason
+	summary.Valid = conditions.IsTrue(host, metal3api.ImageAuthInUseCondition) &&
+		cond.Reason == metal3api.ImageAuthCredentialsInjectedReason
+	return summary
+}
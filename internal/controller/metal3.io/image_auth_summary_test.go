@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestSummarizeImageAuth(t *testing.T) {
+	secretName := "oci-auth-secret"
+	testCases := []struct {
+		name      string
+		condition *metav1.Condition
+		registry  string
+		expected  ImageAuthSummary
+	}{
+		{
+			name: "credentials injected",
+			condition: &metav1.Condition{
+				Type:    metal3api.ImageAuthInUseCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  metal3api.ImageAuthCredentialsInjectedReason,
+				Message: "credentials from secret test-namespace/oci-auth-secret, key quay.io",
+			},
+			registry: "quay.io",
+			expected: ImageAuthSummary{
+				Valid:        true,
+				Reason:       metal3api.ImageAuthCredentialsInjectedReason,
+				RegistryHost: "quay.io",
+				SecretName:   secretName,
+			},
+		},
+		{
+			name: "validation failed",
+			condition: &metav1.Condition{
+				Type:    metal3api.ImageAuthInUseCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  string(ReasonSecretNotFound),
+				Message: "auth secret \"oci-auth-secret\" not found",
+			},
+			expected: ImageAuthSummary{
+				Reason:     string(ReasonSecretNotFound),
+				SecretName: secretName,
+			},
+		},
+		{
+			name: "no condition yet",
+			expected: ImageAuthSummary{
+				SecretName: secretName,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := newDefaultHost(t)
+			host.Spec.Image = &metal3api.Image{
+				URL:               "oci://quay.io/repo/image:tag",
+				OCIAuthSecretName: &secretName,
+			}
+			if tc.condition != nil {
+				conditions.Set(host, *tc.condition)
+			}
+			if tc.registry != "" {
+				host.Annotations = map[string]string{ImageAuthRegistryAnnotation: tc.registry}
+			}
+
+			assert.Equal(t, tc.expected, SummarizeImageAuth(host))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImageTagPolicy configures the optional check nudging operators towards
+// pinned image references, since an image pulled by the "latest" tag, or
+// without a tag, can change between provisionings.
+type ImageTagPolicy struct {
+	// Strict fails validation for unpinned images. Otherwise only a
+	// warning is reported.
+	Strict bool
+	// RequireDigest fails validation for any image referenced by tag
+	// rather than by digest, such as "@sha256:...", so that the image is
+	// immutable. It implies the unpinned image check.
+	RequireDigest bool
+}
+
+// unpinnedImageProblem returns why the OCI image URL is not pinned to a
+// specific tag or digest, or an empty string if it is.
+func unpinnedImageProblem(imageURL string) string {
+	repository, found := imageRepository(imageURL)
+	if !found {
+		return ""
+	}
+	if strings.Contains(repository, "@") {
+		return ""
+	}
+
+	lastSegment := repository[strings.LastIndex(repository, "/")+1:]
+	_, tag, hasTag := strings.Cut(lastSegment, ":")
+	switch {
+	case !hasTag || tag == "":
+		return fmt.Sprintf("image %s has no tag and defaults to \"latest\"; pin it to a specific tag or digest", imageURL)
+	case tag == "latest":
+		return fmt.Sprintf("image %s uses the \"latest\" tag; pin it to a specific tag or digest", imageURL)
+	default:
+		return ""
+	}
+}
+
+// undigestedImageProblem returns why the OCI image URL is not pinned to a
+// digest, or an empty string if it is.
+func undigestedImageProblem(imageURL string) string {
+	repository, found := imageRepository(imageURL)
+	if !found {
+		return ""
+	}
+	_, digest, hasDigest := strings.Cut(repository, "@")
+	if algorithm, encoded, ok := strings.Cut(digest, ":"); hasDigest && ok && algorithm != "" && encoded != "" {
+		return ""
+	}
+	return fmt.Sprintf("image %s is not referenced by digest; use the form oci://<registry>/<repository>@sha256:<digest>", imageURL)
+}
+
+// uppercaseRepositoryProblem returns why the repository path of the OCI
+// image URL will be rejected by the registry for containing uppercase
+// letters, or an empty string if it is lowercase. Tags may be mixed case.
+func uppercaseRepositoryProblem(imageURL string) string {
+	repository, found := imageRepository(imageURL)
+	if !found {
+		return ""
+	}
+	repository, _, _ = strings.Cut(repository, "@")
+	slash := strings.LastIndex(repository, "/")
+	name, _, _ := strings.Cut(repository[slash+1:], ":")
+	repository = repository[:slash+1] + name
+	if repository == strings.ToLower(repository) {
+		return ""
+	}
+	return fmt.Sprintf("image %s has uppercase letters in repository %q; registries require lowercase repository names "+
+		"and will reject the pull", imageURL, repository)
+}
+
+// imageRepository returns the part of the OCI image URL after the registry
+// host, or false if there is none.
+func imageRepository(imageURL string) (string, bool) {
+	reference := strings.TrimPrefix(imageURL, "oci://")
+	reference, _, _ = strings.Cut(reference, "?")
+	_, repository, found := strings.Cut(reference, "/")
+	return repository, found
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestUnpinnedImageProblem(t *testing.T) {
+	tests := []struct {
+		imageURL      string
+		expectProblem bool
+	}{
+		{imageURL: "oci://registry.example.com/repo/image:latest", expectProblem: true},
+		{imageURL: "oci://registry.example.com/repo/image", expectProblem: true},
+		{imageURL: "oci://registry.example.com:5000/repo/image", expectProblem: true},
+		{imageURL: "oci://registry.example.com/repo/image:9.4"},
+		{imageURL: "oci://registry.example.com:5000/repo/image:9.4"},
+		{imageURL: "oci://mirror.example.com/library/image:latest?ns=docker.io", expectProblem: true},
+		{imageURL: "oci://mirror.example.com/library/image:9.4?ns=docker.io"},
+		{imageURL: "oci://registry.example.com/repo/image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{imageURL: "oci://registry.example.com/repo/image:latest@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageURL, func(t *testing.T) {
+			problem := unpinnedImageProblem(tt.imageURL)
+			if (problem != "") != tt.expectProblem {
+				t.Errorf("expected problem %v, got %q", tt.expectProblem, problem)
+			}
+		})
+	}
+}
+
+func TestValidate_ImageTagPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		imageURL      string
+		strict        bool
+		expectErr     bool
+		expectWarning bool
+	}{
+		{name: "latest tag", imageURL: "oci://registry.example.com/repo/image:latest", expectWarning: true},
+		{name: "latest tag in strict mode", imageURL: "oci://registry.example.com/repo/image:latest", strict: true, expectErr: true},
+		{name: "explicit tag in strict mode", imageURL: "oci://registry.example.com/repo/image:9.4", strict: true},
+		{
+			name:     "digest in strict mode",
+			imageURL: "oci://registry.example.com/repo/image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			strict:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				tt.imageURL,
+			)
+			validator := NewImageAuthValidator(nil)
+			validator.TagPolicy = &ImageTagPolicy{Strict: tt.strict}
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if result.Reason != ReasonUnpinnedImage {
+					t.Errorf("expected reason %s, got %s", ReasonUnpinnedImage, result.Reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (len(result.Warnings) > 0) != tt.expectWarning {
+				t.Errorf("expected warning %v, got %v", tt.expectWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestValidate_ImageTagPolicyRequireDigest(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageURL  string
+		expectErr bool
+	}{
+		{name: "explicit tag", imageURL: "oci://registry.example.com/repo/image:9.4", expectErr: true},
+		{name: "latest tag", imageURL: "oci://registry.example.com/repo/image:latest", expectErr: true},
+		{name: "no tag", imageURL: "oci://registry.example.com/repo/image", expectErr: true},
+		{name: "empty digest", imageURL: "oci://registry.example.com/repo/image@sha256:", expectErr: true},
+		{
+			name:     "digest",
+			imageURL: "oci://registry.example.com/repo/image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{
+			name:     "tag and digest",
+			imageURL: "oci://registry.example.com:5000/repo/image:9.4@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}, "registry.example.com:5000": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				tt.imageURL,
+			)
+			validator := NewImageAuthValidator(nil)
+			validator.TagPolicy = &ImageTagPolicy{RequireDigest: true}
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if result.Reason != ReasonTagNotAllowed {
+					t.Errorf("expected reason %s, got %s", ReasonTagNotAllowed, result.Reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Warnings) > 0 {
+				t.Errorf("expected no warnings, got %v", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestUppercaseRepositoryProblem(t *testing.T) {
+	tests := []struct {
+		imageURL      string
+		expectProblem bool
+	}{
+		{imageURL: "oci://registry.example.com/Repo/image:9.4", expectProblem: true},
+		{imageURL: "oci://registry.example.com/repo/Image", expectProblem: true},
+		{imageURL: "oci://registry.example.com:5000/repo/IMAGE@sha256:0123456789abcdef", expectProblem: true},
+		{imageURL: "oci://mirror.example.com/Library/image:9.4?ns=docker.io", expectProblem: true},
+		{imageURL: "oci://registry.example.com/repo/image:9.4"},
+		{imageURL: "oci://registry.example.com/repo/image:V9.4-RC1"},
+		{imageURL: "oci://Registry.Example.com/repo/image:9.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageURL, func(t *testing.T) {
+			problem := uppercaseRepositoryProblem(tt.imageURL)
+			if (problem != "") != tt.expectProblem {
+				t.Errorf("expected problem %v, got %q", tt.expectProblem, problem)
+			}
+		})
+	}
+}
+
+func TestValidate_UppercaseRepositoryWarning(t *testing.T) {
+	tests := []struct {
+		name          string
+		imageURL      string
+		expectWarning bool
+	}{
+		{name: "uppercase repository", imageURL: "oci://registry.example.com/MyOrg/image:9.4", expectWarning: true},
+		{name: "uppercase image name", imageURL: "oci://registry.example.com/repo/Image:9.4", expectWarning: true},
+		{name: "lowercase repository", imageURL: "oci://registry.example.com/repo/image:9.4"},
+		{name: "uppercase tag", imageURL: "oci://registry.example.com/repo/image:RC1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				tt.imageURL,
+			)
+
+			result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (len(result.Warnings) > 0) != tt.expectWarning {
+				t.Errorf("expected warning %v, got %v", tt.expectWarning, result.Warnings)
+			}
+			if tt.expectWarning && !strings.Contains(result.Warnings[0], "lowercase") {
+				t.Errorf("expected the warning to ask for a lowercase repository, got %q", result.Warnings[0])
+			}
+		})
+	}
+}
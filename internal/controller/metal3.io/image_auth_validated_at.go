@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageAuthValidatedAtAnnotation is set by the controller on a host, when
+// enabled, to record in RFC 3339 format when its image auth credentials were
+// last validated and put in use.
+const ImageAuthValidatedAtAnnotation = "metal3.io/image-auth-validated-at"
+
+// newerSecretNote returns a note when the secret was created after the last
+// successful validation recorded on the host, which explains a host
+// recovering from a missing secret. It returns an empty string otherwise.
+func newerSecretNote(sec *corev1.Secret, bmh *metal3api.BareMetalHost) string {
+	value, ok := bmh.GetAnnotations()[ImageAuthValidatedAtAnnotation]
+	if !ok {
+		return ""
+	}
+	validatedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil || !sec.CreationTimestamp.After(validatedAt) {
+		return ""
+	}
+	return fmt.Sprintf("secret was created at %s, after the last successful validation at %s",
+		sec.CreationTimestamp.UTC().Format(time.RFC3339), validatedAt.Format(time.RFC3339))
+}
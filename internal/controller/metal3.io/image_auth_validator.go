@@ -2,25 +2,312 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 )
 
 const (
 	// Events.
-	EventAuthFormatUnsupported = "ImageAuthFormatUnsupported"
-	EventAuthParseError        = "ImageAuthParseError"
+	EventAuthFormatUnsupported    = "ImageAuthFormatUnsupported"
+	EventAuthParseError           = "ImageAuthParseError"
+	EventAuthSecretForbidden      = "ImageAuthSecretForbidden"
+	EventAuthSecretNotLinked      = "ImageAuthSecretNotLinked"
+	EventAuthRegistryNotAllowed   = "ImageAuthRegistryNotAllowed"
+	EventAuthSecretIsBMC          = "ImageAuthSecretIsBMCCredentials"
+	EventAuthRegistryUnreachable  = "ImageAuthRegistryUnreachable"
+	EventAuthRejected             = "ImageAuthRejected"
+	EventAuthRegistryChanged      = "ImageAuthRegistryChanged"
+	EventAuthWeakCredentials      = "ImageAuthWeakCredentials"
+	EventAuthMalformedConfig      = "ImageAuthMalformedConfig"
+	EventAuthRegistryUnresolvable = "ImageAuthRegistryUnresolvable"
+	EventAuthCredentialsStale     = "ImageAuthCredentialsStale"
+	EventAuthCredentialsInjected  = "ImageAuthCredentialsInjected"
+	EventAuthSecretRecreated      = "ImageAuthSecretRecreated"
+	EventAuthUnpinnedImage        = "ImageAuthUnpinnedImage"
+	EventAuthUppercaseRepository  = "ImageAuthUppercaseRepository"
+	EventAuthCredentialConflict   = "ImageAuthCredentialConflict"
 )
 
+// ImageAuthHostsAnnotation may be set on an image auth secret to list, comma
+// separated, the names of the hosts allowed to consume it.
+const ImageAuthHostsAnnotation = "metal3.io/image-auth-hosts"
+
+// SkipImageAuthValidationAnnotation, when set to "true" on a host, disables
+// image auth validation for that host. No credentials are passed on and no
+// events are emitted.
+const SkipImageAuthValidationAnnotation = "metal3.io/skip-image-auth-validation"
+
+// ImageAuthNotExpectedAnnotation, when set to "true" on a host without an
+// image auth secret, records that its OCI image is deliberately pulled without
+// authentication, such as from a public registry.
+const ImageAuthNotExpectedAnnotation = "metal3.io/image-auth-not-expected"
+
+// ImageAuthSecretManagedByAnnotation may be set on a host to name the
+// external controller, such as "external-secrets", that creates its image auth
+// secret. While that secret does not exist yet, validation reports it as
+// pending instead of missing.
+const ImageAuthSecretManagedByAnnotation = "metal3.io/image-auth-secret-managed-by"
+
+// ImageAuthRegistryAnnotation is set by the controller on a host to record the
+// registry its image auth credentials were last resolved for, so that a change
+// of registry behind the same secret can be detected.
+const ImageAuthRegistryAnnotation = "metal3.io/image-auth-registry"
+
+// ImageAuthSecretUIDAnnotation may be set on a host to pin the UID of its
+// image auth secret. When the secret found under the referenced name has
+// another UID, it was deleted and recreated, and an informational event is
+// emitted. The credentials of the new secret are still used.
+const ImageAuthSecretUIDAnnotation = "metal3.io/image-auth-secret-uid"
+
+// SecretLinkPolicy controls whether the image auth secret must be explicitly
+// linked to the host consuming it, either through an owner reference or
+// through ImageAuthHostsAnnotation.
+type SecretLinkPolicy string
+
+const (
+	// SecretLinkIgnore does not check the linkage.
+	SecretLinkIgnore SecretLinkPolicy = ""
+	// SecretLinkWarn emits a warning event when the secret is not linked.
+	SecretLinkWarn SecretLinkPolicy = "Warn"
+	// SecretLinkStrict fails validation when the secret is not linked.
+	SecretLinkStrict SecretLinkPolicy = "Strict"
+)
+
+// ImageAuthReason is a machine-readable reason explaining the outcome of
+// image authentication validation.
+type ImageAuthReason string
+
+const (
+	// ReasonNotRequired means the image does not need authentication or
+	// validation was skipped for the host.
+	ReasonNotRequired ImageAuthReason = "NotRequired"
+	// ReasonNotApplicable means the host image is not an OCI image, so
+	// registry authentication does not apply.
+	ReasonNotApplicable ImageAuthReason = "NotApplicable"
+	// ReasonNoAuthExpected means the host has no auth secret and is marked
+	// with ImageAuthNotExpectedAnnotation as pulling its image anonymously.
+	ReasonNoAuthExpected ImageAuthReason = "NoAuthExpected"
+	// ReasonNoImage means the host has no image block at all, so there is
+	// nothing to validate.
+	ReasonNoImage ImageAuthReason = "NoImage"
+	// ReasonEmptyURL means the host has an image block without a URL, so
+	// the registry to authenticate against cannot be determined.
+	ReasonEmptyURL ImageAuthReason = "EmptyURL"
+	// ReasonInvalidImageURL means the OCI image URL cannot be used to
+	// locate the registry, for example because its host is empty.
+	ReasonInvalidImageURL ImageAuthReason = "InvalidImageURL"
+	// ReasonValid means credentials for the image were found.
+	ReasonValid ImageAuthReason = "Valid"
+	// ReasonSecretNotFound means the referenced secret does not exist.
+	ReasonSecretNotFound ImageAuthReason = "SecretNotFound"
+	// ReasonSecretPending means the secret does not exist yet but is
+	// expected to be created shortly by an external controller.
+	ReasonSecretPending ImageAuthReason = "SecretPending"
+	// ReasonSecretForbidden means the operator is not allowed to read the
+	// referenced secret. This usually indicates missing RBAC rather than a
+	// transient API problem.
+	ReasonSecretForbidden ImageAuthReason = "SecretForbidden"
+	// ReasonInvalidSecretReference means the auth secret reference is
+	// malformed, or names another namespace while cross-namespace
+	// references are not enabled.
+	ReasonInvalidSecretReference ImageAuthReason = "InvalidSecretReference"
+	// ReasonSecretNotLinked means the secret is not linked to the host and
+	// the validator runs with SecretLinkStrict.
+	ReasonSecretNotLinked ImageAuthReason = "SecretNotLinked"
+	// ReasonSecretTypeUnsupported means the secret is not a Docker config
+	// secret.
+	ReasonSecretTypeUnsupported ImageAuthReason = "SecretTypeUnsupported"
+	// ReasonMissingDataKey means the secret has a Docker config type but
+	// does not hold the data key that type requires.
+	ReasonMissingDataKey ImageAuthReason = "MissingDataKey"
+	// ReasonEmptyAuthConfig means the secret holds a Docker config without
+	// any registry, such as "{}".
+	ReasonEmptyAuthConfig ImageAuthReason = "EmptyAuthConfig"
+	// ReasonNoCredentialsForRegistry means the secret has an entry for the
+	// image registry, but it holds no credentials, such as an entry with
+	// only an email field.
+	ReasonNoCredentialsForRegistry ImageAuthReason = "NoCredentialsForRegistry"
+	// ReasonAmbiguousRegistryEntry means the secret has several entries for
+	// the image registry, differing only by case, with different
+	// credentials.
+	ReasonAmbiguousRegistryEntry ImageAuthReason = "AmbiguousRegistryEntry"
+	// ReasonSecretNotDecrypted means the Docker config in the secret looks
+	// like ciphertext, most likely because a sealed or SOPS-encrypted
+	// secret has not been decrypted yet. This is usually transient.
+	ReasonSecretNotDecrypted ImageAuthReason = "SecretNotDecrypted"
+	// ReasonTruncatedData means the Docker config in the secret ends
+	// prematurely, most likely because it was read mid-update. This is
+	// usually transient.
+	ReasonTruncatedData ImageAuthReason = "TruncatedData"
+	// ReasonDeferred means the secret has no credentials for the image
+	// registry but names a credential helper for it, so the credentials are
+	// expected to be materialized later, for example by a sidecar. Only
+	// reported with DeferCredentialHelpers.
+	ReasonDeferred ImageAuthReason = "Deferred"
+	// ReasonSecretIsBMCCredentials means the secret looks like a BMC
+	// credentials secret, which suggests the wrong secret was referenced.
+	ReasonSecretIsBMCCredentials ImageAuthReason = "SecretIsBMCCredentials"
+	// ReasonCredentialsInvalid means no usable credentials for the image
+	// registry could be extracted from the secret.
+	ReasonCredentialsInvalid ImageAuthReason = "CredentialsInvalid"
+	// ReasonCredentialsWeak means the credentials failed the credential
+	// strength policy. Only reported when the policy is strict.
+	ReasonCredentialsWeak ImageAuthReason = "CredentialsWeak"
+	// ReasonRegistryNotAllowed means the image registry is not in the list
+	// of trusted registries.
+	ReasonRegistryNotAllowed ImageAuthReason = "RegistryNotAllowed"
+	// ReasonRegistryUnreachable means live validation could not contact the
+	// registry. This is usually transient.
+	ReasonRegistryUnreachable ImageAuthReason = "RegistryUnreachable"
+	// ReasonRegistryUnresolvable means the registry host name does not
+	// resolve in DNS, which usually points to a typo in the image URL.
+	// Only reported with a DNSCheck.
+	ReasonRegistryUnresolvable ImageAuthReason = "RegistryUnresolvable"
+	// ReasonAuthRejected means the registry refused the credentials during
+	// live validation. This persists until the credentials change.
+	ReasonAuthRejected ImageAuthReason = "AuthRejected"
+	// ReasonConfigStructureInvalid means the Docker config in the secret
+	// does not follow the expected schema. Only reported with
+	// StrictStructure.
+	ReasonConfigStructureInvalid ImageAuthReason = "ConfigStructureInvalid"
+	// ReasonUnpinnedImage means the image uses the "latest" tag or no tag
+	// while the image tag policy is strict.
+	ReasonUnpinnedImage ImageAuthReason = "UnpinnedImage"
+	// ReasonTagNotAllowed means the image is referenced by tag rather than
+	// by digest while the image tag policy requires digests.
+	ReasonTagNotAllowed ImageAuthReason = "TagNotAllowed"
+	// ReasonInvalidCABundle means the registry CA secret named by
+	// ImageAuthCASecretAnnotation is missing or does not hold a valid CA
+	// bundle.
+	ReasonInvalidCABundle ImageAuthReason = "InvalidCABundle"
+)
+
+// ImageAuthError is returned by the validator for failures that have a
+// well-known reason.
+type ImageAuthError struct {
+	Reason  ImageAuthReason
+	Message string
+	Err     error
+}
+
+func (e *ImageAuthError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *ImageAuthError) Unwrap() error {
+	return e.Err
+}
+
+// imageAuthReason returns the reason carried by err, if any.
+func imageAuthReason(err error) (ImageAuthReason, bool) {
+	var authErr *ImageAuthError
+	if errors.As(err, &authErr) {
+		return authErr.Reason, true
+	}
+	return "", false
+}
+
 // ImageAuthValidator validates image authentication secrets.
 type ImageAuthValidator struct {
 	recorder record.EventRecorder
+
+	// ExtractOptions tunes how credentials are located in the secret.
+	ExtractOptions secretutils.ExtractOptions
+
+	// SecretLinkPolicy controls how secrets that are not explicitly linked
+	// to the host are treated.
+	SecretLinkPolicy SecretLinkPolicy
+
+	// TrustedRegistries, when not empty, is the allowlist of registries
+	// images may be pulled from. See secretutils.RegistryMatches for the
+	// matching rules.
+	TrustedRegistries []string
+
+	// RegistryChecker, when set, enables live validation of the resolved
+	// credentials against the registry.
+	RegistryChecker RegistryChecker
+
+	// StrictStructure enables a schema check of the Docker config before
+	// credentials are extracted, rejecting unexpected keys and types.
+	StrictStructure bool
+
+	// CredentialStrength, when set, checks the resolved credentials against
+	// a policy rejecting weak or placeholder passwords.
+	CredentialStrength *CredentialStrengthPolicy
+
+	// TagPolicy, when set, checks that the image is pinned to a tag other
+	// than "latest" or to a digest.
+	TagPolicy *ImageTagPolicy
+
+	// DNSCheck, when set, checks that the registry host name resolves
+	// before the secret is read. It is a lighter alternative to live
+	// validation that does not need credentials.
+	DNSCheck *RegistryDNSCheck
+
+	// SecretMapping, when set, maps the image registry to an auth secret
+	// for hosts that do not name one themselves.
+	SecretMapping *ImageAuthSecretMapping
+
+	// MaxCredentialAge, when set, flags secrets whose
+	// ImageAuthRotatedAtAnnotation is older than this, prompting a rotation.
+	// Stale credentials are still used.
+	MaxCredentialAge time.Duration
+
+	// CollectAll reports every problem found instead of stopping at the
+	// first one, for callers such as webhooks that want to list them all in
+	// one pass. The trusted registry, image tag, BMC credentials lookalike,
+	// secret type, linkage, structure and credential strength checks are
+	// independent and are all run. Reading the secret and extracting
+	// credentials from it depend on earlier inputs, so their failures still
+	// end validation, and live validation is skipped when problems were
+	// found. The problems are listed in ImageAuthResult.Problems, the first
+	// one giving the result Reason.
+	CollectAll bool
+
+	// ReuseValidResult short-circuits validation for hosts whose status
+	// records a valid result, see recordImageAuthResult, for the same image
+	// URL, auth secret resourceVersion and other inputs, provided the host
+	// still reports the credentials in use. The secret is still read to get
+	// the credentials, but no other check is run.
+	ReuseValidResult bool
+
+	// revalidate validates hosts again even when ReuseValidResult would
+	// reuse their result, so that periodic re-validation reaches the
+	// registry.
+	revalidate bool
+
+	// CrossNamespaceSecrets lists the namespaces, besides the host
+	// namespace, that auth secret references of the form "namespace/name"
+	// may point to. References to any other namespace are rejected, so
+	// that a host cannot use, and get labelled, any secret of the cluster.
+	// Empty disables cross-namespace references.
+	CrossNamespaceSecrets []string
+
+	// DeferCredentialHelpers treats a registry missing from the secret as
+	// deferred rather than invalid when a credHelpers entry covers it.
+	DeferCredentialHelpers bool
+
+	// FreshSecretRead reads the auth secret from the API server instead of
+	// the cache, for a reconcile following a credential rotation that must
+	// not see the previous version. It is off by default to limit the load
+	// on the API server with many hosts.
+	FreshSecretRead bool
+
+	// OnResult, when set, is called with a copy of the result at the end of
+	// every evaluation, for custom side effects such as alerting. Changes
+	// the hook makes to its copy do not affect the outcome.
+	OnResult func(ctx context.Context, bmh *metal3api.BareMetalHost, result ImageAuthResult)
 }
 
 // NewImageAuthValidator creates a new ImageAuthValidator.
@@ -31,38 +318,584 @@ func NewImageAuthValidator(recorder record.EventRecorder) *ImageAuthValidator {
 // Validate validates the image authentication secret for the given BMH and
 // returns the base64-encoded credentials in the format expected by Ironic.
 func (v *ImageAuthValidator) Validate(ctx context.Context, bmh *metal3api.BareMetalHost, secretMgr secretutils.SecretManager) (string, error) {
-	img := bmh.Spec.Image
-	if img == nil || !img.IsOCI() || img.OCIAuthSecretName == nil || *img.OCIAuthSecretName == "" {
-		return "", nil
+	result, err := v.Evaluate(ctx, bmh, secretMgr)
+	if err != nil {
+		return "", err
+	}
+	return result.Credentials, nil
+}
+
+// Evaluate validates the image authentication secret for the given BMH and
+// returns a detailed result. The result is never nil; when an error is
+// returned its Reason explains the failure.
+func (v *ImageAuthValidator) Evaluate(ctx context.Context, bmh *metal3api.BareMetalHost, secretMgr secretutils.SecretManager) (*ImageAuthResult, error) {
+	return v.EvaluateImage(ctx, bmh, bmh.Spec.Image, secretMgr)
+}
+
+// EvaluateImage is like Evaluate for the given image of the host, which need
+// not be the one in its spec.
+func (v *ImageAuthValidator) EvaluateImage(ctx context.Context, bmh *metal3api.BareMetalHost, img *metal3api.Image, secretMgr secretutils.SecretManager) (*ImageAuthResult, error) {
+	result, err := v.evaluate(ctx, bmh, img, secretMgr)
+	if v.OnResult != nil {
+		v.OnResult(ctx, bmh, result.snapshot())
+	}
+	return result, err
+}
+
+func (v *ImageAuthValidator) evaluate(ctx context.Context, bmh *metal3api.BareMetalHost, img *metal3api.Image, secretMgr secretutils.SecretManager) (*ImageAuthResult, error) {
+	result := &ImageAuthResult{Reason: ReasonNotRequired}
+
+	switch {
+	case img == nil:
+		result.Reason = ReasonNoImage
+		result.Message = "host has no image set"
+		return result, nil
+	case img.URL == "":
+		result.Reason = ReasonEmptyURL
+		result.Message = "host image has an empty URL"
+		return result, nil
+	}
+	if !img.IsOCI() {
+		result.Reason = ReasonNotApplicable
+		return result, nil
+	}
+	// Match against the canonical form of the URL; an invalid URL is
+	// reported below.
+	imageURL := img.URL
+	if canonical, err := secretutils.CanonicalizeImageURL(img.URL); err == nil {
+		imageURL = canonical
+	}
+	var secretName string
+	if img.OCIAuthSecretName != nil {
+		secretName = *img.OCIAuthSecretName
+	}
+	if secretName == "" && noImageAuthExpected(bmh) {
+		result.Reason = ReasonNoAuthExpected
+		result.Message = "host image is pulled without authentication as intended"
+		return result, nil
+	}
+	if secretName == "" && v.SecretMapping != nil {
+		mapped, err := v.SecretMapping.secretNameFor(ctx, imageURL)
+		if err != nil {
+			return result, err
+		}
+		secretName = mapped
+		if mapped != "" {
+			result.UsedFallback = true
+			result.FallbackReason = fmt.Sprintf(
+				"spec.image.ociAuthSecretName is not set; using secret %q from the secret mapping", mapped)
+		}
+	}
+	if secretName == "" {
+		return result, nil
+	}
+	result.OCIRelevant = true
+	if skipImageAuthValidation(bmh) {
+		return result, nil
+	}
+	if _, err := secretutils.RegistryHostForImage(img.URL); errors.Is(err, secretutils.ErrEmptyRegistryHost) {
+		return result.fail(ReasonInvalidImageURL, &ImageAuthError{
+			Reason: ReasonInvalidImageURL,
+			Message: fmt.Sprintf("image URL %q has an empty registry host; use the form oci://<registry>/<repository>:<tag>",
+				img.URL),
+		})
 	}
-	secretName := *img.OCIAuthSecretName
+	if problem := uppercaseRepositoryProblem(imageURL); problem != "" {
+		v.warn(bmh, result, EventAuthUppercaseRepository, problem)
+	}
+	if len(v.TrustedRegistries) > 0 {
+		if err := v.checkTrustedRegistry(bmh, imageURL); err != nil {
+			if !v.CollectAll {
+				return result.fail(ReasonRegistryNotAllowed, err)
+			}
+			result.collect(ReasonRegistryNotAllowed, err)
+		}
+	}
+	if v.TagPolicy != nil && v.TagPolicy.RequireDigest {
+		if problem := undigestedImageProblem(imageURL); problem != "" {
+			if v.recorder != nil {
+				v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthUnpinnedImage, problem)
+			}
+			err := &ImageAuthError{Reason: ReasonTagNotAllowed, Message: problem}
+			if !v.CollectAll {
+				return result.fail(ReasonTagNotAllowed, err)
+			}
+			result.collect(ReasonTagNotAllowed, err)
+		}
+	} else if v.TagPolicy != nil {
+		if problem := unpinnedImageProblem(imageURL); problem != "" {
+			if !v.TagPolicy.Strict {
+				v.warn(bmh, result, EventAuthUnpinnedImage, problem)
+			} else {
+				if v.recorder != nil {
+					v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthUnpinnedImage, problem)
+				}
+				err := &ImageAuthError{Reason: ReasonUnpinnedImage, Message: problem}
+				if !v.CollectAll {
+					return result.fail(ReasonUnpinnedImage, err)
+				}
+				result.collect(ReasonUnpinnedImage, err)
+			}
+		}
+	}
+	if v.DNSCheck != nil {
+		if err := v.DNSCheck.check(ctx, imageURL); err != nil {
+			reason, _ := imageAuthReason(err)
+			if v.recorder != nil && reason == ReasonRegistryUnresolvable {
+				v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthRegistryUnresolvable, err.Error())
+			}
+			return v.fail(result, reason, err)
+		}
+	}
+	key, err := imageAuthSecretKey(bmh, secretName, v.CrossNamespaceSecrets)
+	if err != nil {
+		return v.fail(result, ReasonInvalidSecretReference, err)
+	}
+	secretName = key.Name
+	result.SecretName = key.Name
+	result.SecretNamespace = key.Namespace
 
-	key := types.NamespacedName{Namespace: bmh.Namespace, Name: secretName}
-	sec, err := secretMgr.ObtainSecret(ctx, key)
+	obtainSecret := secretMgr.ObtainSecret
+	if v.FreshSecretRead {
+		obtainSecret = secretMgr.ObtainFreshSecret
+	}
+	sec, err := obtainSecret(ctx, key)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
-			return "", fmt.Errorf("auth secret %q not found in namespace %q", secretName, bmh.Namespace)
+			if manager := bmh.GetAnnotations()[ImageAuthSecretManagedByAnnotation]; manager != "" {
+				return v.fail(result, ReasonSecretPending, &ImageAuthError{
+					Reason: ReasonSecretPending,
+					Message: fmt.Sprintf("auth secret %q in namespace %q is managed by %s and is expected to appear shortly",
+						secretName, key.Namespace, manager),
+				})
+			}
+			return v.fail(result, ReasonSecretNotFound,
+				fmt.Errorf("auth secret %q not found in namespace %q", secretName, key.Namespace))
 		}
-		return "", err
+		if k8serrors.IsForbidden(err) {
+			if v.recorder != nil {
+				v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthSecretForbidden,
+					"Access to secret %q is forbidden; check the operator RBAC permissions for secrets in namespace %q",
+					secretName, key.Namespace)
+			}
+			return v.fail(result, ReasonSecretForbidden, &ImageAuthError{
+				Reason: ReasonSecretForbidden,
+				Message: fmt.Sprintf("operator is not permitted to read auth secret %q in namespace %q; grant get/list/watch on secrets via RBAC",
+					secretName, key.Namespace),
+				Err: err,
+			})
+		}
+		return result, err
+	}
+
+	result.SecretResourceVersion = sec.ResourceVersion
+	if v.ReuseValidResult && !v.revalidate {
+		result.inputsHash = imageAuthInputsHash(ctx, bmh, secretMgr)
+		if recorded := v.recordedValidResult(bmh, sec, imageURL, result); recorded != nil {
+			return recorded, nil
+		}
+	}
+
+	if pinned := bmh.GetAnnotations()[ImageAuthSecretUIDAnnotation]; pinned != "" && pinned != string(sec.UID) {
+		result.SecretRecreated = true
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeNormal, EventAuthSecretRecreated,
+				"Secret %q has UID %s instead of the pinned %s; it was recreated, update the %s annotation if this is expected",
+				secretName, sec.UID, pinned, ImageAuthSecretUIDAnnotation)
+		}
+	}
+
+	result.NewerSecretNote = newerSecretNote(sec, bmh)
+
+	if looksLikeBMCSecret(sec) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthSecretIsBMC,
+				"Secret %q looks like BMC credentials rather than a registry auth secret; check spec.image.ociAuthSecretName", secretName)
+		}
+		err := fmt.Errorf("secret %q has type %q with username and password keys and looks like BMC credentials; "+
+			"spec.image.ociAuthSecretName must reference a %s secret", secretName, sec.Type, corev1.SecretTypeDockerConfigJson)
+		if !v.CollectAll {
+			return result.fail(ReasonSecretIsBMCCredentials, err)
+		}
+		result.collect(ReasonSecretIsBMCCredentials, err)
 	}
 
-	if sec.Type != corev1.SecretTypeDockerConfigJson && sec.Type != corev1.SecretTypeDockercfg {
+	if !v.supportedSecretType(sec.Type) {
 		if v.recorder != nil {
 			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthFormatUnsupported,
 				"Secret %q has unsupported type %q", secretName, sec.Type)
 		}
-		return "", fmt.Errorf("secret %q has unsupported type %q (expected %s or %s)",
+		err := fmt.Errorf("secret %q has unsupported type %q (expected %s or %s)",
 			secretName, sec.Type, corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg)
+		if !v.CollectAll {
+			return result.fail(ReasonSecretTypeUnsupported, err)
+		}
+		result.collect(ReasonSecretTypeUnsupported, err)
+	}
+
+	if v.SecretLinkPolicy != SecretLinkIgnore && !secretLinkedToHost(sec, bmh) {
+		msg := fmt.Sprintf("Secret %q is not linked to host %q by an owner reference or the %s annotation",
+			secretName, bmh.Name, ImageAuthHostsAnnotation)
+		if v.SecretLinkPolicy != SecretLinkStrict {
+			v.warn(bmh, result, EventAuthSecretNotLinked, msg)
+		} else if v.recorder != nil {
+			v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthSecretNotLinked, msg)
+		}
+		if v.SecretLinkPolicy == SecretLinkStrict {
+			err := &ImageAuthError{Reason: ReasonSecretNotLinked, Message: msg}
+			if !v.CollectAll {
+				return result.fail(ReasonSecretNotLinked, err)
+			}
+			result.collect(ReasonSecretNotLinked, err)
+		}
+	}
+
+	if v.StrictStructure {
+		if errs := secretutils.ValidateDockerConfigStructure(sec); len(errs) > 0 {
+			if v.recorder != nil {
+				v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+					"Secret %q does not follow the Docker config schema: %v", secretName, errs.ToAggregate())
+			}
+			err := fmt.Errorf("secret %q has an invalid docker config structure: %w", secretName, errs.ToAggregate())
+			if !v.CollectAll {
+				return result.fail(ReasonConfigStructureInvalid, err)
+			}
+			result.collect(ReasonConfigStructureInvalid, err)
+		}
 	}
 
-	credentials, err := secretutils.ExtractRegistryCredentials(sec, img.URL)
+	creds, err := secretutils.ResolveRegistryCredentials(sec, imageURL, v.ExtractOptions)
+	if mirrored, mirror, mirroredURL := v.resolveFromMirrors(sec, bmh, imageURL, err); mirrored != nil {
+		if len(v.TrustedRegistries) > 0 {
+			if trustErr := v.checkTrustedRegistry(bmh, mirroredURL); trustErr != nil {
+				if !v.CollectAll {
+					return result.fail(ReasonRegistryNotAllowed, trustErr)
+				}
+				result.collect(ReasonRegistryNotAllowed, trustErr)
+			}
+		}
+		creds, err = mirrored, nil
+		result.MirrorHost = mirror
+		result.UsedFallback = true
+		result.FallbackReason = fmt.Sprintf("secret %q has no credentials for the image registry; using those of mirror %q",
+			secretName, mirror)
+	}
+	if errors.Is(err, secretutils.ErrNotDecrypted) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q holds data that looks encrypted; it may not have been decrypted yet", secretName)
+		}
+		return v.fail(result, ReasonSecretNotDecrypted, &ImageAuthError{
+			Reason: ReasonSecretNotDecrypted,
+			Message: fmt.Sprintf("secret %q holds data that looks encrypted; check that its sealed-secrets or SOPS controller decrypted it",
+				secretName),
+			Err: err,
+		})
+	}
+	if errors.Is(err, secretutils.ErrTruncatedData) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q holds truncated docker config data; it may be in the middle of an update", secretName)
+		}
+		return v.fail(result, ReasonTruncatedData, &ImageAuthError{
+			Reason:  ReasonTruncatedData,
+			Message: fmt.Sprintf("secret %q holds truncated docker config data, it may be in the middle of an update", secretName),
+			Err:     err,
+		})
+	}
+	if errors.Is(err, secretutils.ErrMissingDataKey) {
+		expectedKey := corev1.DockerConfigJsonKey
+		if sec.Type == corev1.SecretTypeDockercfg {
+			expectedKey = corev1.DockerConfigKey
+		}
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q of type %q is missing the %s key", secretName, sec.Type, expectedKey)
+		}
+		return v.fail(result, ReasonMissingDataKey,
+			fmt.Errorf("secret %q of type %q is missing the %s key", secretName, sec.Type, expectedKey))
+	}
+	if errors.Is(err, secretutils.ErrEmptyAuthConfig) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q has no registries configured", secretName)
+		}
+		return v.fail(result, ReasonEmptyAuthConfig, &ImageAuthError{
+			Reason:  ReasonEmptyAuthConfig,
+			Message: fmt.Sprintf("secret %q has no registries configured; its docker config has no auths entries", secretName),
+			Err:     err,
+		})
+	}
+	if errors.Is(err, secretutils.ErrAmbiguousRegistryEntry) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q has conflicting entries for the image registry: %v", secretName, err)
+		}
+		return v.fail(result, ReasonAmbiguousRegistryEntry, &ImageAuthError{
+			Reason: ReasonAmbiguousRegistryEntry,
+			Message: fmt.Sprintf("secret %q has entries for the image registry differing only by case; normalize them: %v",
+				secretName, err),
+			Err: err,
+		})
+	}
+	if v.DeferCredentialHelpers {
+		if deferred := v.credentialHelperDeferral(sec, imageURL, err); deferred != nil {
+			return v.fail(result, ReasonDeferred, deferred)
+		}
+	}
+	if errors.Is(err, secretutils.ErrNoCredentials) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
+				"Secret %q has an entry without credentials for the image registry", secretName)
+		}
+		return v.fail(result, ReasonNoCredentialsForRegistry, &ImageAuthError{
+			Reason:  ReasonNoCredentialsForRegistry,
+			Message: fmt.Sprintf("secret %q has no credentials for the image registry: %v", secretName, err),
+			Err:     err,
+		})
+	}
 	if err != nil {
 		if v.recorder != nil {
 			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthParseError,
 				"Failed to extract credentials from secret %q: %v", secretName, err)
 		}
-		return "", fmt.Errorf("failed to extract credentials from secret %q: %w", secretName, err)
+		return v.fail(result, ReasonCredentialsInvalid,
+			fmt.Errorf("failed to extract credentials from secret %q: %w", secretName, err))
+	}
+
+	for _, warning := range creds.Warnings {
+		v.warn(bmh, result, EventAuthMalformedConfig, fmt.Sprintf("Secret %q: %s", secretName, warning))
+	}
+
+	if v.CredentialStrength != nil {
+		if problem := v.CredentialStrength.weakCredentialProblem(creds.Credentials); problem != "" {
+			msg := fmt.Sprintf("Secret %q holds weak credentials for registry %s: %s", secretName, creds.RegistryHost, problem)
+			if !v.CredentialStrength.Strict {
+				v.warn(bmh, result, EventAuthWeakCredentials, msg)
+			} else if v.recorder != nil {
+				v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthWeakCredentials, msg)
+			}
+			if v.CredentialStrength.Strict {
+				err := &ImageAuthError{Reason: ReasonCredentialsWeak, Message: msg}
+				if !v.CollectAll {
+					return result.fail(ReasonCredentialsWeak, err)
+				}
+				result.collect(ReasonCredentialsWeak, err)
+			}
+		}
+	}
+
+	if v.MaxCredentialAge > 0 {
+		if problem := staleCredentialProblem(sec, v.MaxCredentialAge, time.Now()); problem != "" {
+			result.StaleCredentials = true
+			v.warn(bmh, result, EventAuthCredentialsStale, fmt.Sprintf("Secret %q should be rotated: %s", secretName, problem))
+		}
+	}
+
+	if len(result.Problems) > 0 {
+		// Live validation is pointless with known problems.
+		return result.failCollected()
+	}
+
+	checker := v.RegistryChecker
+	if caSecretName := bmh.GetAnnotations()[ImageAuthCASecretAnnotation]; caSecretName != "" {
+		bundle, err := registryCABundle(ctx, bmh, caSecretName, secretMgr)
+		if err != nil {
+			return v.fail(result, ReasonInvalidCABundle, err)
+		}
+		if caChecker, ok := checker.(CABundleChecker); ok {
+			if checker, err = caChecker.WithCABundle(bundle); err != nil {
+				return v.fail(result, ReasonInvalidCABundle, &ImageAuthError{
+					Reason:  ReasonInvalidCABundle,
+					Message: fmt.Sprintf("cannot use the CA bundle of secret %q for live validation", caSecretName),
+					Err:     err,
+				})
+			}
+		}
+	}
+
+	if checker != nil {
+		var err error
+		if reporter, ok := checker.(RateLimitReporter); ok {
+			// An image URL naming no repository only loses the manifest
+			// rate limit note.
+			repository, reference, _ := secretutils.ImageRepository(imageURL)
+			result.RateLimitNote, err = reporter.CheckCredentialsWithRateLimit(ctx, creds.RegistryHost, repository, reference, creds.Credentials)
+		} else {
+			err = checker.CheckCredentials(ctx, creds.RegistryHost, creds.Credentials)
+		}
+		if err != nil {
+			return result.fail(v.classifyLiveValidationError(bmh, secretName, err))
+		}
+	}
+
+	if v.SecretMapping != nil && !result.UsedFallback {
+		v.warnMappedSecretConflict(ctx, bmh, result, sec, imageURL, secretMgr)
+	}
+
+	result.Reason = ReasonValid
+	result.RegistryHost = creds.RegistryHost
+	result.MatchedAuthKey = creds.MatchedAuthKey
+	result.CanonicalAuthKey = creds.CanonicalAuthKey
+	result.MatchedAuthConfig = creds.MatchedAuthConfig
+	result.Metadata = credentialMetadata(result, creds)
+	result.Credentials = creds.Credentials
+	result.CredentialFingerprint = credentialFingerprint(creds.Credentials)
+	if v.ReuseValidResult {
+		// Reading the CA secret for live validation may have updated it.
+		result.inputsHash = imageAuthInputsHash(ctx, bmh, secretMgr)
+	}
+	return result, nil
+}
+
+// fail ends validation with reason. In CollectAll mode, the problems
+// collected so far are reported along with it.
+func (v *ImageAuthValidator) fail(result *ImageAuthResult, reason ImageAuthReason, err error) (*ImageAuthResult, error) {
+	if v.CollectAll && len(result.Problems) > 0 {
+		result.collect(reason, err)
+		return result.failCollected()
+	}
+	return result.fail(reason, err)
+}
+
+// warn records a non-fatal advisory in the result Warnings and emits it as a
+// warning event.
+func (v *ImageAuthValidator) warn(bmh *metal3api.BareMetalHost, result *ImageAuthResult, eventReason, msg string) {
+	result.Warnings = append(result.Warnings, msg)
+	if v.recorder != nil {
+		v.recorder.Event(bmh, corev1.EventTypeWarning, eventReason, msg)
+	}
+}
+
+// classifyLiveValidationError maps a live validation failure to a reason,
+// emitting the matching event.
+func (v *ImageAuthValidator) classifyLiveValidationError(bmh *metal3api.BareMetalHost, secretName string, err error) (ImageAuthReason, error) {
+	var rejected *RegistryAuthRejectedError
+	if errors.As(err, &rejected) {
+		if v.recorder != nil {
+			v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthRejected,
+				"Registry %s rejected the credentials from secret %q", rejected.Host, secretName)
+		}
+		return ReasonAuthRejected, &ImageAuthError{Reason: ReasonAuthRejected, Message: "live validation failed", Err: err}
 	}
 
-	return credentials, nil
+	if v.recorder != nil {
+		v.recorder.Eventf(bmh, corev1.EventTypeWarning, EventAuthRegistryUnreachable,
+			"Could not validate credentials from secret %q: %v", secretName, err)
+	}
+	return ReasonRegistryUnreachable, &ImageAuthError{Reason: ReasonRegistryUnreachable, Message: "live validation failed", Err: err}
+}
+
+// credentialHelperDeferral returns a ReasonDeferred error when resolveErr
+// reports the registry missing from the secret while a credential helper is
+// configured for it, and nil otherwise.
+func (v *ImageAuthValidator) credentialHelperDeferral(sec *corev1.Secret, imageURL string, resolveErr error) error {
+	var notFound *secretutils.RegistryNotFoundError
+	if !errors.As(resolveErr, &notFound) {
+		return nil
+	}
+	helper, err := secretutils.CredentialHelperForImage(sec, imageURL)
+	if err != nil || helper == "" {
+		return nil
+	}
+	return &ImageAuthError{
+		Reason: ReasonDeferred,
+		Message: fmt.Sprintf("secret %q has no credentials for registry %s yet; they are expected from credential helper %q",
+			sec.Name, notFound.Host, helper),
+	}
+}
+
+// checkTrustedRegistry returns an error if the image is not pulled from one of
+// the trusted registries.
+func (v *ImageAuthValidator) checkTrustedRegistry(bmh *metal3api.BareMetalHost, imageURL string) error {
+	// Both the host the image is pulled from and the registry whose
+	// credentials are used, which differ for a pull through a mirror, must
+	// be trusted.
+	pullHost, err := secretutils.PullRegistryHost(imageURL, v.ExtractOptions)
+	if err != nil {
+		return &ImageAuthError{Reason: ReasonRegistryNotAllowed, Message: "cannot determine image registry", Err: err}
+	}
+	credentialHost, err := secretutils.ResolveRegistryHost(imageURL, v.ExtractOptions)
+	if err != nil {
+		return &ImageAuthError{Reason: ReasonRegistryNotAllowed, Message: "cannot determine image registry", Err: err}
+	}
+	for _, registryHost := range []string{pullHost, credentialHost} {
+		if v.isTrustedRegistry(registryHost) {
+			continue
+		}
+		msg := fmt.Sprintf("registry %q is not in the list of trusted registries", registryHost)
+		if v.recorder != nil {
+			v.recorder.Event(bmh, corev1.EventTypeWarning, EventAuthRegistryNotAllowed, msg)
+		}
+		return &ImageAuthError{Reason: ReasonRegistryNotAllowed, Message: msg}
+	}
+	return nil
+}
+
+func (v *ImageAuthValidator) isTrustedRegistry(registryHost string) bool {
+	for _, pattern := range v.TrustedRegistries {
+		if secretutils.RegistryMatches(registryHost, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedSecretType reports whether image auth secrets of the type are
+// accepted. The API server rejects Docker config secrets without their
+// standard key, so a secret re-exported under another key is Opaque; such
+// secrets are accepted when ExtractOptions.ScanAllKeys looks for the Docker
+// config in every key.
+func (v *ImageAuthValidator) supportedSecretType(secretType corev1.SecretType) bool {
+	switch secretType {
+	case corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg:
+		return true
+	case corev1.SecretTypeOpaque, "":
+		return v.ExtractOptions.ScanAllKeys
+	default:
+		return false
+	}
+}
+
+// looksLikeBMCSecret reports whether the secret has the shape of a BMC
+// credentials secret: an Opaque secret with username and password keys.
+func looksLikeBMCSecret(sec *corev1.Secret) bool {
+	if sec.Type != corev1.SecretTypeOpaque && sec.Type != "" {
+		return false
+	}
+	_, hasUsername := sec.Data["username"]
+	_, hasPassword := sec.Data["password"]
+	return hasUsername && hasPassword
+}
+
+// skipImageAuthValidation reports whether the host opted out of image auth
+// validation through SkipImageAuthValidationAnnotation.
+func skipImageAuthValidation(bmh *metal3api.BareMetalHost) bool {
+	return strings.EqualFold(bmh.GetAnnotations()[SkipImageAuthValidationAnnotation], "true")
+}
+
+// noImageAuthExpected reports whether the host is marked through
+// ImageAuthNotExpectedAnnotation as pulling its image without authentication.
+func noImageAuthExpected(bmh *metal3api.BareMetalHost) bool {
+	return strings.EqualFold(bmh.GetAnnotations()[ImageAuthNotExpectedAnnotation], "true")
+}
+
+// secretLinkedToHost reports whether the secret is owned by the host or lists
+// it in ImageAuthHostsAnnotation.
+func secretLinkedToHost(sec *corev1.Secret, bmh *metal3api.BareMetalHost) bool {
+	for _, ref := range sec.GetOwnerReferences() {
+		if bmh.UID != "" && ref.UID == bmh.UID {
+			return true
+		}
+		if ref.Kind == "BareMetalHost" && ref.Name == bmh.Name {
+			return true
+		}
+	}
+
+	for _, name := range strings.Split(sec.Annotations[ImageAuthHostsAnnotation], ",") {
+		if strings.TrimSpace(name) == bmh.Name {
+			return true
+		}
+	}
+	return false
 }
@@ -1,20 +1,28 @@
 package controllers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func testLogger(t *testing.T) logr.Logger {
@@ -55,6 +63,63 @@ func TestValidate_SecretNotFound(t *testing.T) {
 	}
 }
 
+func TestValidate_SecretForbidden(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = metal3api.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	forbidden := k8serrors.NewForbidden(corev1.Resource("secrets"), "my-secret", errors.New("RBAC denied"))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(_ context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+			return forbidden
+		},
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+	validator := NewImageAuthValidator(recorder)
+
+	secretName := "my-secret"
+	bmh := &metal3api.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-host",
+			Namespace: "default",
+		},
+		Spec: metal3api.BareMetalHostSpec{
+			Image: &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: &secretName,
+			},
+		},
+	}
+
+	credentials, err := validator.Validate(t.Context(), bmh, secretManager)
+	if err == nil {
+		t.Fatal("expected error when secret access is forbidden")
+	}
+	if credentials != "" {
+		t.Error("expected empty credentials when secret access is forbidden")
+	}
+	reason, ok := imageAuthReason(err)
+	if !ok || reason != ReasonSecretForbidden {
+		t.Errorf("expected reason %q, got %q", ReasonSecretForbidden, reason)
+	}
+	if !strings.Contains(err.Error(), "RBAC") {
+		t.Errorf("expected error message to mention RBAC, got: %v", err)
+	}
+	if !k8serrors.IsForbidden(err) {
+		t.Error("expected the original Forbidden error to be preserved")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "Warning "+EventAuthSecretForbidden) {
+			t.Errorf("expected Warning %s event, got: %q", EventAuthSecretForbidden, event)
+		}
+	default:
+		t.Error("expected warning event to be recorded")
+	}
+}
+
 func TestValidate_WrongSecretType(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = metal3api.AddToScheme(scheme)
@@ -68,8 +133,7 @@ func TestValidate_WrongSecretType(t *testing.T) {
 		},
 		Type: corev1.SecretTypeOpaque, // Wrong type
 		Data: map[string][]byte{
-			"username": []byte("user"),
-			"password": []byte("pass"),
+			"token": []byte("abc"),
 		},
 	}
 
@@ -111,6 +175,41 @@ func TestValidate_WrongSecretType(t *testing.T) {
 	}
 }
 
+func TestValidate_SecretLooksLikeBMCCredentials(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeOpaque,
+		map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("password"),
+		},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	recorder := record.NewFakeRecorder(10)
+	secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+	validator := NewImageAuthValidator(recorder)
+
+	result, err := validator.Evaluate(t.Context(), bmh, secretManager)
+	if err == nil {
+		t.Fatal("expected error for BMC credentials secret")
+	}
+	if result.Reason != ReasonSecretIsBMCCredentials {
+		t.Errorf("expected reason %q, got %q", ReasonSecretIsBMCCredentials, result.Reason)
+	}
+	if !strings.Contains(err.Error(), "looks like BMC credentials") {
+		t.Errorf("expected error to suggest a BMC secret mix-up, got: %v", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "Warning "+EventAuthSecretIsBMC) {
+			t.Errorf("expected Warning %s event, got %q", EventAuthSecretIsBMC, event)
+		}
+	default:
+		t.Error("expected warning event to be recorded")
+	}
+}
+
 func TestValidate_ValidDockerConfigJSON(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = metal3api.AddToScheme(scheme)
@@ -278,6 +377,56 @@ func TestValidate_NonOCIImageWithSecret(t *testing.T) {
 	if credentials != "" {
 		t.Error("expected credentials to be empty for non-OCI images")
 	}
+
+	result, err := validator.Evaluate(t.Context(), bmh, secretutils.SecretManager{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Reason != ReasonNotApplicable {
+		t.Errorf("expected reason %q, got %q", ReasonNotApplicable, result.Reason)
+	}
+}
+
+func TestValidate_NoImageAndEmptyURL(t *testing.T) {
+	secretName := "my-secret"
+	tests := []struct {
+		name           string
+		image          *metal3api.Image
+		expectedReason ImageAuthReason
+	}{
+		{
+			name:           "no image",
+			expectedReason: ReasonNoImage,
+		},
+		{
+			name:           "empty URL",
+			image:          &metal3api.Image{OCIAuthSecretName: &secretName},
+			expectedReason: ReasonEmptyURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bmh := &metal3api.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-host",
+					Namespace: "default",
+				},
+				Spec: metal3api.BareMetalHostSpec{Image: tt.image},
+			}
+
+			result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.SecretManager{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, result.Reason)
+			}
+			if result.Message == "" {
+				t.Error("expected a message explaining the reason")
+			}
+		})
+	}
 }
 
 func TestIsOCI(t *testing.T) {
@@ -384,3 +533,1229 @@ func TestIntegration_ValidateAndExtractCredentials(t *testing.T) {
 		t.Errorf("expected decoded credentials to be 'myuser:mypassword', got '%s'", string(decoded))
 	}
 }
+
+func TestValidate_SecretLinkPolicy(t *testing.T) {
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]interface{}{
+				"auth": base64.StdEncoding.EncodeToString([]byte("testuser:testpass")),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		policy      SecretLinkPolicy
+		annotations map[string]string
+		ownerRefs   []metav1.OwnerReference
+		expectError bool
+		expectEvent bool
+	}{
+		{
+			name:   "ignore policy with unlinked secret",
+			policy: SecretLinkIgnore,
+		},
+		{
+			name:        "warn policy with unlinked secret",
+			policy:      SecretLinkWarn,
+			expectEvent: true,
+		},
+		{
+			name:        "strict policy with unlinked secret",
+			policy:      SecretLinkStrict,
+			expectError: true,
+			expectEvent: true,
+		},
+		{
+			name:   "strict policy with owner reference",
+			policy: SecretLinkStrict,
+			ownerRefs: []metav1.OwnerReference{
+				{APIVersion: "metal3.io/v1alpha1", Kind: "BareMetalHost", Name: "test-host", UID: "host-uid"},
+			},
+		},
+		{
+			name:        "strict policy with annotation",
+			policy:      SecretLinkStrict,
+			annotations: map[string]string{ImageAuthHostsAnnotation: "other-host, test-host"},
+		},
+		{
+			name:        "strict policy with annotation for other hosts",
+			policy:      SecretLinkStrict,
+			annotations: map[string]string{ImageAuthHostsAnnotation: "other-host"},
+			expectError: true,
+			expectEvent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, secret := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			secret.Annotations = tt.annotations
+			secret.OwnerReferences = tt.ownerRefs
+			if err := c.Update(t.Context(), secret); err != nil {
+				t.Fatalf("failed to update secret: %v", err)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+			validator := NewImageAuthValidator(recorder)
+			validator.SecretLinkPolicy = tt.policy
+
+			credentials, err := validator.Validate(t.Context(), bmh, secretManager)
+			if tt.expectError {
+				reason, _ := imageAuthReason(err)
+				if reason != ReasonSecretNotLinked {
+					t.Errorf("expected reason %q, got %q (err: %v)", ReasonSecretNotLinked, reason, err)
+				}
+				if credentials != "" {
+					t.Error("expected empty credentials for unlinked secret")
+				}
+			} else if err != nil || credentials == "" {
+				t.Errorf("expected credentials, got %q (err: %v)", credentials, err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !tt.expectEvent {
+					t.Errorf("unexpected event emitted: %q", event)
+				} else if !strings.Contains(event, EventAuthSecretNotLinked) {
+					t.Errorf("expected %s event, got %q", EventAuthSecretNotLinked, event)
+				}
+			default:
+				if tt.expectEvent {
+					t.Error("expected warning event to be recorded")
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_SkipAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expectError bool
+	}{
+		{name: "skip enabled", value: "true"},
+		{name: "skip enabled mixed case", value: "True"},
+		{name: "skip disabled", value: "false", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The secret has the wrong type, so validation fails unless skipped.
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeOpaque,
+				map[string][]byte{"foo": []byte("bar")},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			bmh.Annotations = map[string]string{SkipImageAuthValidationAnnotation: tt.value}
+
+			recorder := record.NewFakeRecorder(10)
+			secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+			validator := NewImageAuthValidator(recorder)
+
+			credentials, err := validator.Validate(t.Context(), bmh, secretManager)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error when validation is not skipped")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if credentials != "" {
+				t.Error("expected no credentials when validation is skipped")
+			}
+			select {
+			case event := <-recorder.Events:
+				t.Errorf("unexpected event emitted: %q", event)
+			default:
+			}
+		})
+	}
+}
+
+func TestValidate_TrustedRegistries(t *testing.T) {
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]interface{}{
+				"auth": base64.StdEncoding.EncodeToString([]byte("testuser:testpass")),
+			},
+			"mirror.untrusted.org": map[string]interface{}{
+				"auth": base64.StdEncoding.EncodeToString([]byte("testuser:testpass")),
+			},
+			"docker.io": map[string]interface{}{
+				"auth": base64.StdEncoding.EncodeToString([]byte("hubuser:hubpass")),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		trusted     []string
+		mirrors     map[string][]string
+		imageURL    string
+		expectAllow bool
+	}{
+		{
+			name:        "no allowlist",
+			imageURL:    "oci://mirror.untrusted.org/repo/image:tag",
+			expectAllow: true,
+		},
+		{
+			name:        "exact match",
+			trusted:     []string{"quay.io", "registry.example.com"},
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			expectAllow: true,
+		},
+		{
+			name:        "subdomain match",
+			trusted:     []string{"*.example.com"},
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			expectAllow: true,
+		},
+		{
+			name:     "apex does not match subdomain pattern",
+			trusted:  []string{"*.untrusted.org.example.com"},
+			imageURL: "oci://mirror.untrusted.org/repo/image:tag",
+		},
+		{
+			name:     "registry not listed despite valid credentials",
+			trusted:  []string{"registry.example.com"},
+			imageURL: "oci://mirror.untrusted.org/repo/image:tag",
+		},
+		{
+			name:     "ns parameter does not bypass the allowlist",
+			trusted:  []string{"docker.io"},
+			imageURL: "oci://mirror.untrusted.org/library/ubuntu:22.04?ns=docker.io",
+		},
+		{
+			name:     "configured mirror not listed",
+			trusted:  []string{"docker.io"},
+			mirrors:  map[string][]string{"docker.io": {"mirror.untrusted.org"}},
+			imageURL: "oci://mirror.untrusted.org/library/ubuntu:22.04?ns=docker.io",
+		},
+		{
+			name:        "configured mirror and upstream listed",
+			trusted:     []string{"docker.io", "mirror.untrusted.org"},
+			mirrors:     map[string][]string{"docker.io": {"mirror.untrusted.org"}},
+			imageURL:    "oci://mirror.untrusted.org/library/ubuntu:22.04?ns=docker.io",
+			expectAllow: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+				tt.imageURL,
+			)
+			recorder := record.NewFakeRecorder(10)
+			secretManager := secretutils.NewSecretManager(testLogger(t), c, c)
+			validator := NewImageAuthValidator(recorder)
+			validator.TrustedRegistries = tt.trusted
+			validator.ExtractOptions.UpstreamMirrors = tt.mirrors
+
+			result, err := validator.Evaluate(t.Context(), bmh, secretManager)
+			if tt.expectAllow {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Reason != ReasonValid {
+					t.Errorf("expected reason %q, got %q", ReasonValid, result.Reason)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error for untrusted registry")
+			}
+			if result.Reason != ReasonRegistryNotAllowed {
+				t.Errorf("expected reason %q, got %q", ReasonRegistryNotAllowed, result.Reason)
+			}
+			if result.Credentials != "" {
+				t.Error("expected no credentials for untrusted registry")
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, EventAuthRegistryNotAllowed) {
+					t.Errorf("expected %s event, got %q", EventAuthRegistryNotAllowed, event)
+				}
+			default:
+				t.Error("expected warning event to be recorded")
+			}
+		})
+	}
+}
+
+func TestValidate_StrictStructure(t *testing.T) {
+	// Credentials can be extracted, but the config has an unexpected key.
+	data := []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}, "registries": {}}`)
+
+	for _, strict := range []bool{false, true} {
+		c, bmh, _ := getFakeClientWithSecretAndBMH(
+			t,
+			corev1.SecretTypeDockerConfigJson,
+			map[string][]byte{corev1.DockerConfigJsonKey: data},
+			"oci://registry.example.com/repo/image:tag",
+		)
+		validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+		validator.StrictStructure = strict
+
+		result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+		if !strict {
+			if err != nil {
+				t.Errorf("unexpected error without strict structure: %v", err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatal("expected error with strict structure")
+		}
+		if result.Reason != ReasonConfigStructureInvalid {
+			t.Errorf("expected reason %q, got %q", ReasonConfigStructureInvalid, result.Reason)
+		}
+		if !strings.Contains(err.Error(), "data[.dockerconfigjson].registries") {
+			t.Errorf("expected error to include the field path, got: %v", err)
+		}
+	}
+}
+
+func TestValidate_CredentialFingerprint(t *testing.T) {
+	evaluate := func(password string) *ImageAuthResult {
+		t.Helper()
+		dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+			"auths": map[string]interface{}{
+				"registry.example.com": map[string]interface{}{
+					"auth": base64.StdEncoding.EncodeToString([]byte("testuser:" + password)),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal docker config: %v", err)
+		}
+		c, bmh, _ := getFakeClientWithSecretAndBMH(
+			t,
+			corev1.SecretTypeDockerConfigJson,
+			map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+			"oci://registry.example.com/repo/image:tag",
+		)
+		result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	first := evaluate("testpass")
+	second := evaluate("testpass")
+	rotated := evaluate("newpass")
+
+	if first.CredentialFingerprint == "" {
+		t.Fatal("expected a credential fingerprint")
+	}
+	if first.CredentialFingerprint != second.CredentialFingerprint {
+		t.Error("expected identical credentials to have the same fingerprint")
+	}
+	if first.CredentialFingerprint == rotated.CredentialFingerprint {
+		t.Error("expected changed credentials to have a different fingerprint")
+	}
+}
+
+func TestValidate_MissingDataKey(t *testing.T) {
+	tests := []struct {
+		secretType  corev1.SecretType
+		expectedKey string
+	}{
+		{secretType: corev1.SecretTypeDockerConfigJson, expectedKey: corev1.DockerConfigJsonKey},
+		{secretType: corev1.SecretTypeDockercfg, expectedKey: corev1.DockerConfigKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.secretType), func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				tt.secretType,
+				map[string][]byte{"config.json": []byte(`{"auths": {}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+
+			result, err := NewImageAuthValidator(record.NewFakeRecorder(10)).Evaluate(
+				t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err == nil {
+				t.Fatal("expected error for a secret without its data key")
+			}
+			if result.Reason != ReasonMissingDataKey {
+				t.Errorf("expected reason %q, got %q", ReasonMissingDataKey, result.Reason)
+			}
+			if !strings.Contains(err.Error(), "missing the "+tt.expectedKey+" key") {
+				t.Errorf("expected error to name the %s key, got: %v", tt.expectedKey, err)
+			}
+		})
+	}
+}
+
+func TestValidate_StringAuthEntryWarning(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": "testuser:testpass"}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := NewImageAuthValidator(recorder).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Reason != ReasonValid {
+		t.Errorf("expected reason %q, got %q", ReasonValid, result.Reason)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventAuthMalformedConfig) {
+			t.Errorf("expected %s event, got %q", EventAuthMalformedConfig, event)
+		}
+	default:
+		t.Error("expected a warning event for the string auths entry")
+	}
+}
+
+func TestValidate_CredentialHelperDeferred(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		deferral       bool
+		expectedReason ImageAuthReason
+	}{
+		{
+			name:           "credHelper only, deferral enabled",
+			config:         `{"auths": {}, "credHelpers": {"registry.example.com": "ecr-login"}}`,
+			deferral:       true,
+			expectedReason: ReasonDeferred,
+		},
+		{
+			name:           "credHelper only, deferral disabled",
+			config:         `{"auths": {}, "credHelpers": {"registry.example.com": "ecr-login"}}`,
+			expectedReason: ReasonCredentialsInvalid,
+		},
+		{
+			name:           "credHelper for another registry",
+			config:         `{"auths": {}, "credHelpers": {"other.example.com": "ecr-login"}}`,
+			deferral:       true,
+			expectedReason: ReasonCredentialsInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(tt.config)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+
+			validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+			validator.DeferCredentialHelpers = tt.deferral
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err == nil {
+				t.Fatal("expected an error when the registry has no inline credentials")
+			}
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, result.Reason)
+			}
+			if reason, _ := imageAuthReason(err); tt.expectedReason == ReasonDeferred && reason != ReasonDeferred {
+				t.Errorf("expected error to carry reason %q, got %q", ReasonDeferred, reason)
+			}
+		})
+	}
+}
+
+func TestValidate_SecretMapping(t *testing.T) {
+	auths := []byte(`{"auths": {"registry.example.com": {"username": "mapped", "password": "mappedpass"}}}`)
+	explicitAuths := []byte(`{"auths": {"registry.example.com": {"username": "explicit", "password": "explicitpass"}}}`)
+
+	tests := []struct {
+		name           string
+		explicitSecret string
+		imageURL       string
+		expectedReason ImageAuthReason
+		expectedSecret string
+		expectFallback bool
+		expectConflict bool
+	}{
+		{
+			name:           "mapping hit",
+			imageURL:       "oci://registry.example.com/repo/image:tag",
+			expectedReason: ReasonValid,
+			expectedSecret: "mapped-secret",
+			expectFallback: true,
+		},
+		{
+			name:           "mapping hit through a pattern",
+			imageURL:       "oci://mirror.internal.example.org/repo/image:tag",
+			expectedReason: ReasonCredentialsInvalid,
+			expectedSecret: "mapped-secret",
+			expectFallback: true,
+		},
+		{
+			name:           "mapping miss",
+			imageURL:       "oci://other.example.com/repo/image:tag",
+			expectedReason: ReasonNotRequired,
+		},
+		{
+			name:           "explicit secret wins over the mapping",
+			explicitSecret: "explicit-secret",
+			imageURL:       "oci://registry.example.com/repo/image:tag",
+			expectedReason: ReasonValid,
+			expectedSecret: "explicit-secret",
+			expectConflict: true,
+		},
+		{
+			name:           "explicit secret is the mapped one",
+			explicitSecret: "mapped-secret",
+			imageURL:       "oci://registry.example.com/repo/image:tag",
+			expectedReason: ReasonValid,
+			expectedSecret: "mapped-secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = metal3api.AddToScheme(scheme)
+			_ = corev1.AddToScheme(scheme)
+
+			mapping := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "image-auth-mapping", Namespace: "metal3"},
+				Data: map[string]string{
+					"registry.example.com":   "mapped-secret",
+					"*.internal.example.org": "mapped-secret",
+				},
+			}
+			mapped := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mapped-secret", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: auths},
+			}
+			explicit := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "explicit-secret", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: explicitAuths},
+			}
+			bmh := &metal3api.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-host", Namespace: "default"},
+				Spec: metal3api.BareMetalHostSpec{
+					Image: &metal3api.Image{URL: tt.imageURL},
+				},
+			}
+			if tt.explicitSecret != "" {
+				bmh.Spec.Image.OCIAuthSecretName = &tt.explicitSecret
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mapping, mapped, explicit, bmh).Build()
+
+			validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+			validator.SecretMapping = &ImageAuthSecretMapping{
+				Reader:    c,
+				ConfigMap: types.NamespacedName{Namespace: "metal3", Name: "image-auth-mapping"},
+			}
+			result, _ := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q (%s)", tt.expectedReason, result.Reason, result.Message)
+			}
+			if result.SecretName != tt.expectedSecret {
+				t.Errorf("expected secret %q, got %q", tt.expectedSecret, result.SecretName)
+			}
+			if result.UsedFallback != tt.expectFallback {
+				t.Errorf("expected UsedFallback %v, got %v (%s)", tt.expectFallback, result.UsedFallback, result.FallbackReason)
+			}
+			if tt.expectFallback && !strings.Contains(result.FallbackReason, "secret mapping") {
+				t.Errorf("expected the fallback reason to name the secret mapping, got %q", result.FallbackReason)
+			}
+			conflict := slices.ContainsFunc(result.Warnings, func(warning string) bool {
+				return strings.Contains(warning, "default/explicit-secret") && strings.Contains(warning, "default/mapped-secret")
+			})
+			if conflict != tt.expectConflict {
+				t.Errorf("expected a conflict warning %v, got warnings %v", tt.expectConflict, result.Warnings)
+			}
+			if tt.expectedSecret != "mapped-secret" {
+				stored := &corev1.Secret{}
+				if err := c.Get(t.Context(), client.ObjectKeyFromObject(mapped), stored); err != nil {
+					t.Fatalf("failed to get mapped secret: %v", err)
+				}
+				if _, labelled := stored.Labels[secretutils.LabelEnvironmentName]; labelled {
+					t.Error("the mapped secret should not be claimed when the host does not use it")
+				}
+			}
+		})
+	}
+}
+
+// TestValidate_ScanAllKeysOpaqueSecret tests that an Opaque secret holding
+// a Docker config under a nonstandard key is only accepted when every key is
+// scanned.
+func TestValidate_ScanAllKeysOpaqueSecret(t *testing.T) {
+	for _, scanAllKeys := range []bool{false, true} {
+		t.Run(fmt.Sprintf("scan all keys %v", scanAllKeys), func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeOpaque,
+				map[string][]byte{"config.json": []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+
+			validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+			validator.ExtractOptions.ScanAllKeys = scanAllKeys
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if !scanAllKeys {
+				if result.Reason != ReasonSecretTypeUnsupported {
+					t.Errorf("expected reason %q, got %q (%v)", ReasonSecretTypeUnsupported, result.Reason, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Reason != ReasonValid {
+				t.Errorf("expected reason %q, got %q", ReasonValid, result.Reason)
+			}
+		})
+	}
+}
+
+// countingReader counts the Get calls made through it.
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+// TestImageAuthSecretMapping_CacheTTL tests that the mapping ConfigMap is
+// only read again once the cached read is older than CacheTTL.
+func TestImageAuthSecretMapping_CacheTTL(t *testing.T) {
+	mapping := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-auth-mapping", Namespace: "metal3"},
+		Data:       map[string]string{"registry.example.com": "mapped-secret"},
+	}
+	reader := &countingReader{Reader: fake.NewClientBuilder().WithObjects(mapping).Build()}
+
+	for _, tt := range []struct {
+		ttl          time.Duration
+		expectedGets int
+	}{
+		{ttl: 0, expectedGets: 3},
+		{ttl: time.Hour, expectedGets: 1},
+	} {
+		reader.gets = 0
+		m := &ImageAuthSecretMapping{
+			Reader:    reader,
+			ConfigMap: types.NamespacedName{Namespace: "metal3", Name: "image-auth-mapping"},
+			CacheTTL:  tt.ttl,
+		}
+		for range 3 {
+			name, err := m.secretNameFor(t.Context(), "oci://registry.example.com/repo/image:tag")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != "mapped-secret" {
+				t.Errorf("expected mapped-secret, got %q", name)
+			}
+		}
+		if reader.gets != tt.expectedGets {
+			t.Errorf("with a TTL of %v, expected %d reads, got %d", tt.ttl, tt.expectedGets, reader.gets)
+		}
+	}
+}
+
+func TestValidate_EmptyRegistryHost(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+		"oci:///repo/image:tag",
+	)
+
+	result, err := NewImageAuthValidator(record.NewFakeRecorder(10)).Evaluate(
+		t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err == nil {
+		t.Fatal("expected an error for an image URL without a host")
+	}
+	if result.Reason != ReasonInvalidImageURL {
+		t.Errorf("expected reason %q, got %q", ReasonInvalidImageURL, result.Reason)
+	}
+	if !strings.Contains(err.Error(), "empty registry host") {
+		t.Errorf("expected the error to point at the empty host, got: %v", err)
+	}
+}
+
+func TestValidate_CanonicalImageURL(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+		"OCI://Registry.Example.com:443/repo/image:tag/",
+	)
+
+	result, err := NewImageAuthValidator(record.NewFakeRecorder(10)).Evaluate(
+		t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RegistryHost != "registry.example.com" {
+		t.Errorf("expected registry host %q, got %q", "registry.example.com", result.RegistryHost)
+	}
+}
+
+func TestValidate_StaleCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotatedAt   string
+		expectStale bool
+	}{
+		{name: "recently rotated", rotatedAt: time.Now().Add(-24 * time.Hour).Format(time.RFC3339)},
+		{name: "over age", rotatedAt: time.Now().Add(-100 * 24 * time.Hour).Format(time.RFC3339), expectStale: true},
+		{name: "unparseable annotation", rotatedAt: "last tuesday", expectStale: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, secret := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			secret.Annotations = map[string]string{ImageAuthRotatedAtAnnotation: tt.rotatedAt}
+			if err := c.Update(t.Context(), secret); err != nil {
+				t.Fatalf("failed to annotate secret: %v", err)
+			}
+			recorder := record.NewFakeRecorder(10)
+
+			validator := NewImageAuthValidator(recorder)
+			validator.MaxCredentialAge = 90 * 24 * time.Hour
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err != nil {
+				t.Fatalf("stale credentials must still be used, got error: %v", err)
+			}
+			if result.Reason != ReasonValid {
+				t.Errorf("expected reason %q, got %q", ReasonValid, result.Reason)
+			}
+			if result.StaleCredentials != tt.expectStale {
+				t.Errorf("expected StaleCredentials %v, got %v", tt.expectStale, result.StaleCredentials)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !tt.expectStale || !strings.Contains(event, EventAuthCredentialsStale) {
+					t.Errorf("unexpected event %q", event)
+				}
+			default:
+				if tt.expectStale {
+					t.Errorf("expected a %s event", EventAuthCredentialsStale)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_CollectAll(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeOpaque,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"other.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	secretMgr := secretutils.NewSecretManager(testLogger(t), c, c)
+
+	t.Run("first problem only by default", func(t *testing.T) {
+		result, err := NewImageAuthValidator(record.NewFakeRecorder(10)).Evaluate(t.Context(), bmh, secretMgr)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if result.Reason != ReasonSecretTypeUnsupported {
+			t.Errorf("expected reason %q, got %q", ReasonSecretTypeUnsupported, result.Reason)
+		}
+		if len(result.Problems) != 0 {
+			t.Errorf("expected no collected problems, got %v", result.Problems)
+		}
+	})
+
+	t.Run("all problems with CollectAll", func(t *testing.T) {
+		validator := NewImageAuthValidator(record.NewFakeRecorder(10))
+		validator.CollectAll = true
+		validator.TrustedRegistries = []string{"quay.io"}
+
+		result, err := validator.Evaluate(t.Context(), bmh, secretMgr)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		var reasons []ImageAuthReason
+		for _, problem := range result.Problems {
+			reasons = append(reasons, problem.Reason)
+		}
+		expected := []ImageAuthReason{ReasonRegistryNotAllowed, ReasonSecretTypeUnsupported, ReasonCredentialsInvalid}
+		if !slices.Equal(reasons, expected) {
+			t.Errorf("expected problems %v, got %v", expected, reasons)
+		}
+		if result.Reason != ReasonRegistryNotAllowed {
+			t.Errorf("expected the first problem to give the reason, got %q", result.Reason)
+		}
+		for _, text := range []string{"not in the list of trusted registries", "unsupported type", "registry registry.example.com not found"} {
+			if !strings.Contains(err.Error(), text) {
+				t.Errorf("expected the error to report %q, got: %v", text, err)
+			}
+		}
+		if reason, _ := imageAuthReason(err); reason != ReasonRegistryNotAllowed {
+			t.Errorf("expected the error to carry reason %q, got %q", ReasonRegistryNotAllowed, reason)
+		}
+	})
+}
+
+func TestValidate_SecretRecreated(t *testing.T) {
+	tests := []struct {
+		name            string
+		pinnedUID       string
+		expectRecreated bool
+	}{
+		{name: "not pinned"},
+		{name: "pinned to the current secret", pinnedUID: "current-uid"},
+		{name: "secret recreated", pinnedUID: "original-uid", expectRecreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, secret := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			// Simulate the recreation: the secret under the same name has a
+			// new UID.
+			if err := c.Delete(t.Context(), secret); err != nil {
+				t.Fatalf("failed to delete secret: %v", err)
+			}
+			secret = secret.DeepCopy()
+			secret.ResourceVersion = ""
+			secret.UID = "current-uid"
+			if err := c.Create(t.Context(), secret); err != nil {
+				t.Fatalf("failed to recreate secret: %v", err)
+			}
+			if tt.pinnedUID != "" {
+				bmh.Annotations = map[string]string{ImageAuthSecretUIDAnnotation: tt.pinnedUID}
+			}
+			recorder := record.NewFakeRecorder(10)
+
+			result, err := NewImageAuthValidator(recorder).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err != nil {
+				t.Fatalf("a recreated secret must still be used, got error: %v", err)
+			}
+			if result.SecretRecreated != tt.expectRecreated {
+				t.Errorf("expected SecretRecreated %v, got %v", tt.expectRecreated, result.SecretRecreated)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !tt.expectRecreated || !strings.Contains(event, EventAuthSecretRecreated) || !strings.HasPrefix(event, corev1.EventTypeNormal) {
+					t.Errorf("unexpected event %q", event)
+				}
+			default:
+				if tt.expectRecreated {
+					t.Errorf("expected a %s event", EventAuthSecretRecreated)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_CrossNamespaceSecret(t *testing.T) {
+	tests := []struct {
+		name           string
+		secretRef      string
+		allow          []string
+		expectErr      bool
+		expectNS       string
+		expectReasonIs ImageAuthReason
+	}{
+		{name: "plain name", secretRef: "test-secret", expectNS: "default"},
+		{name: "own namespace", secretRef: "default/test-secret", expectNS: "default"},
+		{name: "cross-namespace disabled", secretRef: "shared/test-secret", expectErr: true, expectReasonIs: ReasonInvalidSecretReference},
+		{name: "cross-namespace enabled", secretRef: "shared/test-secret", allow: []string{"shared"}, expectNS: "shared"},
+		{name: "namespace not allowed", secretRef: "kube-system/test-secret", allow: []string{"shared"}, expectErr: true, expectReasonIs: ReasonInvalidSecretReference},
+		{name: "malformed reference", secretRef: "shared/", allow: []string{"shared"}, expectErr: true, expectReasonIs: ReasonInvalidSecretReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, secret := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			shared := secret.DeepCopy()
+			shared.Namespace = "shared"
+			shared.ResourceVersion = ""
+			if err := c.Create(t.Context(), shared); err != nil {
+				t.Fatalf("failed to create shared secret: %v", err)
+			}
+			bmh.Spec.Image.OCIAuthSecretName = &tt.secretRef
+
+			validator := NewImageAuthValidator(nil)
+			validator.CrossNamespaceSecrets = tt.allow
+			result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if result.Reason != tt.expectReasonIs {
+					t.Errorf("expected reason %s, got %s", tt.expectReasonIs, result.Reason)
+				}
+				if tt.allow == nil && !strings.Contains(err.Error(), "cross-namespace auth secrets are not enabled") {
+					t.Errorf("expected the error to explain cross-namespace references are disabled, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.SecretNamespace != tt.expectNS || result.SecretName != "test-secret" {
+				t.Errorf("expected secret %s/test-secret, got %s/%s", tt.expectNS, result.SecretNamespace, result.SecretName)
+			}
+		})
+	}
+}
+
+func TestValidate_NewerSecretNote(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		validatedAt string
+		expectNote  bool
+	}{
+		{name: "never validated"},
+		{name: "secret predates the last validation", validatedAt: "2026-05-01T13:00:00Z"},
+		{name: "secret created after the last validation", validatedAt: "2026-05-01T11:00:00Z", expectNote: true},
+		{name: "unparsable validation time", validatedAt: "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, secret := getFakeClientWithSecretAndBMH(
+				t,
+				corev1.SecretTypeDockerConfigJson,
+				map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+				"oci://registry.example.com/repo/image:tag",
+			)
+			// Simulate a secret that appeared at a known time.
+			if err := c.Delete(t.Context(), secret); err != nil {
+				t.Fatalf("failed to delete secret: %v", err)
+			}
+			secret = secret.DeepCopy()
+			secret.ResourceVersion = ""
+			secret.CreationTimestamp = metav1.NewTime(created)
+			if err := c.Create(t.Context(), secret); err != nil {
+				t.Fatalf("failed to recreate secret: %v", err)
+			}
+			if tt.validatedAt != "" {
+				bmh.Annotations = map[string]string{ImageAuthValidatedAtAnnotation: tt.validatedAt}
+			}
+
+			result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if err != nil {
+				t.Fatalf("the note is informational, got error: %v", err)
+			}
+			if (result.NewerSecretNote != "") != tt.expectNote {
+				t.Errorf("expected note %v, got %q", tt.expectNote, result.NewerSecretNote)
+			}
+			if tt.expectNote && !strings.Contains(result.NewerSecretNote, "2026-05-01T11:00:00Z") {
+				t.Errorf("expected the note to name the last validation time, got %q", result.NewerSecretNote)
+			}
+		})
+	}
+}
+
+func TestValidate_NoAuthExpected(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		expectedReason ImageAuthReason
+	}{
+		{name: "unmarked host", expectedReason: ReasonNotRequired},
+		{
+			name:           "host marked as not needing auth",
+			annotations:    map[string]string{ImageAuthNotExpectedAnnotation: "true"},
+			expectedReason: ReasonNoAuthExpected,
+		},
+		{
+			name:           "marker not set to true",
+			annotations:    map[string]string{ImageAuthNotExpectedAnnotation: "no"},
+			expectedReason: ReasonNotRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bmh := &metal3api.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-host", Namespace: "default", Annotations: tt.annotations},
+				Spec: metal3api.BareMetalHostSpec{
+					Image: &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag"},
+				},
+			}
+
+			result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.SecretManager{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, result.Reason)
+			}
+			if tt.expectedReason == ReasonNoAuthExpected && !strings.Contains(result.Message, "as intended") {
+				t.Errorf("expected a message confirming the intent, got %q", result.Message)
+			}
+		})
+	}
+}
+
+func TestValidate_WarningsAccumulate(t *testing.T) {
+	// A string auths entry is a tolerated legacy shape, and the password
+	// repeats the username.
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": "builder:builder"}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	recorder := record.NewFakeRecorder(10)
+	validator := NewImageAuthValidator(recorder)
+	validator.ExtractOptions.WarnUsernameAsPassword = true
+	validator.CredentialStrength = &CredentialStrengthPolicy{MinPasswordLength: 12}
+
+	result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err != nil {
+		t.Fatalf("advisories must not fail validation, got error: %v", err)
+	}
+	if result.Credentials == "" {
+		t.Fatal("expected credentials despite the advisories")
+	}
+
+	expected := []string{"same as the username", "is a string instead of an object", "weak credentials"}
+	if len(result.Warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), result.Warnings)
+	}
+	for i, fragment := range expected {
+		if !strings.Contains(result.Warnings[i], fragment) {
+			t.Errorf("expected warning %d to contain %q, got %q", i, fragment, result.Warnings[i])
+		}
+	}
+	if len(recorder.Events) != len(expected) {
+		t.Errorf("expected every warning to also be emitted as an event, got %d events", len(recorder.Events))
+	}
+}
+
+func TestValidate_MatchedAuthConfig(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"username": "testuser", "password": "testpass"}}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+	validator := NewImageAuthValidator(nil)
+	validator.ExtractOptions.IncludeMatchedAuthConfig = true
+
+	result, err := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MatchedAuthConfig == nil {
+		t.Fatal("expected the matched auth config")
+	}
+	if result.MatchedAuthConfig.Username != "testuser" || result.MatchedAuthConfig.Password != "REDACTED" {
+		t.Errorf("expected the username intact and the password redacted, got %+v", result.MatchedAuthConfig)
+	}
+}
+
+func TestValidate_EmptyAuthConfig(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+
+	result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err == nil {
+		t.Fatal("expected an error for a secret without registries")
+	}
+	if result.Reason != ReasonEmptyAuthConfig {
+		t.Errorf("expected reason %q, got %q", ReasonEmptyAuthConfig, result.Reason)
+	}
+	if !strings.Contains(err.Error(), "no registries configured") {
+		t.Errorf("expected the error to say no registries are configured, got %v", err)
+	}
+}
+
+func TestValidate_NoCredentialsForRegistry(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"email": "user@example.com"}}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+
+	result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err == nil {
+		t.Fatal("expected an error for an entry without credentials")
+	}
+	if result.Reason != ReasonNoCredentialsForRegistry {
+		t.Errorf("expected reason %q, got %q", ReasonNoCredentialsForRegistry, result.Reason)
+	}
+	if !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("expected the error to say the entry has no credentials, got %v", err)
+	}
+}
+
+func TestValidate_FreshSecretRead(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = metal3api.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	newSecret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-secret",
+				Namespace: "default",
+				Labels:    map[string]string{secretutils.LabelEnvironmentName: secretutils.LabelEnvironmentValue},
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+				`{"auths": {"registry.example.com": {"username": "testuser", "password": "` + password + `"}}}`)},
+		}
+	}
+	secretName := "test-secret"
+	bmh := &metal3api.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-host", Namespace: "default"},
+		Spec: metal3api.BareMetalHostSpec{
+			Image: &metal3api.Image{URL: "oci://registry.example.com/repo/image:tag", OCIAuthSecretName: &secretName},
+		},
+	}
+	// The cache still holds the secret from before the rotation
+	cacheClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newSecret("oldpass"), bmh).Build()
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newSecret("newpass")).Build()
+	secretMgr := secretutils.NewSecretManager(testLogger(t), cacheClient, apiReader)
+
+	tests := []struct {
+		name           string
+		freshRead      bool
+		expectPassword string
+	}{
+		{name: "cached read by default", expectPassword: "oldpass"},
+		{name: "fresh read when requested", freshRead: true, expectPassword: "newpass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewImageAuthValidator(nil)
+			validator.FreshSecretRead = tt.freshRead
+
+			credentials, err := validator.Validate(t.Context(), bmh, secretMgr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(credentials)
+			if expected := "testuser:" + tt.expectPassword; string(decoded) != expected {
+				t.Errorf("expected credentials %q, got %q", expected, decoded)
+			}
+		})
+	}
+}
+
+func TestValidate_AmbiguousRegistryEntry(t *testing.T) {
+	c, bmh, _ := getFakeClientWithSecretAndBMH(
+		t,
+		corev1.SecretTypeDockerConfigJson,
+		map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {
+			"Registry.example.com": {"username": "olduser", "password": "oldpass"},
+			"registry.example.com": {"username": "testuser", "password": "testpass"}
+		}}`)},
+		"oci://registry.example.com/repo/image:tag",
+	)
+
+	result, err := NewImageAuthValidator(nil).Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+	if err == nil {
+		t.Fatal("expected an error for case-variant duplicate entries")
+	}
+	if result.Reason != ReasonAmbiguousRegistryEntry {
+		t.Errorf("expected reason %q, got %q", ReasonAmbiguousRegistryEntry, result.Reason)
+	}
+}
+
+func TestValidate_OnResultHook(t *testing.T) {
+	validAuths := map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)}
+	tests := []struct {
+		name           string
+		imageURL       string
+		secretName     string
+		expectedReason ImageAuthReason
+	}{
+		{name: "valid", imageURL: "oci://registry.example.com/repo/image:tag", secretName: "test-secret", expectedReason: ReasonValid},
+		{name: "not applicable", imageURL: "http://example.com/image.qcow2", secretName: "test-secret", expectedReason: ReasonNotApplicable},
+		{name: "secret not found", imageURL: "oci://registry.example.com/repo/image:tag", secretName: "missing", expectedReason: ReasonSecretNotFound},
+		{name: "registry not in secret", imageURL: "oci://other.example.com/repo/image:tag", secretName: "test-secret", expectedReason: ReasonCredentialsInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, bmh, _ := getFakeClientWithSecretAndBMH(t, corev1.SecretTypeDockerConfigJson, validAuths, tt.imageURL)
+			bmh.Spec.Image.OCIAuthSecretName = &tt.secretName
+
+			var calls []ImageAuthResult
+			validator := NewImageAuthValidator(nil)
+			validator.OnResult = func(_ context.Context, hookHost *metal3api.BareMetalHost, result ImageAuthResult) {
+				if hookHost != bmh {
+					t.Error("expected the hook to receive the validated host")
+				}
+				calls = append(calls, result)
+				// Changes to the copy must not leak into the outcome.
+				result.Reason = ReasonRegistryNotAllowed
+				result.Warnings = append(result.Warnings[:0], "injected")
+			}
+
+			result, _ := validator.Evaluate(t.Context(), bmh, secretutils.NewSecretManager(testLogger(t), c, c))
+			if len(calls) != 1 {
+				t.Fatalf("expected the hook to be called once, got %d calls", len(calls))
+			}
+			if calls[0].Reason != tt.expectedReason {
+				t.Errorf("expected the hook to see reason %q, got %q", tt.expectedReason, calls[0].Reason)
+			}
+			if result.Reason != tt.expectedReason {
+				t.Errorf("expected the hook not to change the reason %q, got %q", tt.expectedReason, result.Reason)
+			}
+			if slices.Contains(result.Warnings, "injected") {
+				t.Errorf("expected the hook not to change the warnings, got %v", result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImpactOfSecretDeletion returns, sorted, the hosts that would break if the
+// secret were deleted: those using it as their BMC credentials or depending
+// on it for their image, as reported by DependentSecrets. Hosts in every
+// namespace are considered, since image auth secrets may be referenced
+// across namespaces. It is read-only, for tooling to warn before a secret is
+// deleted.
+func (r *BareMetalHostReconciler) ImpactOfSecretDeletion(ctx context.Context, namespace, name string) ([]types.NamespacedName, error) {
+	secret := types.NamespacedName{Namespace: namespace, Name: name}
+	dependent, err := r.hostsDependingOnSecret(ctx, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+	// BMC credentials are always in the host namespace.
+	hosts := &metal3api.BareMetalHostList{}
+	if err := r.List(ctx, hosts, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+
+	var impacted []types.NamespacedName
+	for i := range dependent {
+		impacted = append(impacted, types.NamespacedName{Namespace: dependent[i].Namespace, Name: dependent[i].Name})
+	}
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		hostName := types.NamespacedName{Namespace: host.Namespace, Name: host.Name}
+		if host.Spec.BMC.CredentialsName != name || slices.Contains(impacted, hostName) {
+			continue
+		}
+		impacted = append(impacted, hostName)
+	}
+	slices.SortFunc(impacted, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return impacted, nil
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestImpactOfSecretDeletion(t *testing.T) {
+	sharedSecret := "shared-pull-secret"
+	otherSecret := "other-pull-secret"
+	crossNamespaceSecret := namespace + "/" + sharedSecret
+	ociHost := func(name string, secretName *string) *metal3api.BareMetalHost {
+		return newHost(name, &metal3api.BareMetalHostSpec{
+			BMC: metal3api.BMCDetails{CredentialsName: name + "-bmc-secret"},
+			Image: &metal3api.Image{
+				URL:               "oci://registry.example.com/repo/image:tag",
+				OCIAuthSecretName: secretName,
+			},
+		})
+	}
+
+	first := ociHost("first", &sharedSecret)
+	second := ociHost("second", &sharedSecret)
+	unrelated := ociHost("unrelated", &otherSecret)
+	anonymous := ociHost("anonymous", nil)
+	elsewhere := ociHost("elsewhere", &crossNamespaceSecret)
+	elsewhere.Namespace = "other-namespace"
+	nonOCI := newHost("non-oci", &metal3api.BareMetalHostSpec{
+		Image: &metal3api.Image{URL: "http://example.com/image.qcow2", OCIAuthSecretName: &sharedSecret},
+	})
+
+	r := newTestReconciler(t, first, second, unrelated, anonymous, elsewhere, nonOCI)
+
+	impacted, err := r.ImpactOfSecretDeletion(t.Context(), namespace, sharedSecret)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.NamespacedName{
+		{Namespace: namespace, Name: "first"},
+		{Namespace: namespace, Name: "second"},
+		{Namespace: "other-namespace", Name: "elsewhere"},
+	}, impacted)
+
+	impacted, err = r.ImpactOfSecretDeletion(t.Context(), namespace, "first-bmc-secret")
+	require.NoError(t, err)
+	assert.Equal(t, []types.NamespacedName{{Namespace: namespace, Name: "first"}}, impacted)
+
+	impacted, err = r.ImpactOfSecretDeletion(t.Context(), namespace, "unused-secret")
+	require.NoError(t, err)
+	assert.Empty(t, impacted)
+}
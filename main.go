@@ -38,6 +38,7 @@ import (
 	ironicv1alpha1 "github.com/metal3-io/ironic-standalone-operator/api/v1alpha1"
 	"go.uber.org/zap/zapcore"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -63,12 +64,44 @@ type TLSOptions struct {
 	TLSCipherSuites string
 }
 
+// ImageAuthOptions holds the flags tuning how image auth secrets are read
+// and which policies their credentials are checked against.
+type ImageAuthOptions struct {
+	ScanAllKeys              bool
+	DockerHubCanonicalHost   string
+	PathPrefixMatching       bool
+	DebugMatching            bool
+	TokenRegistries          string
+	UpstreamMirrors          string
+	DefaultRegistry          string
+	WarnUsernameAsPassword   bool
+	StripRegistryHostPrefix  string
+	YAMLFallback             bool
+	IncludeMatchedAuthConfig bool
+	CredentialFormat         string
+	PreferAuthField          bool
+	SecretLinkPolicy         string
+	StrictStructure          bool
+	MinPasswordLength        int
+	PasswordBlocklist        string
+	StrictCredentialStrength bool
+	TagPolicy                string
+}
+
+// Values of the image auth tag policy flag.
+const (
+	ImageAuthTagPolicyWarn          = "Warn"
+	ImageAuthTagPolicyStrict        = "Strict"
+	ImageAuthTagPolicyRequireDigest = "RequireDigest"
+)
+
 var (
 	scheme               = k8sruntime.NewScheme()
 	setupLog             = ctrl.Log.WithName("setup")
 	healthAddr           string
 	tlsOptions           = TLSOptions{}
 	tlsSupportedVersions = []string{TLSVersion12, TLSVersion13}
+	imageAuthOptions     = ImageAuthOptions{}
 )
 
 const leaderElectionID = "baremetal-operator"
@@ -145,6 +178,21 @@ func main() {
 	var leaseDurationSeconds string
 	var renewDeadlineSeconds string
 	var retryPeriodSeconds string
+	var imageAuthSecretMapping string
+	var imageAuthWatchSecrets bool
+	var imageAuthLiveValidation bool
+	var imageAuthRevalidationInterval time.Duration
+	var imageAuthEventsPerHostPerMinute int
+	var imageAuthTrustedRegistries string
+	var imageAuthCrossNamespaceSecrets string
+	var imageAuthReportNotApplicable bool
+	var imageAuthDeferCredentialHelpers bool
+	var imageAuthDNSCheck bool
+	var imageAuthMaxCredentialAge time.Duration
+	var imageAuthInjectionEvents bool
+	var imageAuthRecordValidatedAt bool
+	var imageAuthFreshSecretRead bool
+	var imageAuthReuseValidResult bool
 
 	// From CAPI point of view, BMO should be able to watch all namespaces
 	// in case of a deployment that is not multi-tenant. If the deployment
@@ -193,6 +241,82 @@ func main() {
 	flag.StringVar(&renewDeadlineSeconds, "renew-deadline-seconds", os.Getenv("RENEW_DEADLINE_SECONDS"), "Leader election renew deadline duration in seconds.")
 	flag.StringVar(&retryPeriodSeconds, "retry-period-seconds", os.Getenv("RETRY_PERIOD_SECONDS"), "Leader election retry period in seconds.")
 
+	flag.StringVar(&imageAuthSecretMapping, "image-auth-secret-mapping", "",
+		"ConfigMap, as <namespace>/<name>, mapping registry hosts to the image auth secret of hosts whose image does not set one.")
+	flag.BoolVar(&imageAuthWatchSecrets, "image-auth-watch-secrets", false,
+		"Validate the image auth credentials of hosts again when the type or data of their image auth secret changes.")
+	flag.BoolVar(&imageAuthLiveValidation, "image-auth-live-validation", false,
+		"Check image auth credentials against the registry before provisioning.")
+	flag.DurationVar(&imageAuthRevalidationInterval, "image-auth-revalidation-interval", 0,
+		"Interval at which the image auth credentials of provisioned hosts are checked against the registry again. "+
+			"Requires --image-auth-live-validation. Zero disables re-validation.")
+	flag.IntVar(&imageAuthEventsPerHostPerMinute, "image-auth-events-per-host-per-minute", 0,
+		"Maximum number of image auth events emitted for a single host per minute. "+
+			"Zero uses the default of 5, a negative value disables the limit.")
+	flag.StringVar(&imageAuthTrustedRegistries, "image-auth-trusted-registries", "",
+		"Comma-separated list of registries host images may be pulled from with credentials, such as quay.io or *.example.com. "+
+			"Hosts whose image is pulled from another registry are not provisioned. Empty allows any registry.")
+	flag.StringVar(&imageAuthCrossNamespaceSecrets, "image-auth-cross-namespace-secrets", "",
+		"Comma-separated list of namespaces hosts may reference an OCI auth secret in, as <namespace>/<name>, besides their own. "+
+			"Empty disables cross-namespace references.")
+	flag.BoolVar(&imageAuthReportNotApplicable, "image-auth-report-not-applicable", false,
+		"Set the ImageAuthInUse condition on hosts that do not use registry credentials too, with the reason why.")
+	flag.BoolVar(&imageAuthDeferCredentialHelpers, "image-auth-defer-credential-helpers", false,
+		"Wait for credentials, instead of failing the host, when the image auth secret only names a credential helper for the image registry.")
+	flag.BoolVar(&imageAuthDNSCheck, "image-auth-dns-check", false,
+		"Check that the image registry host name resolves before the image auth secret is validated.")
+	flag.DurationVar(&imageAuthMaxCredentialAge, "image-auth-max-credential-age", 0,
+		"Maximum age of image auth credentials, from the rotation annotation of their secret, before the host notes they should be rotated. "+
+			"Zero disables the check.")
+	flag.BoolVar(&imageAuthInjectionEvents, "image-auth-injection-events", false,
+		"Emit an event naming the secret resourceVersion whenever credentials from a new version of the image auth secret are injected.")
+	flag.BoolVar(&imageAuthRecordValidatedAt, "image-auth-record-validated-at", false,
+		"Record when image auth credentials are put in use on a host, to note image auth secrets created since then.")
+	flag.BoolVar(&imageAuthFreshSecretRead, "image-auth-fresh-secret-read", false,
+		"Read image auth secrets from the API server instead of the cache, trading load for freshness after rotations.")
+	flag.BoolVar(&imageAuthReuseValidResult, "image-auth-reuse-valid-result", false,
+		"Record valid image auth results in the host status and skip validating them again while the image, secret and image auth annotations are unchanged.")
+	flag.BoolVar(&imageAuthOptions.ScanAllKeys, "image-auth-scan-all-keys", false,
+		"Look for a Docker config under every key of image auth secrets, Opaque ones included, when the standard keys are missing.")
+	flag.StringVar(&imageAuthOptions.DockerHubCanonicalHost, "image-auth-docker-hub-canonical-host", "",
+		"Name Docker Hub credentials are reported under, whichever alias they are stored under. Defaults to docker.io.")
+	flag.BoolVar(&imageAuthOptions.PathPrefixMatching, "image-auth-path-prefix-matching", false,
+		"Select credentials stored under host/prefix keys by the first path segment of the image, for registries behind a shared host.")
+	flag.BoolVar(&imageAuthOptions.DebugMatching, "image-auth-debug-matching", false,
+		"Report the auths keys tried when no credentials match the image registry.")
+	flag.StringVar(&imageAuthOptions.TokenRegistries, "image-auth-token-registries", "",
+		"Comma-separated list of additional registries accepting a token such as a JWT as the password.")
+	flag.StringVar(&imageAuthOptions.UpstreamMirrors, "image-auth-upstream-mirrors", "",
+		"Semicolon-separated list of <upstream>=<mirror>[,<mirror>...] entries naming the mirror hosts trusted to serve an upstream registry.")
+	flag.StringVar(&imageAuthOptions.DefaultRegistry, "image-auth-default-registry", "",
+		"Registry short image references, such as oci://ubuntu:22.04, are resolved against.")
+	flag.BoolVar(&imageAuthOptions.WarnUsernameAsPassword, "image-auth-warn-username-as-password", false,
+		"Warn when the password of image auth credentials equals the username.")
+	flag.StringVar(&imageAuthOptions.StripRegistryHostPrefix, "image-auth-strip-registry-host-prefix", "",
+		"Prefix stripped from registry hosts, in image URLs and auths keys alike, before they are compared, such as an environment-specific proxy prefix.")
+	flag.BoolVar(&imageAuthOptions.YAMLFallback, "image-auth-yaml-fallback", false,
+		"Accept Docker configs written as YAML instead of JSON.")
+	flag.BoolVar(&imageAuthOptions.IncludeMatchedAuthConfig, "image-auth-include-matched-auth-config", false,
+		"Report a redacted copy of the matched auths entry.")
+	flag.StringVar(&imageAuthOptions.CredentialFormat, "image-auth-credential-format", "",
+		"Template the username and password are assembled with, holding exactly two %s verbs. Defaults to %s:%s, the format Ironic expects.")
+	flag.BoolVar(&imageAuthOptions.PreferAuthField, "image-auth-prefer-auth-field", false,
+		"Take credentials from the auth field of an auths entry even when it also has a username and password.")
+	flag.StringVar(&imageAuthOptions.SecretLinkPolicy, "image-auth-secret-link-policy", "",
+		fmt.Sprintf("How image auth secrets that are not linked to their host are treated. Possible values are %s and %s; empty ignores the link.",
+			metal3iocontroller.SecretLinkWarn, metal3iocontroller.SecretLinkStrict))
+	flag.BoolVar(&imageAuthOptions.StrictStructure, "image-auth-strict-structure", false,
+		"Reject image auth secrets whose Docker config holds unexpected keys or types.")
+	flag.IntVar(&imageAuthOptions.MinPasswordLength, "image-auth-min-password-length", 0,
+		"Minimum length of image auth passwords. Zero disables the length check.")
+	flag.StringVar(&imageAuthOptions.PasswordBlocklist, "image-auth-password-blocklist", "",
+		"Comma-separated list of image auth passwords considered weak, compared case-insensitively.")
+	flag.BoolVar(&imageAuthOptions.StrictCredentialStrength, "image-auth-strict-credential-strength", false,
+		"Fail hosts whose image auth credentials are weak instead of only warning.")
+	flag.StringVar(&imageAuthOptions.TagPolicy, "image-auth-tag-policy", "",
+		fmt.Sprintf("Check that OCI images are pinned. Possible values are %s, %s and %s; empty disables the check.",
+			ImageAuthTagPolicyWarn, ImageAuthTagPolicyStrict, ImageAuthTagPolicyRequireDigest))
+
 	flag.Parse()
 
 	logOpts := zap.Options{}
@@ -346,12 +470,58 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&metal3iocontroller.BareMetalHostReconciler{
-		Client:             mgr.GetClient(),
-		Log:                ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
-		ProvisionerFactory: provisionerFactory,
-		APIReader:          mgr.GetAPIReader(),
-	}).SetupWithManager(mgr, preprovImgEnable, maxConcurrency); err != nil {
+	var secretMapping *types.NamespacedName
+	if imageAuthSecretMapping != "" {
+		namespace, name, found := strings.Cut(imageAuthSecretMapping, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(nil, "invalid image auth secret mapping, expected <namespace>/<name>", "value", imageAuthSecretMapping)
+			os.Exit(1)
+		}
+		secretMapping = &types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	if imageAuthRevalidationInterval < 0 || (imageAuthRevalidationInterval > 0 && !imageAuthLiveValidation) {
+		setupLog.Error(nil, "image auth re-validation requires a positive interval and --image-auth-live-validation",
+			"interval", imageAuthRevalidationInterval)
+		os.Exit(1)
+	}
+
+	var registryChecker metal3iocontroller.RegistryChecker
+	if imageAuthLiveValidation {
+		registryChecker = metal3iocontroller.NewHTTPRegistryChecker(nil)
+	}
+
+	var registryDNSCheck *metal3iocontroller.RegistryDNSCheck
+	if imageAuthDNSCheck {
+		registryDNSCheck = &metal3iocontroller.RegistryDNSCheck{}
+	}
+
+	bmhReconciler := &metal3iocontroller.BareMetalHostReconciler{
+		Client:                          mgr.GetClient(),
+		Log:                             ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
+		ProvisionerFactory:              provisionerFactory,
+		APIReader:                       mgr.GetAPIReader(),
+		ImageAuthSecretMapping:          secretMapping,
+		ImageAuthWatchSecrets:           imageAuthWatchSecrets,
+		ImageAuthRegistryChecker:        registryChecker,
+		ImageAuthRevalidationInterval:   imageAuthRevalidationInterval,
+		ImageAuthEventsPerHostPerMinute: imageAuthEventsPerHostPerMinute,
+		ImageAuthTrustedRegistries:      splitFlagList(imageAuthTrustedRegistries),
+		ImageAuthCrossNamespaceSecrets:  splitFlagList(imageAuthCrossNamespaceSecrets),
+		ImageAuthReportNotApplicable:    imageAuthReportNotApplicable,
+		ImageAuthDeferCredentialHelpers: imageAuthDeferCredentialHelpers,
+		ImageAuthDNSCheck:               registryDNSCheck,
+		ImageAuthMaxCredentialAge:       imageAuthMaxCredentialAge,
+		ImageAuthInjectionEvents:        imageAuthInjectionEvents,
+		ImageAuthRecordValidatedAt:      imageAuthRecordValidatedAt,
+		ImageAuthFreshSecretRead:        imageAuthFreshSecretRead,
+		ImageAuthReuseValidResult:       imageAuthReuseValidResult,
+	}
+	if err = applyImageAuthOptions(bmhReconciler, imageAuthOptions); err != nil {
+		setupLog.Error(err, "invalid image auth options")
+		os.Exit(1)
+	}
+	if err = bmhReconciler.SetupWithManager(mgr, preprovImgEnable, maxConcurrency); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BareMetalHost")
 		os.Exit(1)
 	}
@@ -492,6 +662,106 @@ func GetTLSOptionOverrideFuncs(options TLSOptions) ([]func(*tls.Config), error)
 	return tlsOptions, nil
 }
 
+// applyImageAuthOptions configures how the reconciler reads image auth
+// secrets and checks their credentials.
+func applyImageAuthOptions(r *metal3iocontroller.BareMetalHostReconciler, options ImageAuthOptions) error {
+	upstreamMirrors, err := parseUpstreamMirrors(options.UpstreamMirrors)
+	if err != nil {
+		return err
+	}
+	if options.CredentialFormat != "" {
+		if err = secretutils.ValidateCredentialFormat(options.CredentialFormat); err != nil {
+			return err
+		}
+	}
+	var hostNormalizer func(string) string
+	if prefix := options.StripRegistryHostPrefix; prefix != "" {
+		hostNormalizer = func(host string) string { return strings.TrimPrefix(host, prefix) }
+	}
+	r.ImageAuthExtractOptions = secretutils.ExtractOptions{
+		ScanAllKeys:              options.ScanAllKeys,
+		DockerHubCanonicalHost:   options.DockerHubCanonicalHost,
+		PathPrefixMatching:       options.PathPrefixMatching,
+		DebugMatching:            options.DebugMatching,
+		TokenRegistries:          splitFlagList(options.TokenRegistries),
+		UpstreamMirrors:          upstreamMirrors,
+		DefaultRegistry:          options.DefaultRegistry,
+		WarnUsernameAsPassword:   options.WarnUsernameAsPassword,
+		HostNormalizer:           hostNormalizer,
+		YAMLFallback:             options.YAMLFallback,
+		IncludeMatchedAuthConfig: options.IncludeMatchedAuthConfig,
+		CredentialFormat:         options.CredentialFormat,
+		PreferAuthField:          options.PreferAuthField,
+	}
+
+	switch policy := metal3iocontroller.SecretLinkPolicy(options.SecretLinkPolicy); policy {
+	case metal3iocontroller.SecretLinkIgnore, metal3iocontroller.SecretLinkWarn, metal3iocontroller.SecretLinkStrict:
+		r.ImageAuthSecretLinkPolicy = policy
+	default:
+		return fmt.Errorf("unexpected image auth secret link policy %q (must be one of: %s, %s)",
+			options.SecretLinkPolicy, metal3iocontroller.SecretLinkWarn, metal3iocontroller.SecretLinkStrict)
+	}
+	r.ImageAuthStrictStructure = options.StrictStructure
+
+	if options.MinPasswordLength < 0 {
+		return fmt.Errorf("image auth minimum password length: %d is invalid", options.MinPasswordLength)
+	}
+	blocklist := splitFlagList(options.PasswordBlocklist)
+	if options.MinPasswordLength > 0 || len(blocklist) > 0 {
+		r.ImageAuthCredentialStrength = &metal3iocontroller.CredentialStrengthPolicy{
+			MinPasswordLength: options.MinPasswordLength,
+			Blocklist:         blocklist,
+			Strict:            options.StrictCredentialStrength,
+		}
+	}
+
+	switch options.TagPolicy {
+	case "":
+	case ImageAuthTagPolicyWarn:
+		r.ImageAuthTagPolicy = &metal3iocontroller.ImageTagPolicy{}
+	case ImageAuthTagPolicyStrict:
+		r.ImageAuthTagPolicy = &metal3iocontroller.ImageTagPolicy{Strict: true}
+	case ImageAuthTagPolicyRequireDigest:
+		r.ImageAuthTagPolicy = &metal3iocontroller.ImageTagPolicy{RequireDigest: true}
+	default:
+		return fmt.Errorf("unexpected image auth tag policy %q (must be one of: %s, %s, %s)",
+			options.TagPolicy, ImageAuthTagPolicyWarn, ImageAuthTagPolicyStrict, ImageAuthTagPolicyRequireDigest)
+	}
+	return nil
+}
+
+// parseUpstreamMirrors parses a semicolon-separated list of
+// <upstream>=<mirror>[,<mirror>...] entries.
+func parseUpstreamMirrors(value string) (map[string][]string, error) {
+	var mirrors map[string][]string
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		upstream, hosts, found := strings.Cut(entry, "=")
+		upstream = strings.TrimSpace(upstream)
+		if !found || upstream == "" || len(splitFlagList(hosts)) == 0 {
+			return nil, fmt.Errorf("invalid upstream mirrors entry %q, expected <upstream>=<mirror>[,<mirror>...]", entry)
+		}
+		if mirrors == nil {
+			mirrors = make(map[string][]string)
+		}
+		mirrors[upstream] = append(mirrors[upstream], splitFlagList(hosts)...)
+	}
+	return mirrors, nil
+}
+
+// splitFlagList splits a comma-separated flag value, dropping blank items.
+func splitFlagList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getMaxConcurrentReconciles(controllerConcurrency int) (int, error) {
 	if controllerConcurrency > 0 {
 		ctrl.Log.Info(fmt.Sprintf("controller concurrency will be set to %d according to command line flag", controllerConcurrency))
@@ -21,6 +21,7 @@ import (
 	"crypto/tls"
 	"testing"
 
+	metal3iocontroller "github.com/metal3-io/baremetal-operator/internal/controller/metal3.io"
 	. "github.com/onsi/gomega"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -144,3 +145,49 @@ func TestTLSOptions(t *testing.T) {
 		g.Expect(err).ShouldNot(HaveOccurred())
 	})
 }
+
+func TestApplyImageAuthOptions(t *testing.T) {
+	t.Run("should build the extract options and policies from the flags", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &metal3iocontroller.BareMetalHostReconciler{}
+		err := applyImageAuthOptions(r, ImageAuthOptions{
+			PathPrefixMatching:      true,
+			UpstreamMirrors:         "docker.io=mirror.example.com,mirror2.example.com;quay.io=quay-mirror.example.com",
+			StripRegistryHostPrefix: "proxy-",
+			SecretLinkPolicy:        "Strict",
+			MinPasswordLength:       12,
+			TagPolicy:               ImageAuthTagPolicyRequireDigest,
+		})
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(r.ImageAuthExtractOptions.PathPrefixMatching).To(BeTrue())
+		g.Expect(r.ImageAuthExtractOptions.UpstreamMirrors).To(Equal(map[string][]string{
+			"docker.io": {"mirror.example.com", "mirror2.example.com"},
+			"quay.io":   {"quay-mirror.example.com"},
+		}))
+		g.Expect(r.ImageAuthExtractOptions.HostNormalizer("proxy-quay.io")).To(Equal("quay.io"))
+		g.Expect(r.ImageAuthSecretLinkPolicy).To(Equal(metal3iocontroller.SecretLinkStrict))
+		g.Expect(r.ImageAuthCredentialStrength).To(Equal(&metal3iocontroller.CredentialStrengthPolicy{MinPasswordLength: 12}))
+		g.Expect(r.ImageAuthTagPolicy).To(Equal(&metal3iocontroller.ImageTagPolicy{RequireDigest: true}))
+	})
+	t.Run("should leave the policies unset by default", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &metal3iocontroller.BareMetalHostReconciler{}
+		g.Expect(applyImageAuthOptions(r, ImageAuthOptions{})).To(Succeed())
+		g.Expect(r.ImageAuthExtractOptions.HostNormalizer).To(BeNil())
+		g.Expect(r.ImageAuthCredentialStrength).To(BeNil())
+		g.Expect(r.ImageAuthTagPolicy).To(BeNil())
+	})
+	t.Run("should reject invalid values", func(t *testing.T) {
+		for _, options := range []ImageAuthOptions{
+			{UpstreamMirrors: "docker.io"},
+			{UpstreamMirrors: "=mirror.example.com"},
+			{CredentialFormat: "%s"},
+			{SecretLinkPolicy: "Always"},
+			{MinPasswordLength: -1},
+			{TagPolicy: "Pinned"},
+		} {
+			g := NewWithT(t)
+			g.Expect(applyImageAuthOptions(&metal3iocontroller.BareMetalHostReconciler{}, options)).ToNot(Succeed(), "%+v", options)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+// Package imageauthtest provides helpers for integration and e2e tests, in
+// this repository and downstream, that exercise image authentication across
+// many hosts.
+package imageauthtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	controllers "github.com/metal3-io/baremetal-operator/internal/controller/metal3.io"
+	"github.com/metal3-io/baremetal-operator/pkg/secretutils"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reason is the machine-readable outcome of the image auth validation of a
+// host, as reported in the ImageAuth host conditions.
+type Reason string
+
+// The reasons a host result can report.
+const (
+	ReasonNotRequired              = Reason(controllers.ReasonNotRequired)
+	ReasonNotApplicable            = Reason(controllers.ReasonNotApplicable)
+	ReasonNoAuthExpected           = Reason(controllers.ReasonNoAuthExpected)
+	ReasonNoImage                  = Reason(controllers.ReasonNoImage)
+	ReasonEmptyURL                 = Reason(controllers.ReasonEmptyURL)
+	ReasonInvalidImageURL          = Reason(controllers.ReasonInvalidImageURL)
+	ReasonValid                    = Reason(controllers.ReasonValid)
+	ReasonSecretNotFound           = Reason(controllers.ReasonSecretNotFound)
+	ReasonSecretPending            = Reason(controllers.ReasonSecretPending)
+	ReasonSecretForbidden          = Reason(controllers.ReasonSecretForbidden)
+	ReasonInvalidSecretReference   = Reason(controllers.ReasonInvalidSecretReference)
+	ReasonSecretNotLinked          = Reason(controllers.ReasonSecretNotLinked)
+	ReasonSecretTypeUnsupported    = Reason(controllers.ReasonSecretTypeUnsupported)
+	ReasonMissingDataKey           = Reason(controllers.ReasonMissingDataKey)
+	ReasonEmptyAuthConfig          = Reason(controllers.ReasonEmptyAuthConfig)
+	ReasonNoCredentialsForRegistry = Reason(controllers.ReasonNoCredentialsForRegistry)
+	ReasonAmbiguousRegistryEntry   = Reason(controllers.ReasonAmbiguousRegistryEntry)
+	ReasonSecretNotDecrypted       = Reason(controllers.ReasonSecretNotDecrypted)
+	ReasonTruncatedData            = Reason(controllers.ReasonTruncatedData)
+	ReasonDeferred                 = Reason(controllers.ReasonDeferred)
+	ReasonSecretIsBMCCredentials   = Reason(controllers.ReasonSecretIsBMCCredentials)
+	ReasonCredentialsInvalid       = Reason(controllers.ReasonCredentialsInvalid)
+	ReasonCredentialsWeak          = Reason(controllers.ReasonCredentialsWeak)
+	ReasonRegistryNotAllowed       = Reason(controllers.ReasonRegistryNotAllowed)
+	ReasonRegistryUnreachable      = Reason(controllers.ReasonRegistryUnreachable)
+	ReasonRegistryUnresolvable     = Reason(controllers.ReasonRegistryUnresolvable)
+	ReasonAuthRejected             = Reason(controllers.ReasonAuthRejected)
+	ReasonConfigStructureInvalid   = Reason(controllers.ReasonConfigStructureInvalid)
+	ReasonUnpinnedImage            = Reason(controllers.ReasonUnpinnedImage)
+	ReasonTagNotAllowed            = Reason(controllers.ReasonTagNotAllowed)
+	ReasonInvalidCABundle          = Reason(controllers.ReasonInvalidCABundle)
+)
+
+// Options configures the validation, mirroring the image auth flags of the
+// operator. The zero value validates with the operator defaults, without
+// live validation.
+type Options struct {
+	// ExtractOptions tunes how credentials are located in the secret.
+	ExtractOptions secretutils.ExtractOptions
+	// TrustedRegistries, when not empty, is the allowlist of registries
+	// images may be pulled from.
+	TrustedRegistries []string
+	// CrossNamespaceSecrets lists the namespaces, besides the host
+	// namespace, that auth secret references may point to.
+	CrossNamespaceSecrets []string
+	// StrictStructure rejects Docker configs with unexpected keys or types.
+	StrictStructure bool
+	// DeferCredentialHelpers treats a registry covered by a credHelpers
+	// entry as deferred rather than invalid.
+	DeferCredentialHelpers bool
+	// MaxCredentialAge, when set, flags secrets that should be rotated.
+	MaxCredentialAge time.Duration
+	// CollectAll reports every problem found instead of stopping at the
+	// first one.
+	CollectAll bool
+}
+
+// Problem is one of the problems found for a host with Options.CollectAll.
+type Problem struct {
+	Reason  Reason
+	Message string
+}
+
+// Result is the image auth outcome of a host. It never holds the
+// credentials themselves.
+type Result struct {
+	// Reason summarizes the outcome.
+	Reason Reason
+	// Message is a human readable explanation of a failure.
+	Message string
+	// SecretName and SecretNamespace identify the auth secret.
+	SecretName      string
+	SecretNamespace string
+	// RegistryHost is the registry the image is pulled from.
+	RegistryHost string
+	// MatchedAuthKey is the auths entry the credentials were taken from.
+	MatchedAuthKey string
+	// UsedFallback is true when the credentials were found through a
+	// fallback such as the secret mapping or a registry mirror.
+	UsedFallback bool
+	// Warnings lists non-fatal advisories.
+	Warnings []string
+	// Problems lists every problem found with Options.CollectAll.
+	Problems []Problem
+}
+
+// HostResult is the image auth outcome for a single host.
+type HostResult struct {
+	Host   types.NamespacedName
+	Result Result
+	Err    error
+}
+
+// Summary is the outcome of validating the image auth of every host.
+type Summary struct {
+	// Results holds one entry per host, in the order the hosts were listed.
+	Results []HostResult
+	// Valid, Failed and NotRequired count the hosts whose credentials were
+	// found, whose validation failed, and which need no credentials.
+	Valid       int
+	Failed      int
+	NotRequired int
+}
+
+// Get returns the result for the named host, or nil if it was not validated.
+func (s *Summary) Get(namespace, name string) *HostResult {
+	for i := range s.Results {
+		if s.Results[i].Host.Namespace == namespace && s.Results[i].Host.Name == name {
+			return &s.Results[i]
+		}
+	}
+	return nil
+}
+
+// ValidateAllHosts lists every BareMetalHost visible through c and validates
+// its image auth with the options. An error is only returned if the hosts
+// cannot be listed; per-host failures are reported in the summary.
+func ValidateAllHosts(ctx context.Context, c client.Client, options Options) (*Summary, error) {
+	validator := newValidator(options)
+
+	hosts := &metal3api.BareMetalHostList{}
+	if err := c.List(ctx, hosts); err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+
+	secretManager := secretutils.NewSecretManager(logr.Discard(), c, c)
+	summary := &Summary{}
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		result, err := validator.Evaluate(ctx, host, secretManager)
+		hostResult := HostResult{
+			Host: client.ObjectKeyFromObject(host),
+			Err:  err,
+		}
+		if result != nil {
+			hostResult.Result = newResult(result)
+		}
+		summary.Results = append(summary.Results, hostResult)
+		switch {
+		case err != nil:
+			summary.Failed++
+		case hostResult.Result.Reason == ReasonValid:
+			summary.Valid++
+		default:
+			summary.NotRequired++
+		}
+	}
+	return summary, nil
+}
+
+// newValidator returns a validator without an event recorder configured
+// with the options.
+func newValidator(options Options) *controllers.ImageAuthValidator {
+	validator := controllers.NewImageAuthValidator(nil)
+	validator.ExtractOptions = options.ExtractOptions
+	validator.TrustedRegistries = slices.Clone(options.TrustedRegistries)
+	validator.CrossNamespaceSecrets = slices.Clone(options.CrossNamespaceSecrets)
+	validator.StrictStructure = options.StrictStructure
+	validator.DeferCredentialHelpers = options.DeferCredentialHelpers
+	validator.MaxCredentialAge = options.MaxCredentialAge
+	validator.CollectAll = options.CollectAll
+	return validator
+}
+
+// newResult converts a validator result, dropping the credentials.
+func newResult(result *controllers.ImageAuthResult) Result {
+	converted := Result{
+		Reason:          Reason(result.Reason),
+		Message:         result.Message,
+		SecretName:      result.SecretName,
+		SecretNamespace: result.SecretNamespace,
+		RegistryHost:    result.RegistryHost,
+		MatchedAuthKey:  result.MatchedAuthKey,
+		UsedFallback:    result.UsedFallback,
+		Warnings:        slices.Clone(result.Warnings),
+	}
+	for _, problem := range result.Problems {
+		converted.Problems = append(converted.Problems, Problem{Reason: Reason(problem.Reason), Message: problem.Message})
+	}
+	return converted
+}
@@ -0,0 +1,122 @@
+package imageauthtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	metal3api "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newHost(name, imageURL, secretName string) *metal3api.BareMetalHost {
+	host := &metal3api.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: metal3api.BareMetalHostSpec{
+			Image: &metal3api.Image{URL: imageURL},
+		},
+	}
+	if secretName != "" {
+		host.Spec.Image.OCIAuthSecretName = &secretName
+	}
+	return host
+}
+
+func newClient(t *testing.T, hosts ...client.Object) client.Client {
+	t.Helper()
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"quay.io": map[string]string{
+				"auth": base64.StdEncoding.EncodeToString([]byte("testuser:testpass")),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = metal3api.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	objects := []client.Object{secret}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, hosts...)...).Build()
+}
+
+func TestValidateAllHosts(t *testing.T) {
+	c := newClient(t,
+		newHost("valid", "oci://quay.io/repo/image:tag", "pull-secret"),
+		newHost("wrong-registry", "oci://registry.example.com/repo/image:tag", "pull-secret"),
+		newHost("missing-secret", "oci://quay.io/repo/image:tag", "no-such-secret"),
+		newHost("http-image", "http://example.com/image.qcow2", ""),
+	)
+
+	summary, err := ValidateAllHosts(t.Context(), c, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(summary.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(summary.Results))
+	}
+	if summary.Valid != 1 || summary.Failed != 2 || summary.NotRequired != 1 {
+		t.Errorf("unexpected counts: valid=%d failed=%d notRequired=%d",
+			summary.Valid, summary.Failed, summary.NotRequired)
+	}
+
+	expected := map[string]Reason{
+		"valid":          ReasonValid,
+		"wrong-registry": ReasonCredentialsInvalid,
+		"missing-secret": ReasonSecretNotFound,
+		"http-image":     ReasonNotApplicable,
+	}
+	for name, reason := range expected {
+		hostResult := summary.Get("default", name)
+		if hostResult == nil {
+			t.Errorf("no result for host %s", name)
+			continue
+		}
+		if hostResult.Result.Reason != reason {
+			t.Errorf("host %s: expected reason %q, got %q", name, reason, hostResult.Result.Reason)
+		}
+	}
+	if summary.Get("default", "unknown") != nil {
+		t.Error("expected no result for an unknown host")
+	}
+}
+
+func TestValidateAllHosts_Options(t *testing.T) {
+	c := newClient(t, newHost("valid", "oci://quay.io/repo/image:tag", "pull-secret"))
+
+	summary, err := ValidateAllHosts(t.Context(), c, Options{TrustedRegistries: []string{"registry.example.com"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hostResult := summary.Get("default", "valid")
+	if hostResult == nil {
+		t.Fatal("no result for host valid")
+	}
+	if hostResult.Result.Reason != ReasonRegistryNotAllowed || hostResult.Err == nil {
+		t.Errorf("expected reason %q with an error, got %q (%v)", ReasonRegistryNotAllowed, hostResult.Result.Reason, hostResult.Err)
+	}
+	if summary.Failed != 1 {
+		t.Errorf("expected 1 failed host, got %d", summary.Failed)
+	}
+
+	summary, err = ValidateAllHosts(t.Context(), c, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := summary.Get("default", "valid").Result
+	if result.RegistryHost != "quay.io" || result.MatchedAuthKey != "quay.io" || result.SecretName != "pull-secret" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -1,17 +1,140 @@
 package secretutils
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/cpuguy83/dockercfg"
+	"golang.org/x/net/idna"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
 )
 
+// ExtractOptions tunes how the Docker config is located and matched inside a
+// secret. The zero value gives the strict default behaviour.
+type ExtractOptions struct {
+	// ScanAllKeys enables a fallback for secrets that were mounted and
+	// re-exported under a different key name: when neither of the standard
+	// keys is present, every data value is inspected (in key order) and the
+	// first one that parses as a Docker config with auths is used.
+	ScanAllKeys bool
+
+	// DockerHubCanonicalHost is the name under which Docker Hub credentials
+	// are reported, whichever alias they were stored under. Defaults to
+	// "docker.io".
+	DockerHubCanonicalHost string
+
+	// PathPrefixMatching enables credential selection for registries exposed
+	// behind a shared host under path prefixes, such as
+	// registry.internal/quay and registry.internal/gcr. An auths key of the
+	// form "host/prefix" then applies to images whose first path segment
+	// after the host is "prefix", and takes precedence over a host-only key.
+	// Path-qualified keys never match images under another prefix.
+	PathPrefixMatching bool
+
+	// DebugMatching records, in order, the auths keys tried when looking up
+	// the registry. On a miss they are reported in RegistryNotFoundError to
+	// help troubleshooting. It is off by default to avoid the overhead.
+	DebugMatching bool
+
+	// TokenRegistries lists additional registries, as patterns for
+	// RegistryMatches, that accept a token such as a JWT as the password.
+	// A JWT-shaped password for any other registry yields a warning.
+	TokenRegistries []string
+
+	// UpstreamMirrors maps an upstream registry, as named by the "ns" query
+	// parameter containerd appends to pulls through a mirror, to the mirror
+	// hosts, as patterns for RegistryMatches, trusted to serve it. The
+	// credentials of the upstream registry are only used for images pulled
+	// from one of its mirrors, so that an image URL cannot select the
+	// credentials of a registry it is not pulled from. The "ns" parameter is
+	// ignored for any other host.
+	UpstreamMirrors map[string][]string
+
+	// DefaultRegistry is the registry short image references, such as
+	// "oci://ubuntu:22.04" or "oci://library/ubuntu:22.04", are resolved
+	// against, like the unqualified search registries of container runtimes.
+	// A reference is short when its first component is not a host name:
+	// it contains neither "." nor ":" and is not "localhost", or it has no
+	// "/" after it and, up to any tag, neither contains "." nor is
+	// "localhost". When empty, the first component is always taken as the
+	// registry host.
+	DefaultRegistry string
+
+	// WarnUsernameAsPassword adds a warning when the password equals the
+	// username, a common sign that the username was pasted in both fields.
+	// Such credentials are still returned.
+	WarnUsernameAsPassword bool
+
+	// HostNormalizer, when set, rewrites registry hosts before they are
+	// compared, such as to strip an environment-specific proxy prefix. It is
+	// applied, followed by lowercasing, both to the host of the image and to
+	// the host of every auths key.
+	HostNormalizer func(host string) string
+
+	// YAMLFallback accepts a Docker config written as YAML instead of JSON,
+	// as some GitOps tools store it by mistake. It only applies when the
+	// data is not valid JSON.
+	YAMLFallback bool
+
+	// IncludeMatchedAuthConfig reports a redacted copy of the matched auths
+	// entry in RegistryCredentials, to show which of its fields were used.
+	IncludeMatchedAuthConfig bool
+
+	// CredentialFormat is the fmt template the username and password, in
+	// that order, are assembled with before being base64-encoded, for
+	// consumers other than Ironic. Explicit argument indexes, such as
+	// "%[2]s:%[1]s", change the order. Defaults to "%s:%s", the format Ironic
+	// expects, which the validator checks on the credentials also assume.
+	// The template must hold exactly two %s verbs, one for each of the
+	// username and the password; see ValidateCredentialFormat.
+	CredentialFormat string
+
+	// PreferAuthField takes the credentials from the auth field of an
+	// auths entry when it is set, even if the entry also has a username and
+	// password, for tooling that only updates the auth field on rotation.
+	// By default the username and password take precedence.
+	PreferAuthField bool
+}
+
+const (
+	defaultDockerHubCanonicalHost = "docker.io"
+	defaultCredentialFormat       = "%s:%s"
+)
+
+func (o ExtractOptions) credentialFormat() string {
+	if o.CredentialFormat == "" {
+		return defaultCredentialFormat
+	}
+	return o.CredentialFormat
+}
+
+// normalizeHost applies the HostNormalizer, if any, to host.
+func (o ExtractOptions) normalizeHost(host string) string {
+	if o.HostNormalizer == nil {
+		return host
+	}
+	return strings.ToLower(o.HostNormalizer(host))
+}
+
+func (o ExtractOptions) dockerHubCanonicalHost() string {
+	if o.DockerHubCanonicalHost != "" {
+		return o.DockerHubCanonicalHost
+	}
+	return defaultDockerHubCanonicalHost
+}
+
 // ExtractRegistryCredentials extracts the registry credentials from a Kubernetes secret
 // for the registry associated with the given image URL.
 // It supports both kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg secret types.
@@ -19,50 +142,1087 @@ import (
 // base64-encoded "username:password" (NOT the entire Docker config JSON).
 // This is what Ironic accepts in instance_info[image_pull_secret].
 func ExtractRegistryCredentials(secret *corev1.Secret, imageURL string) (string, error) {
+	return ExtractRegistryCredentialsWithOptions(secret, imageURL, ExtractOptions{})
+}
+
+// ExtractRegistryCredentialsWithOptions is like ExtractRegistryCredentials
+// but allows the lookup behaviour to be tuned.
+func ExtractRegistryCredentialsWithOptions(secret *corev1.Secret, imageURL string, opts ExtractOptions) (string, error) {
+	creds, err := ResolveRegistryCredentials(secret, imageURL, opts)
+	if err != nil {
+		return "", err
+	}
+	return creds.Credentials, nil
+}
+
+// ExtractRegistryCredentialsFromBytes is like ExtractRegistryCredentials for
+// callers holding the raw Docker config rather than a Secret. The data is
+// read as the legacy dockercfg format, a bare auths map, when legacy is set,
+// and as the dockerconfigjson format otherwise.
+func ExtractRegistryCredentialsFromBytes(dockerConfigJSON []byte, imageURL string, legacy bool) (string, error) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+	}
+	if legacy {
+		secret.Type = corev1.SecretTypeDockercfg
+		secret.Data = map[string][]byte{corev1.DockerConfigKey: dockerConfigJSON}
+	}
+	return ExtractRegistryCredentials(secret, imageURL)
+}
+
+// RegistryCredentials describes the credentials resolved from a secret for a
+// particular image.
+type RegistryCredentials struct {
+	// Credentials is base64("username:password") as expected by Ironic.
+	Credentials string
+	// RegistryHost is the registry host derived from the image URL. Docker
+	// Hub hosts are reported under the canonical Docker Hub name.
+	RegistryHost string
+	// MatchedAuthKey is the auths entry that supplied the credentials, as
+	// stored in the secret.
+	MatchedAuthKey string
+	// CanonicalAuthKey is MatchedAuthKey with Docker Hub aliases reported
+	// under the canonical Docker Hub name, so that it compares equal across
+	// secrets storing the credentials under different aliases.
+	CanonicalAuthKey string
+	// Warnings describes problems with the secret that were tolerated.
+	Warnings []string
+	// TokenAuth is set when the credentials are a token rather than a
+	// password, such as an identity token or an Azure Container Registry
+	// refresh token.
+	TokenAuth bool
+	// MatchedAuthConfig is a redacted copy of the matched auths entry, set
+	// when ExtractOptions.IncludeMatchedAuthConfig is enabled.
+	MatchedAuthConfig *RedactedAuthConfig
+	// LegacyFormat is set when the credentials were read from the legacy
+	// dockercfg key rather than the dockerconfigjson key.
+	LegacyFormat bool
+}
+
+// ResolveRegistryCredentials finds the credentials in the secret for the
+// registry hosting the given image and reports which entry was used.
+func ResolveRegistryCredentials(secret *corev1.Secret, imageURL string, opts ExtractOptions) (*RegistryCredentials, error) {
 	if secret == nil {
-		return "", errors.New("secret is nil")
+		return nil, errors.New("secret is nil")
+	}
+
+	cfg, stringKeys, err := parseDockerConfig(secret, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := resolveFromConfig(cfg, imageURL, opts)
+	var notFound *RegistryNotFoundError
+	if errors.As(err, &notFound) && len(cfg.AuthConfigs) == 0 && len(cfg.CredentialHelpers) == 0 && cfg.CredentialsStore == "" {
+		return nil, fmt.Errorf("%w: %w", ErrEmptyAuthConfig, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(stringKeys, creds.MatchedAuthKey) {
+		creds.Warnings = append(creds.Warnings, fmt.Sprintf(
+			"auths entry %q is a string instead of an object; it was interpreted as the auth field", creds.MatchedAuthKey))
+	}
+	_, hasJSONKey := secret.Data[corev1.DockerConfigJsonKey]
+	_, hasLegacyKey := secret.Data[corev1.DockerConfigKey]
+	creds.LegacyFormat = !hasJSONKey && hasLegacyKey
+	if legacyKeyDisagrees(secret, imageURL, opts, creds) {
+		creds.Warnings = append(creds.Warnings, fmt.Sprintf(
+			"secret has both %s and %s keys with different credentials for registry %s; the %s key is used",
+			corev1.DockerConfigJsonKey, corev1.DockerConfigKey, creds.RegistryHost, corev1.DockerConfigJsonKey))
+	}
+	return creds, nil
+}
+
+// legacyKeyDisagrees reports whether the secret also holds a legacy dockercfg
+// key, next to the dockerconfigjson key the credentials were taken from, with
+// other credentials for the same registry. Such a stale legacy entry would
+// otherwise go unnoticed, since the dockerconfigjson key always wins.
+func legacyKeyDisagrees(secret *corev1.Secret, imageURL string, opts ExtractOptions, creds *RegistryCredentials) bool {
+	legacy, ok := secret.Data[corev1.DockerConfigKey]
+	if _, hasJSON := secret.Data[corev1.DockerConfigJsonKey]; !ok || !hasJSON {
+		return false
+	}
+	auths, _, err := decodeLegacyAuthConfigs(opts.jsonFromYAML(legacy))
+	if err != nil {
+		return false
+	}
+	legacyCreds, err := resolveFromConfig(dockercfg.Config{AuthConfigs: auths}, imageURL, opts)
+	if err != nil {
+		return false
+	}
+	return legacyCreds.Credentials != creds.Credentials
+}
+
+// MultiRegistryCredentials is the outcome of resolving credentials for
+// several images from a single secret.
+type MultiRegistryCredentials struct {
+	// Resolved holds the credentials found, keyed by image URL, since
+	// images on the same host may match different credentials with
+	// ExtractOptions.PathPrefixMatching.
+	Resolved map[string]*RegistryCredentials
+	// Failed holds the reason credentials could not be found, keyed by
+	// image URL.
+	Failed map[string]error
+}
+
+// ResolveRegistryCredentialsForImages resolves credentials for every image in
+// imageURLs from the same secret, for hosts pulling from several registries.
+// Unlike ResolveRegistryCredentials, a registry missing from the secret does
+// not stop the resolution: it is reported in Failed. An error is only
+// returned when the secret itself cannot be parsed.
+func ResolveRegistryCredentialsForImages(secret *corev1.Secret, imageURLs []string, opts ExtractOptions) (*MultiRegistryCredentials, error) {
+	if secret == nil {
+		return nil, errors.New("secret is nil")
+	}
+
+	cfg, _, err := parseDockerConfig(secret, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MultiRegistryCredentials{
+		Resolved: map[string]*RegistryCredentials{},
+		Failed:   map[string]error{},
+	}
+	for _, imageURL := range imageURLs {
+		creds, err := resolveFromConfig(cfg, imageURL, opts)
+		if err != nil {
+			result.Failed[imageURL] = err
+			continue
+		}
+		result.Resolved[imageURL] = creds
+	}
+	return result, nil
+}
+
+func resolveFromConfig(cfg dockercfg.Config, imageURL string, opts ExtractOptions) (*RegistryCredentials, error) {
+	registryHost, err := ResolveRegistryHost(imageURL, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract registry host from image URL: %w", err)
+	}
+	registryHost = opts.normalizeHost(registryHost)
+
+	var (
+		matchedKey string
+		auth       dockercfg.AuthConfig
+		found      bool
+	)
+	var attempts *keyAttempts
+	if opts.DebugMatching {
+		attempts = &keyAttempts{}
+	}
+	if opts.PathPrefixMatching {
+		matchedKey, auth, found = findPathPrefixAuthConfig(cfg, registryHost, imagePathPrefix(imageURL), attempts)
+		if !found {
+			cfg = withoutPathPrefixKeys(cfg)
+		}
+	}
+	if !found {
+		matchedKey, auth, found = findAuthConfig(cfg, registryHost, opts.normalizeHost, attempts)
+	}
+	if !found {
+		return nil, &RegistryNotFoundError{
+			Host:             registryHost,
+			AttemptedKeys:    attempts.list(),
+			PortMismatchKeys: portMismatchKeys(cfg, registryHost),
+		}
+	}
+
+	username, password, err := credentialsFromAuthConfig(auth, opts.PreferAuthField)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for registry %s: %w", registryHost, err)
 	}
 
+	if username == "" && password == "" {
+		// An entry without any usable credentials is as good as no entry
+		return nil, fmt.Errorf("%w under key %q: %w", ErrNoCredentials, matchedKey, &RegistryNotFoundError{Host: registryHost})
+	}
+	if variant, ok := caseVariantConflict(cfg, matchedKey, username, password, opts); ok {
+		return nil, fmt.Errorf("%w: keys %q and %q hold different credentials for registry %s",
+			ErrAmbiguousRegistryEntry, matchedKey, variant, registryHost)
+	}
+
+	var warnings []string
+	if warning := tokenPasswordWarning(registryHost, username, password, opts); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	if opts.WarnUsernameAsPassword && username != "" && username == password {
+		warnings = append(warnings, fmt.Sprintf(
+			"the password for registry %s is the same as the username; check it was not pasted in both fields", registryHost))
+	}
+
+	canonicalKey := matchedKey
+	if IsDockerHubHost(registryHost) {
+		canonical := opts.dockerHubCanonicalHost()
+		registryHost = canonical
+		canonicalKey = canonical
+	}
+
+	// Return credentials in the format expected by Ironic (base64-encoded "username:password")
+	// unless configured otherwise
+	if err := ValidateCredentialFormat(opts.credentialFormat()); err != nil {
+		return nil, err
+	}
+	credentials := fmt.Sprintf(opts.credentialFormat(), username, password)
+	var matchedAuthConfig *RedactedAuthConfig
+	if opts.IncludeMatchedAuthConfig {
+		matchedAuthConfig = redactAuthConfig(auth)
+	}
+	return &RegistryCredentials{
+		Credentials:       base64.StdEncoding.EncodeToString([]byte(credentials)),
+		RegistryHost:      registryHost,
+		MatchedAuthKey:    matchedKey,
+		CanonicalAuthKey:  canonicalKey,
+		Warnings:          warnings,
+		TokenAuth:         isTokenAuth(username),
+		MatchedAuthConfig: matchedAuthConfig,
+	}, nil
+}
+
+// caseVariantConflict returns another auths key differing from matchedKey
+// only by case, such as "Registry.example.com" next to
+// "registry.example.com", whose credentials differ from the matched ones.
+// Since registry hosts are case-insensitive, either entry could be meant.
+func caseVariantConflict(cfg dockercfg.Config, matchedKey, username, password string, opts ExtractOptions) (string, bool) {
+	for _, key := range sortedKeys(cfg.AuthConfigs) {
+		if key == matchedKey || !strings.EqualFold(key, matchedKey) {
+			continue
+		}
+		otherUsername, otherPassword, err := credentialsFromAuthConfig(cfg.AuthConfigs[key], opts.PreferAuthField)
+		if err != nil || otherUsername != username || otherPassword != password {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// dockerHubAliases lists the auths keys under which Docker Hub credentials
+// are commonly stored, in order of preference.
+var dockerHubAliases = []string{
+	"https://index.docker.io/v1/",
+	"index.docker.io",
+	"docker.io",
+	"registry-1.docker.io",
+}
+
+// DockerHubAliases returns the auths keys under which Docker Hub
+// credentials are commonly stored, in the order findAuthConfig tries them.
+// The returned slice is a copy and may be modified by the caller.
+func DockerHubAliases() []string {
+	return slices.Clone(dockerHubAliases)
+}
+
+// IsDockerHubHost reports whether host is one of the Docker Hub aliases.
+func IsDockerHubHost(host string) bool {
+	return slices.Contains(dockerHubAliases, host)
+}
+
+// findAuthConfig looks up the auths entry for registryHost. An exact key
+// match wins; for Docker Hub any of the well-known aliases is accepted. The
+// host of every other key is passed through normalizeHost before comparison.
+func findAuthConfig(cfg dockercfg.Config, registryHost string, normalizeHost func(string) string, attempts *keyAttempts) (string, dockercfg.AuthConfig, bool) {
+	attempts.add(registryHost)
+	if auth, ok := cfg.AuthConfigs[registryHost]; ok {
+		return registryHost, auth, true
+	}
+
+	if IsDockerHubHost(registryHost) {
+		for _, alias := range dockerHubAliases {
+			attempts.add(alias)
+			if auth, ok := cfg.AuthConfigs[alias]; ok {
+				return alias, auth, true
+			}
+		}
+	}
+
+	// Keys may be scheme-qualified, such as "https://registry:5000", which is
+	// common in the legacy dockercfg format, or carry userinfo, such as
+	// "user@registry.example.com". The credentials still apply to the host
+	// once the key is reduced to its hostname. Internationalized names are
+	// compared in punycode, so either form matches the other, and the default
+	// HTTPS port is ignored, so "registry:443" matches "registry".
+	asciiHost := withoutDefaultPort(toPunycode(registryHost))
+	for _, key := range sortedKeys(cfg.AuthConfigs) {
+		host := withoutDefaultPort(toPunycode(normalizeHost(normalizeAuthKey(key))))
+		attempts.add(key + " (as " + host + ")")
+		if host == asciiHost || (IsDockerHubHost(host) && IsDockerHubHost(registryHost)) {
+			return key, cfg.AuthConfigs[key], true
+		}
+	}
+
+	return "", dockercfg.AuthConfig{}, false
+}
+
+// keyAttempts records the auths keys tried by the lookup. A nil *keyAttempts
+// records nothing, so collection costs nothing when debugging is off.
+type keyAttempts []string
+
+func (a *keyAttempts) add(key string) {
+	if a != nil {
+		*a = append(*a, key)
+	}
+}
+
+func (a *keyAttempts) list() []string {
+	if a == nil {
+		return nil
+	}
+	return *a
+}
+
+// normalizeAuthKey reduces an auths key to the registry hostname it applies
+// to, removing any scheme, path, query, fragment and leading "user@". Keys
+// with a query or fragment are usually URLs copied from a browser.
+func normalizeAuthKey(key string) string {
+	host := key
+	if _, rest, found := strings.Cut(host, "://"); found {
+		host = rest
+	}
+	if end := strings.IndexAny(host, "/?#"); end >= 0 {
+		host = host[:end]
+	}
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return host
+}
+
+// toPunycode converts the hostname part of host, which may include a port, to
+// its punycode form. Hosts that are not valid domain names are returned
+// unchanged.
+func toPunycode(host string) string {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, ""
+	}
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return host
+	}
+	if port != "" {
+		return net.JoinHostPort(ascii, port)
+	}
+	return ascii
+}
+
+// withoutDefaultPort drops an explicit ":443" from host, as registries are
+// reached over HTTPS and the port is then implied.
+func withoutDefaultPort(host string) string {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil || port != "443" {
+		return host
+	}
+	if strings.Contains(name, ":") {
+		return "[" + name + "]"
+	}
+	return name
+}
+
+// RegistryNotFoundError is returned when the secret holds no usable
+// credentials for the registry hosting the image.
+type RegistryNotFoundError struct {
+	Host string
+	// AttemptedKeys lists, in order, the auths keys tried. It is only
+	// filled in with ExtractOptions.DebugMatching.
+	AttemptedKeys []string
+	// PortMismatchKeys lists the auths keys for the same host name on a
+	// different port, the likely cause of the miss.
+	PortMismatchKeys []string
+}
+
+func (e *RegistryNotFoundError) Error() string {
+	msg := fmt.Sprintf("registry %s not found in auth config", e.Host)
+	if len(e.AttemptedKeys) > 0 {
+		msg += fmt.Sprintf(" (tried %s)", strings.Join(e.AttemptedKeys, ", "))
+	}
+	if len(e.PortMismatchKeys) > 0 {
+		msg += fmt.Sprintf("; port mismatch: %s for the same host on a different port, "+
+			"the image URL and the auths key must use the same port", strings.Join(e.PortMismatchKeys, ", "))
+	}
+	return msg
+}
+
+// portMismatchKeys returns, in sorted order, the auths keys naming the same
+// host as registryHost on a different port.
+func portMismatchKeys(cfg dockercfg.Config, registryHost string) []string {
+	name, port := splitRegistryPort(withoutDefaultPort(toPunycode(registryHost)))
+	var keys []string
+	for _, key := range sortedKeys(cfg.AuthConfigs) {
+		keyName, keyPort := splitRegistryPort(withoutDefaultPort(toPunycode(normalizeAuthKey(key))))
+		if strings.EqualFold(keyName, name) && keyPort != port {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// splitRegistryPort splits host into its name and port, the port being empty
+// when there is none.
+func splitRegistryPort(host string) (string, string) {
+	if name, port, err := net.SplitHostPort(host); err == nil {
+		return name, port
+	}
+	return host, ""
+}
+
+// CanAuthenticate reports whether the secret holds credentials for the
+// registry hosting the image, without returning the credentials. It is meant
+// for policy checks that do not need to handle them. A registry missing from
+// the secret yields false without an error; an error is returned when the
+// image URL or the secret cannot be parsed, or when the matching credentials
+// are corrupted, for example ErrMissingDataKey, ErrTruncatedData,
+// ErrCredentialsNotUTF8 or ErrCredentialsControlChars.
+func CanAuthenticate(secret *corev1.Secret, imageURL string) (bool, error) {
+	_, err := ResolveRegistryCredentials(secret, imageURL, ExtractOptions{})
+	var notFound *RegistryNotFoundError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &notFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// CredentialHelperForImage returns the credential helper the Docker config in
+// the secret names, through credHelpers, for the registry hosting the image.
+// Credential helpers cannot be run by the operator, but their presence tells
+// that credentials are provided out of band. An empty string is returned when
+// no helper covers the registry.
+func CredentialHelperForImage(secret *corev1.Secret, imageURL string) (string, error) {
+	if secret == nil {
+		return "", errors.New("secret is nil")
+	}
+	cfg, _, err := parseDockerConfig(secret, ExtractOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.CredentialHelpers) == 0 {
+		return "", nil
+	}
 	registryHost, err := extractRegistryHost(imageURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to extract registry host from image URL: %w", err)
 	}
 
-	// Use dockercfg library to parse Docker config
+	// Reuse the auths key matching rules for the credHelpers keys.
+	helperKeys := dockercfg.Config{AuthConfigs: make(map[string]dockercfg.AuthConfig, len(cfg.CredentialHelpers))}
+	for key := range cfg.CredentialHelpers {
+		helperKeys.AuthConfigs[key] = dockercfg.AuthConfig{}
+	}
+	key, _, found := findAuthConfig(helperKeys, registryHost, ExtractOptions{}.normalizeHost, nil)
+	if !found {
+		return "", nil
+	}
+	return cfg.CredentialHelpers[key], nil
+}
+
+// imagePathPrefix returns the first path segment after the host in the image
+// URL, or an empty string if there is none.
+func imagePathPrefix(imageURL string) string {
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return ""
+	}
+	prefix, rest, found := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if !found || rest == "" {
+		// A single segment is the repository itself, not a prefix.
+		return ""
+	}
+	return prefix
+}
+
+// authKeyPathPrefix splits an auths key into its normalized host and the
+// first path segment, ignoring the "v1" and "v2" API version suffixes of
+// legacy keys such as "https://index.docker.io/v1/".
+func authKeyPathPrefix(key string) (string, string) {
+	trimmed := key
+	if _, rest, found := strings.Cut(trimmed, "://"); found {
+		trimmed = rest
+	}
+	trimmed, _, _ = strings.Cut(trimmed, "?")
+	trimmed, _, _ = strings.Cut(trimmed, "#")
+	_, path, _ := strings.Cut(trimmed, "/")
+	prefix, _, _ := strings.Cut(path, "/")
+	if prefix == "v1" || prefix == "v2" {
+		prefix = ""
+	}
+	return toPunycode(normalizeAuthKey(key)), prefix
+}
+
+// findPathPrefixAuthConfig looks for an auths key of the form "host/prefix"
+// for the registry host and image path prefix.
+func findPathPrefixAuthConfig(cfg dockercfg.Config, registryHost, prefix string, attempts *keyAttempts) (string, dockercfg.AuthConfig, bool) {
+	if prefix == "" {
+		return "", dockercfg.AuthConfig{}, false
+	}
+	attempts.add(registryHost + "/" + prefix)
+	asciiHost := toPunycode(registryHost)
+	for _, key := range sortedKeys(cfg.AuthConfigs) {
+		if host, keyPrefix := authKeyPathPrefix(key); host == asciiHost && keyPrefix == prefix {
+			return key, cfg.AuthConfigs[key], true
+		}
+	}
+	return "", dockercfg.AuthConfig{}, false
+}
+
+// withoutPathPrefixKeys returns a copy of the config without path-qualified
+// auths keys, so that they cannot match images under another prefix.
+func withoutPathPrefixKeys(cfg dockercfg.Config) dockercfg.Config {
+	filtered := cfg
+	filtered.AuthConfigs = map[string]dockercfg.AuthConfig{}
+	for key, auth := range cfg.AuthConfigs {
+		if _, prefix := authKeyPathPrefix(key); prefix == "" {
+			filtered.AuthConfigs[key] = auth
+		}
+	}
+	return filtered
+}
+
+// ErrCredentialsNotUTF8 is returned when the stored credentials are not valid
+// UTF-8, which usually means the secret data is corrupted.
+var ErrCredentialsNotUTF8 = errors.New("credentials are not valid UTF-8")
+
+// ErrCredentialsControlChars is returned when the stored credentials contain
+// control characters, such as NUL bytes or a trailing newline, which the
+// provisioner cannot pass on and usually mean the auth value is corrupted.
+var ErrCredentialsControlChars = errors.New("credentials contain control characters")
+
+// ErrTruncatedData is returned when the Docker config in the secret ends
+// prematurely, which usually means the secret was read while being updated.
+var ErrTruncatedData = errors.New("docker config data is truncated")
+
+// ErrNotDecrypted is returned when the Docker config in the secret looks
+// like ciphertext, which usually means a sealed or SOPS-encrypted secret has
+// not been decrypted yet.
+var ErrNotDecrypted = errors.New("docker config data appears to be encrypted")
+
+// ErrEmptyRegistryHost is returned when an OCI image URL has no registry
+// host, such as "oci:///repo/image:tag".
+var ErrEmptyRegistryHost = errors.New("image URL has an empty registry host")
+
+// ErrEmptyAuthConfig is returned, together with a RegistryNotFoundError, when
+// the Docker config has no auths entries, such as a secret holding "{}" or
+// {"auths": {}}, nor credential helpers, so no registry is configured.
+var ErrEmptyAuthConfig = errors.New("docker config has no registries configured")
+
+// ErrNoCredentials is returned, together with a RegistryNotFoundError, when
+// the auths entry matching the registry holds no credentials, such as an
+// entry with only an email field.
+var ErrNoCredentials = errors.New("auths entry has no credentials")
+
+// ErrAmbiguousRegistryEntry is returned when the auths keys for the registry
+// differ only by case and hold different credentials, so it is unclear which
+// entry is meant.
+var ErrAmbiguousRegistryEntry = errors.New("ambiguous registry entry")
+
+// ErrInvalidCredentialFormat is returned when ExtractOptions.CredentialFormat
+// is not a template assembling the username and password.
+var ErrInvalidCredentialFormat = errors.New("invalid credential format")
+
+// ErrMissingDataKey is returned when the secret holds no Docker config under
+// any of the keys it is looked up in.
+var ErrMissingDataKey = errors.New("docker config data key missing")
+
+// ValidateCredentialFormat checks that format, a template for
+// ExtractOptions.CredentialFormat, holds exactly two %s verbs using the
+// username and the password once each, either in order or through explicit
+// argument indexes such as "%[2]s". A literal percent sign is written "%%".
+// Any other verb, flag or width is rejected, so that a template cannot
+// silently produce malformed credentials.
+func ValidateCredentialFormat(format string) error {
+	var used []int
+	next := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		arg := next
+		if i < len(format) && format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return fmt.Errorf("%w %q: unterminated argument index", ErrInvalidCredentialFormat, format)
+			}
+			n, err := strconv.Atoi(format[i+1 : i+end])
+			if err != nil {
+				return fmt.Errorf("%w %q: invalid argument index", ErrInvalidCredentialFormat, format)
+			}
+			arg = n
+			i += end + 1
+		}
+		if i >= len(format) || format[i] != 's' {
+			return fmt.Errorf("%w %q: only %%s verbs are supported", ErrInvalidCredentialFormat, format)
+		}
+		used = append(used, arg)
+		next = arg + 1
+	}
+	slices.Sort(used)
+	if !slices.Equal(used, []int{1, 2}) {
+		return fmt.Errorf("%w %q: the username and the password must each be used once", ErrInvalidCredentialFormat, format)
+	}
+	return nil
+}
+
+// credentialsFromAuthConfig returns the username and password stored in an
+// auths entry. If the username is empty, the password is an identity token.
+// When the entry has both, the auth field wins over the username and
+// password if preferAuthField is set.
+func credentialsFromAuthConfig(auth dockercfg.AuthConfig, preferAuthField bool) (string, string, error) {
+	username, password, err := rawCredentialsFromAuthConfig(auth, preferAuthField)
+	if err != nil {
+		return "", "", err
+	}
+	if !utf8.ValidString(username) || !utf8.ValidString(password) {
+		return "", "", ErrCredentialsNotUTF8
+	}
+	if strings.ContainsFunc(username, unicode.IsControl) || strings.ContainsFunc(password, unicode.IsControl) {
+		return "", "", ErrCredentialsControlChars
+	}
+	return username, password, nil
+}
+
+func rawCredentialsFromAuthConfig(auth dockercfg.AuthConfig, preferAuthField bool) (string, string, error) {
+	if auth.IdentityToken != "" {
+		return "", auth.IdentityToken, nil
+	}
+
+	if preferAuthField && auth.Auth != "" {
+		return dockercfg.DecodeBase64Auth(auth)
+	}
+
+	if auth.Username != "" && auth.Password != "" {
+		return auth.Username, auth.Password, nil
+	}
+
+	return dockercfg.DecodeBase64Auth(auth)
+}
+
+// parseDockerConfig decodes the Docker config stored in the secret, as
+// located by dockerConfigAuths. It also returns the auths keys whose value
+// was a string rather than an object, which are tolerated but indicate a
+// malformed config.
+func parseDockerConfig(secret *corev1.Secret, opts ExtractOptions) (dockercfg.Config, []string, error) {
+	cfg, format, raw, err := dockerConfigAuths(secret, opts)
+	if err != nil || raw == nil {
+		return cfg, nil, err
+	}
+	auths, stringKeys, err := decodeAuthConfigMap(raw)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+	cfg.AuthConfigs = auths
+	return cfg, stringKeys, nil
+}
+
+// dockerConfigAuths locates the Docker config stored in the secret, trying
+// the dockerconfigjson key first, then the legacy dockercfg key and finally,
+// if enabled, any other key holding a Docker config. It returns the config
+// with every field but the auths decoded, the name of the format found, and
+// the auths entries undecoded, so that credential resolution and auditing
+// share the same keys and layouts. The auths are nil when the config has
+// none.
+//
+// The dockerconfigjson data may have the auths object at the top level, as
+// in {"auths": {...}}, possibly next to other fields such as an apiVersion, or
+// wrapped one level down, as in {"config": {"auths": {...}}}, as emitted by
+// some external tools. A wrapper is only searched when there is no top-level
+// auths object. The dockercfg data is either a bare auths map, from which the
+// Docker CLI config keys and values that cannot be auths entries are
+// dropped, or a config with an auths object.
+func dockerConfigAuths(secret *corev1.Secret, opts ExtractOptions) (dockercfg.Config, string, map[string]json.RawMessage, error) {
 	var cfg dockercfg.Config
-	var data []byte
-	var ok bool
 
 	// Try parsing as dockerconfigjson format first (newer format)
-	if data, ok = secret.Data[corev1.DockerConfigJsonKey]; ok {
-		if parseErr := json.Unmarshal(data, &cfg); parseErr != nil {
-			return "", fmt.Errorf("failed to parse dockerconfigjson: %w", parseErr)
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		const format = "dockerconfigjson"
+		cfg, raw, err := dockerConfigJSONAuths(data, opts)
+		if err != nil {
+			return cfg, format, nil, fmt.Errorf("failed to parse %s: %w", format, err)
 		}
-	} else if data, ok = secret.Data[corev1.DockerConfigKey]; ok {
+		return cfg, format, raw, nil
+	}
+
+	if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
 		// Try parsing as dockercfg format (legacy format) - it's just the AuthConfigs map
-		if parseErr := json.Unmarshal(data, &cfg.AuthConfigs); parseErr != nil {
-			return "", fmt.Errorf("failed to parse dockercfg: %w", parseErr)
+		const format = "dockercfg"
+		raw, err := legacyAuthEntries(opts.jsonFromYAML(data))
+		if err != nil {
+			return cfg, format, nil, fmt.Errorf("failed to parse %s: %w", format, checkEncrypted(data, checkTruncated(data, err)))
+		}
+		return cfg, format, raw, nil
+	}
+
+	if opts.ScanAllKeys {
+		if found, raw, ok := scanForDockerConfig(secret, opts); ok {
+			return found, "docker config", raw, nil
+		}
+	}
+
+	return cfg, "", nil, fmt.Errorf("%w: secret does not contain %s or %s key", ErrMissingDataKey, corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
+}
+
+// dockerConfigJSONAuths decodes dockerconfigjson data, as described for
+// dockerConfigAuths, returning the config with every field but the auths
+// decoded and the auths entries undecoded.
+func dockerConfigJSONAuths(data []byte, opts ExtractOptions) (dockercfg.Config, map[string]json.RawMessage, error) {
+	var cfg dockercfg.Config
+	data = opts.jsonFromYAML(data)
+	var top struct {
+		Auths json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &top); err != nil {
+		return cfg, nil, checkEncrypted(data, checkTruncated(data, err))
+	}
+	// Any error left stems from the auths entries, which are decoded by the
+	// caller; the other fields are decoded regardless.
+	_ = json.Unmarshal(data, &cfg)
+	cfg.AuthConfigs = nil
+
+	auths := top.Auths
+	if auths == nil || string(bytes.TrimSpace(auths)) == "null" {
+		wrapped, ok := wrappedAuths(data)
+		if !ok {
+			return cfg, nil, nil
+		}
+		auths = wrapped
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(auths, &raw); err != nil {
+		return cfg, nil, err
+	}
+	return cfg, raw, nil
+}
+
+// wrappedAuths returns the auths object nested one level down in the Docker
+// config data, under the first key, in sorted order, whose value holds one.
+func wrappedAuths(data []byte) (json.RawMessage, bool) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, false
+	}
+	for _, key := range sortedKeys(top) {
+		var wrapper struct {
+			Auths json.RawMessage `json:"auths"`
+		}
+		if json.Unmarshal(top[key], &wrapper) == nil && len(wrapper.Auths) > 0 && wrapper.Auths[0] == '{' {
+			return wrapper.Auths, true
+		}
+	}
+	return nil, false
+}
+
+// jsonFromYAML converts data that is not valid JSON to JSON, if it parses as
+// YAML and YAMLFallback is enabled. Otherwise data is returned unchanged, so
+// that the JSON parse error is reported.
+func (o ExtractOptions) jsonFromYAML(data []byte) []byte {
+	if !o.YAMLFallback || json.Valid(data) {
+		return data
+	}
+	converted, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return data
+	}
+	return converted
+}
+
+// dockerCLIConfigKeys are top-level keys of a Docker CLI config.json that
+// never name a registry.
+var dockerCLIConfigKeys = []string{
+	"aliases",
+	"auths",
+	"configFormat",
+	"credHelpers",
+	"credsStore",
+	"currentContext",
+	"detachKeys",
+	"experimental",
+	"features",
+	"HttpHeaders",
+	"imagesFormat",
+	"networksFormat",
+	"nodesFormat",
+	"plugins",
+	"pluginsFormat",
+	"proxies",
+	"psFormat",
+	"secretFormat",
+	"serviceInspectFormat",
+	"servicesFormat",
+	"stackOrchestrator",
+	"statsFormat",
+	"tasksFormat",
+	"volumesFormat",
+}
+
+// decodeLegacyAuthConfigs decodes a legacy dockercfg document, which is a
+// flat map of registries. Full config.json files are sometimes stored under
+// the legacy key instead, so a nested "auths" object is used when present,
+// and top-level entries that are not auth entries, such as "HttpHeaders" or
+// "psFormat", are skipped rather than read as registries.
+func decodeLegacyAuthConfigs(data []byte) (map[string]dockercfg.AuthConfig, []string, error) {
+	raw, err := legacyAuthEntries(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeAuthConfigMap(raw)
+}
+
+// legacyAuthEntries returns the undecoded auths entries of a legacy dockercfg
+// document. See decodeLegacyAuthConfigs.
+func legacyAuthEntries(data []byte) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if nested, ok := raw["auths"]; ok && bytes.HasPrefix(bytes.TrimSpace(nested), []byte("{")) {
+		var auths map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &auths); err != nil {
+			return nil, err
+		}
+		return auths, nil
+	}
+
+	for key, value := range raw {
+		if slices.Contains(dockerCLIConfigKeys, key) || !looksLikeAuthEntry(value) {
+			delete(raw, key)
 		}
-	} else {
-		return "", fmt.Errorf("secret does not contain %s or %s key", corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
 	}
+	return raw, nil
+}
 
-	// Get credentials for the registry using the library's built-in resolution
-	// Use ResolveRegistryHost to handle Docker Hub resolution (docker.io -> index.docker.io)
-	resolvedHost := dockercfg.ResolveRegistryHost(registryHost)
-	username, password, err := cfg.GetRegistryCredentials(resolvedHost)
+// looksLikeAuthEntry reports whether value can be an auths entry: a string,
+// tolerated as the auth field, or an object with at least one auth entry key.
+func looksLikeAuthEntry(value json.RawMessage) bool {
+	value = bytes.TrimSpace(value)
+	if bytes.HasPrefix(value, []byte(`"`)) {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(value, &fields) != nil {
+		return false
+	}
+	for key := range fields {
+		if slices.Contains(knownAuthEntryKeys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeAuthConfigMap decodes the values of an auths map, tolerating entries
+// whose value is a "username:password" string, optionally base64-encoded,
+// instead of an object. The keys of such entries are returned in sorted
+// order.
+func decodeAuthConfigMap(raw map[string]json.RawMessage) (map[string]dockercfg.AuthConfig, []string, error) {
+	auths := make(map[string]dockercfg.AuthConfig, len(raw))
+	var stringKeys []string
+	for _, key := range sortedKeys(raw) {
+		var value string
+		if bytes.HasPrefix(bytes.TrimSpace(raw[key]), []byte(`"`)) && json.Unmarshal(raw[key], &value) == nil {
+			auths[key] = authConfigFromString(value)
+			stringKeys = append(stringKeys, key)
+			continue
+		}
+		var auth dockercfg.AuthConfig
+		if err := json.Unmarshal(raw[key], &auth); err != nil {
+			return nil, nil, err
+		}
+		auths[key] = auth
+	}
+	return auths, stringKeys, nil
+}
+
+// authConfigFromString interprets a string-valued auths entry as the auth
+// field. Both the usual base64 encoding and a plain "username:password" are
+// accepted.
+func authConfigFromString(value string) dockercfg.AuthConfig {
+	if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && strings.Contains(string(decoded), ":") {
+		return dockercfg.AuthConfig{Auth: value}
+	}
+	if strings.Contains(value, ":") {
+		return dockercfg.AuthConfig{Auth: base64.StdEncoding.EncodeToString([]byte(value))}
+	}
+	return dockercfg.AuthConfig{Auth: value}
+}
+
+// checkTruncated marks a JSON decoding error caused by the data ending
+// prematurely with ErrTruncatedData.
+func checkTruncated(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		(errors.As(err, &syntaxErr) && syntaxErr.Offset >= int64(len(data))) {
+		return fmt.Errorf("%w: %w", ErrTruncatedData, err)
+	}
+	return err
+}
+
+// checkEncrypted marks a JSON decoding error for data that looks like
+// ciphertext rather than text with ErrNotDecrypted.
+func checkEncrypted(data []byte, err error) error {
+	if looksEncrypted(data) {
+		return fmt.Errorf("%w: %w", ErrNotDecrypted, err)
+	}
+	return err
+}
+
+// looksEncrypted reports whether data is binary, as ciphertext is, or a SOPS
+// encrypted value. JSON, YAML and base64 data are always printable text.
+func looksEncrypted(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return false
+	}
+	if bytes.HasPrefix(trimmed, []byte("ENC[")) || !utf8.Valid(trimmed) {
+		return true
+	}
+	return bytes.ContainsFunc(trimmed, func(r rune) bool {
+		return unicode.IsControl(r) && !unicode.IsSpace(r)
+	})
+}
+
+// scanForDockerConfig looks through all data values of the secret, in sorted
+// key order so the result is deterministic, and returns the first one that
+// parses, in any of the layouts accepted for the dockerconfigjson key, as a
+// Docker config with at least one auth entry, along with its undecoded auths
+// entries.
+func scanForDockerConfig(secret *corev1.Secret, opts ExtractOptions) (dockercfg.Config, map[string]json.RawMessage, bool) {
+	for _, key := range sortedKeys(secret.Data) {
+		cfg, raw, err := dockerConfigJSONAuths(secret.Data[key], opts)
+		if err != nil || len(raw) == 0 {
+			continue
+		}
+		if _, _, err := decodeAuthConfigMap(raw); err != nil {
+			continue
+		}
+		return cfg, raw, true
+	}
+	return dockercfg.Config{}, nil, false
+}
+
+// RegistryHostForImage returns the registry host, including any port, of an
+// oci:// image URL.
+func RegistryHostForImage(imageURL string) (string, error) {
+	return extractRegistryHost(imageURL)
+}
+
+// ResolveRegistryHost returns the registry whose credentials apply to the
+// image. It is the host the image is pulled from, as returned by
+// PullRegistryHost, unless that host is one of opts.UpstreamMirrors for the
+// upstream registry named by an "ns" query parameter, as appended by
+// containerd to pulls through a mirror, such as
+// "oci://mirror.example.com/library/ubuntu?ns=docker.io".
+func ResolveRegistryHost(imageURL string, opts ExtractOptions) (string, error) {
+	pullHost, err := PullRegistryHost(imageURL, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to get credentials for registry %s: %w", registryHost, err)
+		return "", err
 	}
+	if upstream := upstreamRegistry(imageURL); upstream != "" && isUpstreamMirror(pullHost, upstream, opts.UpstreamMirrors) {
+		return upstream, nil
+	}
+	return pullHost, nil
+}
 
-	if username == "" && password == "" {
-		// Empty credentials means the registry was not found in the config
-		return "", fmt.Errorf("registry %s not found in auth config", registryHost)
+// PullRegistryHost is like RegistryHostForImage but resolves short image
+// references against opts.DefaultRegistry. It ignores any "ns" query
+// parameter, returning the host the image is actually pulled from.
+func PullRegistryHost(imageURL string, opts ExtractOptions) (string, error) {
+	if opts.DefaultRegistry != "" && isShortImageReference(imageURL) {
+		return opts.DefaultRegistry, nil
 	}
+	return extractRegistryHost(imageURL)
+}
 
-	// Return credentials in the format expected by Ironic (base64-encoded "username:password")
-	credentials := fmt.Sprintf("%s:%s", username, password)
-	return base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+// IsUpstreamMirror reports whether host is configured in opts.UpstreamMirrors
+// as a mirror of the upstream registry.
+func IsUpstreamMirror(host, upstream string, opts ExtractOptions) bool {
+	return isUpstreamMirror(host, upstream, opts.UpstreamMirrors)
+}
+
+// isUpstreamMirror reports whether pullHost is configured in mirrors as a
+// mirror of the upstream registry.
+func isUpstreamMirror(pullHost, upstream string, mirrors map[string][]string) bool {
+	for registry, patterns := range mirrors {
+		if registry != upstream && !(IsDockerHubHost(registry) && IsDockerHubHost(upstream)) {
+			continue
+		}
+		for _, pattern := range patterns {
+			if RegistryMatches(pullHost, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upstreamRegistry returns the value of the "ns" query parameter of the
+// image URL, or an empty string if it has none.
+func upstreamRegistry(imageURL string) string {
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(parsed.Query().Get("ns"))
+}
+
+// isShortImageReference reports whether the first component of the OCI image
+// URL is part of the repository name rather than a registry host. The URL is
+// not parsed as such since a short reference like "oci://ubuntu:22.04" is not
+// a valid URL.
+func isShortImageReference(imageURL string) bool {
+	scheme, rest, found := strings.Cut(imageURL, "://")
+	if !found || !strings.EqualFold(scheme, "oci") {
+		return false
+	}
+	first, path, _ := strings.Cut(rest, "/")
+	if first == "" {
+		return false
+	}
+	if strings.Trim(path, "/") == "" {
+		// A single component is a repository, as in "ubuntu:22.04", unless
+		// the part before any tag or port is a host name, as in
+		// "registry.example.com:5000". A colon alone does not tell a port
+		// from a tag there.
+		name, _, _ := strings.Cut(first, ":")
+		name, _, _ = strings.Cut(name, "@")
+		return name != "localhost" && !strings.Contains(name, ".")
+	}
+	return first != "localhost" && !strings.ContainsAny(first, ".:")
+}
+
+// RegistryMatches reports whether registryHost is covered by pattern. A
+// pattern of the form "*.example.com" matches any subdomain of example.com
+// but not example.com itself. A pattern without a port matches the host on
+// any port, while a pattern with a port only matches that port. All Docker
+// Hub aliases are treated as the same registry.
+func RegistryMatches(registryHost, pattern string) bool {
+	registryHost = strings.ToLower(registryHost)
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return false
+	}
+
+	if IsDockerHubHost(registryHost) && IsDockerHubHost(pattern) {
+		return true
+	}
+
+	if _, _, err := net.SplitHostPort(pattern); err != nil {
+		// No port in the pattern, compare host names only
+		if host, _, splitErr := net.SplitHostPort(registryHost); splitErr == nil {
+			registryHost = host
+		}
+	}
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(registryHost, "."+suffix)
+	}
+	return registryHost == pattern
 }
 
 // extractRegistryHost extracts the registry hostname from an OCI image URL.
@@ -78,7 +1238,7 @@ func extractRegistryHost(imageURL string) (string, error) {
 	}
 
 	if parsed.Host == "" {
-		return "", fmt.Errorf("failed to extract hostname from image URL: %s", imageURL)
+		return "", fmt.Errorf("failed to extract hostname from image URL: %w: %s", ErrEmptyRegistryHost, imageURL)
 	}
 
 	return parsed.Host, nil
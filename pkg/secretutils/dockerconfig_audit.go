@@ -0,0 +1,88 @@
+package secretutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cpuguy83/dockercfg"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegistryStatus reports whether a single auths entry holds usable
+// credentials.
+type RegistryStatus struct {
+	// Host is the auths key as stored in the secret.
+	Host string
+	// OK is true when the entry holds complete, usable credentials.
+	OK bool
+	// Reason explains why the entry is not usable. It is empty when OK.
+	Reason string
+}
+
+// AuditDockerConfig checks every auths entry of the Docker config in the
+// secret, for pre-flight linting. Unlike credential resolution, a broken
+// entry does not stop the audit: it is reported with OK set to false. The
+// statuses are sorted by key. An error is returned only when the secret
+// holds no Docker config or the config as a whole cannot be parsed.
+func AuditDockerConfig(secret *corev1.Secret) ([]RegistryStatus, error) {
+	return AuditDockerConfigWithOptions(secret, ExtractOptions{})
+}
+
+// AuditDockerConfigWithOptions is like AuditDockerConfig but locates the
+// Docker config as credential resolution with the options does, such as in
+// any key or as YAML.
+func AuditDockerConfigWithOptions(secret *corev1.Secret, opts ExtractOptions) ([]RegistryStatus, error) {
+	if secret == nil {
+		return nil, errors.New("secret is nil")
+	}
+	_, _, entries, err := dockerConfigAuths(secret, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]RegistryStatus, 0, len(entries))
+	for _, key := range sortedKeys(entries) {
+		status := RegistryStatus{Host: key}
+		if reason := auditAuthEntry(entries[key]); reason != "" {
+			status.Reason = reason
+		} else {
+			status.OK = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// auditAuthEntry returns why the raw auths entry is not usable, or an empty
+// string if it is.
+func auditAuthEntry(raw json.RawMessage) string {
+	var auth dockercfg.AuthConfig
+	var value string
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`)) && json.Unmarshal(raw, &value) == nil {
+		auth = authConfigFromString(value)
+	} else if err := json.Unmarshal(raw, &auth); err != nil {
+		return fmt.Sprintf("malformed entry: %v", err)
+	}
+
+	if auth.Auth == "" && auth.IdentityToken == "" {
+		switch {
+		case auth.Username == "" && auth.Password == "":
+			return "entry holds no credentials"
+		case auth.Password == "":
+			return "entry has a username but no password"
+		case auth.Username == "":
+			return "entry has a password but no username"
+		}
+	}
+
+	username, password, err := credentialsFromAuthConfig(auth, false)
+	if err != nil {
+		return fmt.Sprintf("invalid credentials: %v", err)
+	}
+	if username == "" && password == "" {
+		return "entry holds no credentials"
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package secretutils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAuditDockerConfig(t *testing.T) {
+	config := `{"auths": {
+		"valid.example.com": {"username": "testuser", "password": "testpass"},
+		"authonly.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+		"string.example.com": "testuser:testpass",
+		"token.example.com": {"identitytoken": "refresh-token"},
+		"empty.example.com": {},
+		"nopassword.example.com": {"username": "testuser"},
+		"badauth.example.com": {"auth": "not base64!"},
+		"malformed.example.com": 42
+	}}`
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(config)},
+	}
+
+	statuses, err := AuditDockerConfig(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var hosts []string
+	byHost := map[string]RegistryStatus{}
+	for _, status := range statuses {
+		hosts = append(hosts, status.Host)
+		byHost[status.Host] = status
+	}
+	expectedHosts := []string{
+		"authonly.example.com",
+		"badauth.example.com",
+		"empty.example.com",
+		"malformed.example.com",
+		"nopassword.example.com",
+		"string.example.com",
+		"token.example.com",
+		"valid.example.com",
+	}
+	if !reflect.DeepEqual(hosts, expectedHosts) {
+		t.Fatalf("expected statuses for %v in order, got %v", expectedHosts, hosts)
+	}
+
+	for _, host := range []string{"valid.example.com", "authonly.example.com", "string.example.com", "token.example.com"} {
+		if status := byHost[host]; !status.OK || status.Reason != "" {
+			t.Errorf("expected %s to be usable, got %+v", host, status)
+		}
+	}
+	broken := map[string]string{
+		"empty.example.com":      "no credentials",
+		"nopassword.example.com": "no password",
+		"badauth.example.com":    "invalid credentials",
+		"malformed.example.com":  "malformed entry",
+	}
+	for host, reason := range broken {
+		status := byHost[host]
+		if status.OK || !strings.Contains(status.Reason, reason) {
+			t.Errorf("expected %s to be reported with %q, got %+v", host, reason, status)
+		}
+	}
+}
+
+func TestAuditDockerConfig_LegacyFullConfig(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockercfg,
+		Data: map[string][]byte{corev1.DockerConfigKey: []byte(`{
+			"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="},
+			"HttpHeaders": {"User-Agent": "Docker-Client/1.13.1 (linux)"},
+			"psFormat": "table {{.ID}}"
+		}`)},
+	}
+
+	statuses, err := AuditDockerConfig(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []RegistryStatus{{Host: "registry.example.com", OK: true}}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %+v, got %+v", expected, statuses)
+	}
+}
+
+func TestAuditDockerConfig_InvalidSecret(t *testing.T) {
+	if _, err := AuditDockerConfig(&corev1.Secret{}); !errors.Is(err, ErrMissingDataKey) {
+		t.Errorf("expected ErrMissingDataKey, got %v", err)
+	}
+
+	truncated := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"auth": "dGVz`)},
+	}
+	if _, err := AuditDockerConfig(truncated); !errors.Is(err, ErrTruncatedData) {
+		t.Errorf("expected ErrTruncatedData, got %v", err)
+	}
+}
+
+// TestAuditDockerConfig_Layouts tests that the audit finds the auths entries
+// in every layout credential resolution accepts.
+func TestAuditDockerConfig_Layouts(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string][]byte
+		opts ExtractOptions
+	}{
+		{
+			name: "wrapped auths",
+			data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+				`{"config": {"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}}`)},
+		},
+		{
+			name: "YAML",
+			data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+				"auths:\n  registry.example.com:\n    auth: dGVzdHVzZXI6dGVzdHBhc3M=\n")},
+			opts: ExtractOptions{YAMLFallback: true},
+		},
+		{
+			name: "nonstandard key",
+			data: map[string][]byte{"config.json": []byte(
+				`{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)},
+			opts: ExtractOptions{ScanAllKeys: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, err := AuditDockerConfigWithOptions(&corev1.Secret{Data: tt.data}, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := []RegistryStatus{{Host: "registry.example.com", OK: true}}
+			if !reflect.DeepEqual(statuses, expected) {
+				t.Errorf("expected %+v, got %+v", expected, statuses)
+			}
+		})
+	}
+}
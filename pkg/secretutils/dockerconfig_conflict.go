@@ -0,0 +1,71 @@
+package secretutils
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CredentialConflict describes a registry for which a per-host secret and a
+// global secret both hold credentials, with different values.
+type CredentialConflict struct {
+	// RegistryHost is the registry both secrets hold credentials for.
+	RegistryHost string
+	// HostSecret and GlobalSecret identify the two secrets as
+	// "namespace/name".
+	HostSecret   string
+	GlobalSecret string
+	// HostAuthKey and GlobalAuthKey are the auths entries matched in each
+	// secret.
+	HostAuthKey   string
+	GlobalAuthKey string
+}
+
+// Winner returns the secret whose credentials are used. The per-host secret
+// always takes precedence over the global one.
+func (c *CredentialConflict) Winner() string {
+	return c.HostSecret
+}
+
+// Message returns a human readable description of the conflict naming both
+// sources and the winner. It never includes the credentials.
+func (c *CredentialConflict) Message() string {
+	return fmt.Sprintf("registry %s has different credentials in secret %s (key %s) and global secret %s (key %s); using %s",
+		c.RegistryHost, c.HostSecret, c.HostAuthKey, c.GlobalSecret, c.GlobalAuthKey, c.Winner())
+}
+
+// DetectCredentialConflict reports whether the per-host and global secrets
+// both provide credentials for the registry hosting the image and disagree on
+// them. It returns nil when only one of the secrets, or neither, covers the
+// registry, or when both hold the same credentials. An error is returned if
+// either secret cannot be parsed.
+func DetectCredentialConflict(hostSecret, globalSecret *corev1.Secret, imageURL string, opts ExtractOptions) (*CredentialConflict, error) {
+	if hostSecret == nil || globalSecret == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	hostCfg, _, err := parseDockerConfig(hostSecret, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secret %s/%s: %w", hostSecret.Namespace, hostSecret.Name, err)
+	}
+	globalCfg, _, err := parseDockerConfig(globalSecret, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secret %s/%s: %w", globalSecret.Namespace, globalSecret.Name, err)
+	}
+
+	// A registry missing from either secret cannot conflict.
+	hostCreds, hostErr := resolveFromConfig(hostCfg, imageURL, opts)
+	globalCreds, globalErr := resolveFromConfig(globalCfg, imageURL, opts)
+	bothCovered := hostErr == nil && globalErr == nil
+	if !bothCovered || hostCreds.Credentials == globalCreds.Credentials {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &CredentialConflict{
+		RegistryHost:  hostCreds.RegistryHost,
+		HostSecret:    hostSecret.Namespace + "/" + hostSecret.Name,
+		GlobalSecret:  globalSecret.Namespace + "/" + globalSecret.Name,
+		HostAuthKey:   hostCreds.MatchedAuthKey,
+		GlobalAuthKey: globalCreds.MatchedAuthKey,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package secretutils
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDetectCredentialConflict(t *testing.T) {
+	hostSecret := createDockerConfigJSONSecret("host-secret", map[string]map[string]string{
+		"quay.io": {
+			"username": "hostuser",
+			"password": "hostpass",
+		},
+	})
+	conflicting := createDockerConfigJSONSecret("global-secret", map[string]map[string]string{
+		"https://quay.io": {
+			"username": "globaluser",
+			"password": "globalpass",
+		},
+	})
+	identical := createDockerConfigJSONSecret("global-secret", map[string]map[string]string{
+		"quay.io": {
+			"username": "hostuser",
+			"password": "hostpass",
+		},
+	})
+	otherRegistry := createDockerConfigJSONSecret("global-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "globaluser",
+			"password": "globalpass",
+		},
+	})
+
+	tests := []struct {
+		name           string
+		globalSecret   *corev1.Secret
+		expectConflict bool
+	}{
+		{name: "conflicting credentials", globalSecret: conflicting, expectConflict: true},
+		{name: "identical credentials", globalSecret: identical},
+		{name: "registry only in one secret", globalSecret: otherRegistry},
+		{name: "no global secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict, err := DetectCredentialConflict(hostSecret, tt.globalSecret, "oci://quay.io/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.expectConflict {
+				if conflict != nil {
+					t.Errorf("expected no conflict, got %q", conflict.Message())
+				}
+				return
+			}
+			if conflict == nil {
+				t.Fatal("expected a conflict")
+			}
+			if conflict.Winner() != "default/host-secret" {
+				t.Errorf("expected the per-host secret to win, got %q", conflict.Winner())
+			}
+			message := conflict.Message()
+			for _, want := range []string{"quay.io", "default/host-secret", "default/global-secret", "https://quay.io", "using default/host-secret"} {
+				if !strings.Contains(message, want) {
+					t.Errorf("expected message to contain %q, got %q", want, message)
+				}
+			}
+			if strings.Contains(message, "pass") {
+				t.Errorf("message must not reveal credentials: %q", message)
+			}
+		})
+	}
+}
+
+func TestDetectCredentialConflict_InvalidSecret(t *testing.T) {
+	hostSecret := createDockerConfigJSONSecret("host-secret", map[string]map[string]string{
+		"quay.io": {
+			"username": "hostuser",
+			"password": "hostpass",
+		},
+	})
+	broken := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte("{")},
+	}
+
+	if _, err := DetectCredentialConflict(hostSecret, broken, "oci://quay.io/repo/image:tag", ExtractOptions{}); err == nil {
+		t.Error("expected an error for an unparsable global secret")
+	}
+}
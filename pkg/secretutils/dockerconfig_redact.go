@@ -0,0 +1,38 @@
+package secretutils
+
+import "github.com/cpuguy83/dockercfg"
+
+// redactedValue replaces secret values in a RedactedAuthConfig.
+const redactedValue = "REDACTED"
+
+// RedactedAuthConfig is a copy of an auths entry safe for logging: the
+// fields holding secrets are replaced by "REDACTED" when set, so that it
+// still shows which fields were present.
+type RedactedAuthConfig struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Auth          string `json:"auth,omitempty"`
+	Email         string `json:"email,omitempty"`
+	ServerAddress string `json:"serveraddress,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// redactAuthConfig returns a copy of auth with its secrets redacted.
+func redactAuthConfig(auth dockercfg.AuthConfig) *RedactedAuthConfig {
+	redact := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return redactedValue
+	}
+	return &RedactedAuthConfig{
+		Username:      auth.Username,
+		Password:      redact(auth.Password),
+		Auth:          redact(auth.Auth),
+		Email:         auth.Email,
+		ServerAddress: auth.ServerAddress,
+		IdentityToken: redact(auth.IdentityToken),
+		RegistryToken: redact(auth.RegistryToken),
+	}
+}
@@ -0,0 +1,74 @@
+package secretutils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveRegistryCredentials_MatchedAuthConfig(t *testing.T) {
+	config := `{"auths": {"registry.example.com": {
+		"username": "testuser",
+		"password": "testpass",
+		"auth": "dGVzdHVzZXI6dGVzdHBhc3M=",
+		"email": "testuser@example.com"
+	}}}`
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(config)},
+	}
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.MatchedAuthConfig != nil {
+		t.Errorf("expected no matched auth config unless requested, got %+v", creds.MatchedAuthConfig)
+	}
+
+	creds, err = ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag",
+		ExtractOptions{IncludeMatchedAuthConfig: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := RedactedAuthConfig{
+		Username: "testuser",
+		Password: "REDACTED",
+		Auth:     "REDACTED",
+		Email:    "testuser@example.com",
+	}
+	if creds.MatchedAuthConfig == nil || *creds.MatchedAuthConfig != expected {
+		t.Fatalf("expected %+v, got %+v", expected, creds.MatchedAuthConfig)
+	}
+
+	encoded, err := json.Marshal(creds.MatchedAuthConfig)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	for _, secretValue := range []string{"testpass", "dGVzdHVzZXI6dGVzdHBhc3M="} {
+		if strings.Contains(string(encoded), secretValue) {
+			t.Errorf("secret value %q leaked in %s", secretValue, encoded)
+		}
+	}
+}
+
+func TestResolveRegistryCredentials_MatchedAuthConfigIdentityToken(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"identitytoken": "refresh-token"}}}`),
+		},
+	}
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag",
+		ExtractOptions{IncludeMatchedAuthConfig: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := RedactedAuthConfig{IdentityToken: "REDACTED"}
+	if creds.MatchedAuthConfig == nil || *creds.MatchedAuthConfig != expected {
+		t.Errorf("expected %+v, got %+v", expected, creds.MatchedAuthConfig)
+	}
+}
@@ -0,0 +1,142 @@
+package secretutils
+
+import (
+	"encoding/json"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// knownDockerConfigKeys are the top-level keys accepted by
+// ValidateDockerConfigStructure in a dockerconfigjson document.
+var knownDockerConfigKeys = []string{
+	"auths",
+	"credHelpers",
+	"credsStore",
+	"HttpHeaders",
+	"proxies",
+}
+
+// knownAuthEntryKeys are the keys accepted in a single auths entry.
+var knownAuthEntryKeys = []string{
+	"auth",
+	"email",
+	"identitytoken",
+	"password",
+	"registrytoken",
+	"serveraddress",
+	"username",
+}
+
+// ValidateDockerConfigStructure checks that the Docker config stored in the
+// secret strictly follows the expected schema: auths must be an object of
+// objects holding only known string fields, and no unexpected top-level keys
+// may be present. Each violation is reported with its field path.
+func ValidateDockerConfigStructure(secret *corev1.Secret) field.ErrorList {
+	dataPath := field.NewPath("data")
+
+	if data, ok := secret.Data[corev1.DockerConfigJsonKey]; ok {
+		return validateDockerConfigJSON(data, dataPath.Key(corev1.DockerConfigJsonKey))
+	}
+	if data, ok := secret.Data[corev1.DockerConfigKey]; ok {
+		return validateAuthsMap(data, dataPath.Key(corev1.DockerConfigKey))
+	}
+
+	return field.ErrorList{field.Required(dataPath.Key(corev1.DockerConfigJsonKey),
+		"secret must contain "+corev1.DockerConfigJsonKey+" or "+corev1.DockerConfigKey)}
+}
+
+func validateDockerConfigJSON(data []byte, path *field.Path) field.ErrorList {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return field.ErrorList{field.Invalid(path, "", "must be a JSON object: "+err.Error())}
+	}
+
+	var errs field.ErrorList
+	for _, key := range sortedKeys(top) {
+		if !slices.Contains(knownDockerConfigKeys, key) {
+			errs = append(errs, field.NotSupported(path.Child(key), key, knownDockerConfigKeys))
+		}
+	}
+
+	auths, ok := top["auths"]
+	if !ok {
+		return append(errs, field.Required(path.Child("auths"), ""))
+	}
+	errs = append(errs, validateAuthsMap(auths, path.Child("auths"))...)
+
+	if helpers, ok := top["credHelpers"]; ok {
+		var m map[string]string
+		if err := json.Unmarshal(helpers, &m); err != nil {
+			errs = append(errs, field.TypeInvalid(path.Child("credHelpers"), jsonKind(helpers), "must be an object of strings"))
+		}
+	}
+	if store, ok := top["credsStore"]; ok {
+		var s string
+		if err := json.Unmarshal(store, &s); err != nil {
+			errs = append(errs, field.TypeInvalid(path.Child("credsStore"), jsonKind(store), "must be a string"))
+		}
+	}
+	return errs
+}
+
+func validateAuthsMap(data []byte, path *field.Path) field.ErrorList {
+	var auths map[string]json.RawMessage
+	if err := json.Unmarshal(data, &auths); err != nil || auths == nil {
+		return field.ErrorList{field.TypeInvalid(path, jsonKind(data), "must be an object keyed by registry")}
+	}
+
+	var errs field.ErrorList
+	for _, registry := range sortedKeys(auths) {
+		entryPath := path.Key(registry)
+		var entry map[string]json.RawMessage
+		if err := json.Unmarshal(auths[registry], &entry); err != nil || entry == nil {
+			errs = append(errs, field.TypeInvalid(entryPath, jsonKind(auths[registry]), "must be an object"))
+			continue
+		}
+		for _, key := range sortedKeys(entry) {
+			if !slices.Contains(knownAuthEntryKeys, key) {
+				errs = append(errs, field.NotSupported(entryPath.Child(key), key, knownAuthEntryKeys))
+				continue
+			}
+			var s string
+			if err := json.Unmarshal(entry[key], &s); err != nil {
+				errs = append(errs, field.TypeInvalid(entryPath.Child(key), jsonKind(entry[key]), "must be a string"))
+			}
+		}
+	}
+	return errs
+}
+
+// jsonKind describes the type of a JSON value without revealing its content,
+// which may be sensitive.
+func jsonKind(data []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "invalid JSON"
+	}
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package secretutils
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateDockerConfigStructure(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		data           string
+		expectedFields []string
+	}{
+		{
+			name: "valid dockerconfigjson",
+			key:  corev1.DockerConfigJsonKey,
+			data: `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz", "email": "a@b.c"}}, "credHelpers": {"gcr.io": "gcloud"}}`,
+		},
+		{
+			name: "valid legacy dockercfg",
+			key:  corev1.DockerConfigKey,
+			data: `{"registry.example.com": {"username": "user", "password": "pass"}}`,
+		},
+		{
+			name:           "auths as array",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `{"auths": [{"registry.example.com": {"auth": "dXNlcjpwYXNz"}}]}`,
+			expectedFields: []string{"data[.dockerconfigjson].auths"},
+		},
+		{
+			name:           "auths entry as string",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `{"auths": {"registry.example.com": "dXNlcjpwYXNz"}}`,
+			expectedFields: []string{"data[.dockerconfigjson].auths[registry.example.com]"},
+		},
+		{
+			name:           "unexpected top-level key and entry field",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz", "token": "x"}}, "registries": {}}`,
+			expectedFields: []string{"data[.dockerconfigjson].registries", "data[.dockerconfigjson].auths[registry.example.com].token"},
+		},
+		{
+			name:           "non-string entry field",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `{"auths": {"registry.example.com": {"auth": 42}}}`,
+			expectedFields: []string{"data[.dockerconfigjson].auths[registry.example.com].auth"},
+		},
+		{
+			name:           "missing auths",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `{"credsStore": "desktop"}`,
+			expectedFields: []string{"data[.dockerconfigjson].auths"},
+		},
+		{
+			name:           "not an object",
+			key:            corev1.DockerConfigJsonKey,
+			data:           `["auths"]`,
+			expectedFields: []string{"data[.dockerconfigjson]"},
+		},
+		{
+			name:           "legacy dockercfg with wrapper",
+			key:            corev1.DockerConfigKey,
+			data:           `{"registry.example.com": ["user", "pass"]}`,
+			expectedFields: []string{"data[.dockercfg][registry.example.com]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{Data: map[string][]byte{tt.key: []byte(tt.data)}}
+			errs := ValidateDockerConfigStructure(secret)
+
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			if len(fields) != len(tt.expectedFields) {
+				t.Fatalf("expected violations at %v, got %v", tt.expectedFields, errs)
+			}
+			for _, expected := range tt.expectedFields {
+				if !containsField(errs, expected) {
+					t.Errorf("expected violation at %q, got %v", expected, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDockerConfigStructure_DoesNotLeakValues(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": "c3VwZXJzZWNyZXQ="}}`),
+	}}
+	errs := ValidateDockerConfigStructure(secret)
+	if len(errs) == 0 {
+		t.Fatal("expected a violation")
+	}
+	if strings.Contains(errs.ToAggregate().Error(), "c3VwZXJzZWNyZXQ=") {
+		t.Errorf("violation message leaks secret data: %v", errs)
+	}
+}
+
+func containsField(errs field.ErrorList, path string) bool {
+	for _, err := range errs {
+		if err.Field == path {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,11 @@ package secretutils
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -95,6 +100,40 @@ func TestExtractRegistryCredentials(t *testing.T) {
 			imageURL:    "oci://quay.io/repo/image:tag",
 			expectError: false,
 		},
+		{
+			name: "explicit port without repository",
+			secret: createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				"registry.example.com:5000": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			}),
+			imageURL:    "oci://registry.example.com:5000",
+			expectError: false,
+		},
+		{
+			name: "explicit port without repository does not match portless key",
+			secret: createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				"registry.example.com": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			}),
+			imageURL:      "oci://registry.example.com:5000",
+			expectError:   true,
+			errorContains: "registry.example.com:5000 not found",
+		},
+		{
+			name: "helm chart reference",
+			secret: createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				"registry.example.com": {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			}),
+			imageURL:    "oci://registry.example.com/charts/mychart:1.2.3",
+			expectError: false,
+		},
 		{
 			name:          "nil secret",
 			secret:        nil,
@@ -177,6 +216,36 @@ func TestExtractRegistryHost(t *testing.T) {
 			expectedHost: "registry.example.com",
 			expectError:  false,
 		},
+		{
+			name:         "OCI chart reference",
+			imageURL:     "oci://registry.example.com/charts/mychart:1.2.3",
+			expectedHost: "registry.example.com",
+			expectError:  false,
+		},
+		{
+			name:         "OCI artifact reference with digest",
+			imageURL:     "oci://registry.example.com:5000/artifacts/bundle@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expectedHost: "registry.example.com:5000",
+			expectError:  false,
+		},
+		{
+			name:         "OCI URL with port and no repository",
+			imageURL:     "oci://registry.example.com:5000",
+			expectedHost: "registry.example.com:5000",
+			expectError:  false,
+		},
+		{
+			name:         "OCI URL with port and trailing slash",
+			imageURL:     "oci://registry.example.com:5000/",
+			expectedHost: "registry.example.com:5000",
+			expectError:  false,
+		},
+		{
+			name:         "OCI URL with underscore in host",
+			imageURL:     "oci://lab_registry.local:5000/repo/image:tag",
+			expectedHost: "lab_registry.local:5000",
+			expectError:  false,
+		},
 		{
 			name:         "non-OCI URL",
 			imageURL:     "http://example.com/image.iso",
@@ -341,3 +410,2031 @@ func createLegacyDockerCfgSecret(name string, auths map[string]map[string]string
 		},
 	}
 }
+
+func TestExtractRegistryCredentials_ScanAllKeys(t *testing.T) {
+	standard := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	nonstandard := standard.DeepCopy()
+	nonstandard.Data = map[string][]byte{
+		"ca.crt": []byte("-----BEGIN CERTIFICATE-----"),
+		"data":   standard.Data[corev1.DockerConfigJsonKey],
+	}
+	garbage := standard.DeepCopy()
+	garbage.Data = map[string][]byte{
+		"data":  []byte("not json"),
+		"other": []byte(`{"auths": {}}`),
+	}
+	withLayout := func(config string) *corev1.Secret {
+		secret := standard.DeepCopy()
+		secret.Data = map[string][]byte{"config": []byte(config)}
+		return secret
+	}
+
+	tests := []struct {
+		name          string
+		secret        *corev1.Secret
+		opts          ExtractOptions
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "nonstandard key without heuristic",
+			secret:        nonstandard,
+			expectError:   true,
+			errorContains: "does not contain",
+		},
+		{
+			name:   "nonstandard key discovered by heuristic",
+			secret: nonstandard,
+			opts:   ExtractOptions{ScanAllKeys: true},
+		},
+		{
+			name:   "standard key still preferred with heuristic",
+			secret: standard,
+			opts:   ExtractOptions{ScanAllKeys: true},
+		},
+		{
+			name:   "string-valued entry under nonstandard key",
+			secret: withLayout(`{"auths": {"registry.example.com": "testuser:testpass"}}`),
+			opts:   ExtractOptions{ScanAllKeys: true},
+		},
+		{
+			name:   "wrapped auths under nonstandard key",
+			secret: withLayout(`{"data": {"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}}`),
+			opts:   ExtractOptions{ScanAllKeys: true},
+		},
+		{
+			name:   "YAML under nonstandard key",
+			secret: withLayout("auths:\n  registry.example.com:\n    username: testuser\n    password: testpass\n"),
+			opts:   ExtractOptions{ScanAllKeys: true, YAMLFallback: true},
+		},
+		{
+			name:          "no value parses as docker config",
+			secret:        garbage,
+			opts:          ExtractOptions{ScanAllKeys: true},
+			expectError:   true,
+			errorContains: "does not contain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractRegistryCredentialsWithOptions(tt.secret, "oci://registry.example.com/repo/image:tag", tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("expected error to contain %q, got: %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(result)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != "testuser:testpass" {
+				t.Errorf("expected 'testuser:testpass', got %q", string(decoded))
+			}
+		})
+	}
+}
+
+func TestDockerHubAliases(t *testing.T) {
+	documented := []string{
+		"https://index.docker.io/v1/",
+		"index.docker.io",
+		"docker.io",
+		"registry-1.docker.io",
+	}
+
+	aliases := DockerHubAliases()
+	if !slices.Equal(aliases, documented) {
+		t.Errorf("expected aliases %q, got %q", documented, aliases)
+	}
+	for _, alias := range documented {
+		if !IsDockerHubHost(alias) {
+			t.Errorf("expected %q to be recognized as Docker Hub", alias)
+		}
+	}
+	for _, host := range []string{"quay.io", "docker.io.example.com", "hub.docker.com", ""} {
+		if IsDockerHubHost(host) {
+			t.Errorf("expected %q not to be recognized as Docker Hub", host)
+		}
+	}
+
+	aliases[0] = "modified.example.com"
+	if !slices.Equal(DockerHubAliases(), documented) {
+		t.Error("expected modifying the returned aliases to leave the alias set unchanged")
+	}
+}
+
+func TestResolveRegistryCredentials_DockerHubCanonicalization(t *testing.T) {
+	aliases := DockerHubAliases()
+	imageURLs := []string{
+		"oci://docker.io/library/busybox:latest",
+		"oci://index.docker.io/library/busybox:latest",
+		"oci://registry-1.docker.io/library/busybox:latest",
+	}
+
+	for _, alias := range aliases {
+		for _, imageURL := range imageURLs {
+			t.Run(alias+" "+imageURL, func(t *testing.T) {
+				secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+					alias: {
+						"username": "hubuser",
+						"password": "hubpass",
+					},
+				})
+
+				creds, err := ResolveRegistryCredentials(secret, imageURL, ExtractOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if creds.RegistryHost != "docker.io" {
+					t.Errorf("expected RegistryHost %q, got %q", "docker.io", creds.RegistryHost)
+				}
+				if creds.MatchedAuthKey != alias {
+					t.Errorf("expected MatchedAuthKey %q as stored in the secret, got %q", alias, creds.MatchedAuthKey)
+				}
+				if creds.CanonicalAuthKey != "docker.io" {
+					t.Errorf("expected CanonicalAuthKey %q, got %q", "docker.io", creds.CanonicalAuthKey)
+				}
+				decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+				if err != nil {
+					t.Fatalf("credentials are not valid base64: %v", err)
+				}
+				if string(decoded) != "hubuser:hubpass" {
+					t.Errorf("expected 'hubuser:hubpass', got %q", string(decoded))
+				}
+			})
+		}
+	}
+}
+
+func TestResolveRegistryCredentials_CustomDockerHubCanonicalHost(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"docker.io": {
+			"username": "hubuser",
+			"password": "hubpass",
+		},
+	})
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://docker.io/library/busybox:latest",
+		ExtractOptions{DockerHubCanonicalHost: "index.docker.io"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.RegistryHost != "index.docker.io" || creds.CanonicalAuthKey != "index.docker.io" {
+		t.Errorf("expected canonical host index.docker.io, got RegistryHost=%q CanonicalAuthKey=%q",
+			creds.RegistryHost, creds.CanonicalAuthKey)
+	}
+	if creds.MatchedAuthKey != "docker.io" {
+		t.Errorf("expected MatchedAuthKey %q as stored in the secret, got %q", "docker.io", creds.MatchedAuthKey)
+	}
+}
+
+func TestResolveRegistryCredentials_NonDockerHubUnchanged(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"quay.io": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+	})
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://quay.io/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.RegistryHost != "quay.io" || creds.MatchedAuthKey != "quay.io" || creds.CanonicalAuthKey != "quay.io" {
+		t.Errorf("expected quay.io, got RegistryHost=%q MatchedAuthKey=%q CanonicalAuthKey=%q",
+			creds.RegistryHost, creds.MatchedAuthKey, creds.CanonicalAuthKey)
+	}
+}
+
+func TestResolveRegistryCredentials_HarborRobotAccount(t *testing.T) {
+	const (
+		robotUser = "robot$project+ci-puller"
+		robotPass = "s3cr3t+/="
+	)
+
+	authPath := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"harbor.example.com": {
+			"username": robotUser,
+			"password": robotPass,
+		},
+	})
+
+	explicitJSON, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"harbor.example.com": map[string]string{
+				"username": robotUser,
+				"password": robotPass,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+	explicitPath := authPath.DeepCopy()
+	explicitPath.Data[corev1.DockerConfigJsonKey] = explicitJSON
+
+	for name, secret := range map[string]*corev1.Secret{
+		"auth field":              authPath,
+		"username/password field": explicitPath,
+	} {
+		t.Run(name, func(t *testing.T) {
+			creds, err := ResolveRegistryCredentials(secret, "oci://harbor.example.com/project/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if expected := robotUser + ":" + robotPass; string(decoded) != expected {
+				t.Errorf("expected %q, got %q", expected, string(decoded))
+			}
+		})
+	}
+}
+
+func TestRegistryMatches(t *testing.T) {
+	tests := []struct {
+		host     string
+		pattern  string
+		expected bool
+	}{
+		{"registry.example.com", "registry.example.com", true},
+		{"Registry.Example.com", "registry.example.com", true},
+		{"registry.example.com:5000", "registry.example.com", true},
+		{"registry.example.com:5000", "registry.example.com:5000", true},
+		{"registry.example.com:5001", "registry.example.com:5000", false},
+		{"registry.example.com", "registry.example.com:5000", false},
+		{"registry.example.com", "example.com", false},
+		{"registry.example.com", "*.example.com", true},
+		{"a.b.example.com:443", "*.example.com", true},
+		{"example.com", "*.example.com", false},
+		{"evilexample.com", "*.example.com", false},
+		{"index.docker.io", "docker.io", true},
+		{"docker.io", "https://index.docker.io/v1/", true},
+		{"quay.io", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host+" "+tt.pattern, func(t *testing.T) {
+			if result := RegistryMatches(tt.host, tt.pattern); result != tt.expected {
+				t.Errorf("RegistryMatches(%q, %q) = %v, expected %v", tt.host, tt.pattern, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_UnderscoreHost(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"lab_registry.local:5000": {
+			"username": "labuser",
+			"password": "labpass",
+		},
+		"lab-registry.local:5000": {
+			"username": "wronguser",
+			"password": "wrongpass",
+		},
+	})
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://lab_registry.local:5000/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.RegistryHost != "lab_registry.local:5000" || creds.MatchedAuthKey != "lab_registry.local:5000" {
+		t.Errorf("expected host to be matched verbatim, got RegistryHost=%q MatchedAuthKey=%q",
+			creds.RegistryHost, creds.MatchedAuthKey)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+	if err != nil {
+		t.Fatalf("credentials are not valid base64: %v", err)
+	}
+	if string(decoded) != "labuser:labpass" {
+		t.Errorf("expected 'labuser:labpass', got %q", string(decoded))
+	}
+
+	// A key that only differs by normalizing the underscore must not match.
+	hyphenOnly := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"lab-registry.local:5000": {
+			"username": "wronguser",
+			"password": "wrongpass",
+		},
+	})
+	if _, err := ResolveRegistryCredentials(hyphenOnly, "oci://lab_registry.local:5000/repo/image:tag", ExtractOptions{}); err == nil {
+		t.Error("expected no match for a normalized host key")
+	}
+}
+
+func TestResolveRegistryCredentials_InvalidUTF8(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:\xff\xfepass"))
+	data, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]string{"auth": auth},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: data},
+	}
+
+	_, err = ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if !errors.Is(err, ErrCredentialsNotUTF8) {
+		t.Errorf("expected ErrCredentialsNotUTF8, got: %v", err)
+	}
+}
+
+func TestResolveRegistryCredentials_ControlCharacters(t *testing.T) {
+	tests := []struct {
+		name      string
+		decoded   string
+		expectErr bool
+	}{
+		{name: "NUL in password", decoded: "user:pa\x00ss", expectErr: true},
+		{name: "trailing newline", decoded: "user:pass\n", expectErr: true},
+		{name: "escape in username", decoded: "us\x1ber:pass", expectErr: true},
+		{name: "DEL in password", decoded: "user:pass\x7f", expectErr: true},
+		{name: "printable punctuation", decoded: "user:p@ss w0rd!~"},
+		{name: "non-ASCII letters", decoded: "usér:pässwörd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(map[string]interface{}{
+				"auths": map[string]interface{}{
+					"registry.example.com": map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte(tt.decoded))},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to marshal docker config: %v", err)
+			}
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: data},
+			}
+
+			_, err = ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if tt.expectErr && !errors.Is(err, ErrCredentialsControlChars) {
+				t.Errorf("expected ErrCredentialsControlChars, got: %v", err)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentialsForImages(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"quay.io": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+	})
+	images := []string{
+		"oci://quay.io/repo/kernel:tag",
+		"oci://quay.io/repo/initramfs:tag",
+		"oci://registry.example.com/repo/image:tag",
+	}
+
+	result, err := ResolveRegistryCredentialsForImages(secret, images, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Resolved) != 2 {
+		t.Fatalf("expected two resolved images, got %v", result.Resolved)
+	}
+	expected := base64.StdEncoding.EncodeToString([]byte("quayuser:quaypass"))
+	for _, image := range images[:2] {
+		creds, ok := result.Resolved[image]
+		if !ok {
+			t.Fatalf("expected %s to be resolved, got %v", image, result.Resolved)
+		}
+		if creds.Credentials != expected || creds.RegistryHost != "quay.io" {
+			t.Errorf("expected credentials %q for quay.io, got %q for %s", expected, creds.Credentials, creds.RegistryHost)
+		}
+	}
+
+	if len(result.Failed) != 1 {
+		t.Fatalf("expected one failed image, got %v", result.Failed)
+	}
+	failure, ok := result.Failed["oci://registry.example.com/repo/image:tag"]
+	if !ok {
+		t.Fatalf("expected registry.example.com image to fail, got %v", result.Failed)
+	}
+	if !strings.Contains(failure.Error(), "registry.example.com not found") {
+		t.Errorf("unexpected failure: %v", failure)
+	}
+}
+
+func TestResolveRegistryCredentialsForImages_PathPrefixMatching(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.internal/quay": {"username": "quayuser", "password": "quaypass"},
+		"registry.internal/gcr":  {"username": "gcruser", "password": "gcrpass"},
+	})
+	images := map[string]string{
+		"oci://registry.internal/quay/repo/image:tag": "quayuser:quaypass",
+		"oci://registry.internal/gcr/repo/image:tag":  "gcruser:gcrpass",
+	}
+
+	result, err := ResolveRegistryCredentialsForImages(secret, slices.Collect(maps.Keys(images)), ExtractOptions{PathPrefixMatching: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Resolved) != len(images) {
+		t.Fatalf("expected every image to be resolved, got %v", result.Resolved)
+	}
+	for image, credentials := range images {
+		expected := base64.StdEncoding.EncodeToString([]byte(credentials))
+		if creds := result.Resolved[image]; creds == nil || creds.Credentials != expected {
+			t.Errorf("expected credentials %q for %s, got %v", expected, image, creds)
+		}
+	}
+}
+
+func TestResolveRegistryCredentialsForImages_InvalidSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte("not json")},
+	}
+
+	if _, err := ResolveRegistryCredentialsForImages(secret, []string{"oci://quay.io/repo/image:tag"}, ExtractOptions{}); err == nil {
+		t.Error("expected an error for an unparsable secret")
+	}
+}
+
+func TestResolveRegistryCredentials_UserinfoKey(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"svc@registry.example.com": {
+			"username": "svcuser",
+			"password": "svcpass",
+		},
+	})
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.RegistryHost != "registry.example.com" || creds.MatchedAuthKey != "svc@registry.example.com" {
+		t.Errorf("unexpected match: RegistryHost=%q MatchedAuthKey=%q", creds.RegistryHost, creds.MatchedAuthKey)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+	if err != nil {
+		t.Fatalf("credentials are not valid base64: %v", err)
+	}
+	if string(decoded) != "svcuser:svcpass" {
+		t.Errorf("expected 'svcuser:svcpass', got %q", string(decoded))
+	}
+
+	// An exact key takes precedence over a userinfo key.
+	both := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"svc@registry.example.com": {
+			"username": "svcuser",
+			"password": "svcpass",
+		},
+		"registry.example.com": {
+			"username": "exactuser",
+			"password": "exactpass",
+		},
+	})
+	creds, err = ResolveRegistryCredentials(both, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.MatchedAuthKey != "registry.example.com" {
+		t.Errorf("expected the exact key to be used, got %q", creds.MatchedAuthKey)
+	}
+
+	// The userinfo is stripped but the host must still match.
+	if _, err := ResolveRegistryCredentials(secret, "oci://other.example.com/repo/image:tag", ExtractOptions{}); err == nil {
+		t.Error("expected no match for a different host")
+	}
+}
+
+func TestResolveRegistryCredentials_SchemeQualifiedKey(t *testing.T) {
+	auths := map[string]map[string]string{
+		"https://registry.example.com:5000": {
+			"username": "legacyuser",
+			"password": "legacypass",
+		},
+	}
+	secrets := map[string]*corev1.Secret{
+		"dockercfg":        createLegacyDockerCfgSecret("test-secret", auths),
+		"dockerconfigjson": createDockerConfigJSONSecret("test-secret", auths),
+	}
+
+	for name, secret := range secrets {
+		t.Run(name, func(t *testing.T) {
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com:5000/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.RegistryHost != "registry.example.com:5000" || creds.MatchedAuthKey != "https://registry.example.com:5000" {
+				t.Errorf("unexpected match: RegistryHost=%q MatchedAuthKey=%q", creds.RegistryHost, creds.MatchedAuthKey)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != "legacyuser:legacypass" {
+				t.Errorf("expected 'legacyuser:legacypass', got %q", string(decoded))
+			}
+
+			// The port is part of the host and must match.
+			if _, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{}); err == nil {
+				t.Error("expected no match for a different port")
+			}
+		})
+	}
+}
+
+func TestNormalizeAuthKey(t *testing.T) {
+	tests := map[string]string{
+		"registry.example.com":              "registry.example.com",
+		"https://registry.example.com:5000": "registry.example.com:5000",
+		"http://registry.example.com/v1/":   "registry.example.com",
+		"svc@registry.example.com":          "registry.example.com",
+		"https://svc@registry.example.com/": "registry.example.com",
+	}
+	for key, expected := range tests {
+		if got := normalizeAuthKey(key); got != expected {
+			t.Errorf("normalizeAuthKey(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
+
+func TestResolveRegistryCredentials_TruncatedData(t *testing.T) {
+	full := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	data := full.Data[corev1.DockerConfigJsonKey]
+	truncated := full.DeepCopy()
+	truncated.Data[corev1.DockerConfigJsonKey] = data[:len(data)/2]
+
+	_, err := ResolveRegistryCredentials(truncated, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if !errors.Is(err, ErrTruncatedData) {
+		t.Errorf("expected ErrTruncatedData, got: %v", err)
+	}
+
+	malformed := full.DeepCopy()
+	malformed.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths": nope}`)
+	_, err = ResolveRegistryCredentials(malformed, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err == nil || errors.Is(err, ErrTruncatedData) {
+		t.Errorf("expected a non-truncation parse error, got: %v", err)
+	}
+}
+
+func TestResolveRegistryCredentials_IDNHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		authKey  string
+		imageURL string
+	}{
+		{
+			name:     "unicode host, punycode key",
+			authKey:  "xn--bcher-kva.example.com",
+			imageURL: "oci://bücher.example.com/repo/image:tag",
+		},
+		{
+			name:     "punycode host, unicode key",
+			authKey:  "bücher.example.com",
+			imageURL: "oci://xn--bcher-kva.example.com/repo/image:tag",
+		},
+		{
+			name:     "unicode host with port, punycode key",
+			authKey:  "https://xn--bcher-kva.example.com:5000",
+			imageURL: "oci://bücher.example.com:5000/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.authKey: {
+					"username": "idnuser",
+					"password": "idnpass",
+				},
+			})
+
+			creds, err := ResolveRegistryCredentials(secret, tt.imageURL, ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.MatchedAuthKey != tt.authKey {
+				t.Errorf("expected key %q to be matched, got %q", tt.authKey, creds.MatchedAuthKey)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != "idnuser:idnpass" {
+				t.Errorf("expected 'idnuser:idnpass', got %q", string(decoded))
+			}
+		})
+	}
+}
+
+func TestCanAuthenticate(t *testing.T) {
+	auths := map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		imageURL    string
+		expected    bool
+		expectError error
+	}{
+		{
+			name:     "dockerconfigjson secret",
+			secret:   createDockerConfigJSONSecret("test-secret", auths),
+			imageURL: "oci://registry.example.com/repo/image:tag",
+			expected: true,
+		},
+		{
+			name:     "legacy dockercfg secret",
+			secret:   createLegacyDockerCfgSecret("test-secret", auths),
+			imageURL: "oci://registry.example.com/repo/image:tag",
+			expected: true,
+		},
+		{
+			name:     "registry not in secret",
+			secret:   createDockerConfigJSONSecret("test-secret", auths),
+			imageURL: "oci://quay.io/repo/image:tag",
+		},
+		{
+			name: "missing data key",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{"other": []byte("{}")},
+			},
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			expectError: ErrMissingDataKey,
+		},
+		{
+			name: "truncated data",
+			secret: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {`)},
+			},
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			expectError: ErrTruncatedData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := CanAuthenticate(tt.secret, tt.imageURL)
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("expected error %v, got %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+
+	if _, err := CanAuthenticate(createDockerConfigJSONSecret("test-secret", auths), "http://example.com/image.iso"); err == nil {
+		t.Error("expected an error for a non-OCI image URL")
+	}
+}
+
+func TestResolveRegistryCredentials_PathPrefixMatching(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.internal/quay": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+		"registry.internal/gcr": {
+			"username": "gcruser",
+			"password": "gcrpass",
+		},
+		"registry.internal": {
+			"username": "defaultuser",
+			"password": "defaultpass",
+		},
+	})
+	opts := ExtractOptions{PathPrefixMatching: true}
+
+	tests := []struct {
+		imageURL            string
+		expectedKey         string
+		expectedCredentials string
+	}{
+		{
+			imageURL:            "oci://registry.internal/quay/repo/image:tag",
+			expectedKey:         "registry.internal/quay",
+			expectedCredentials: "quayuser:quaypass",
+		},
+		{
+			imageURL:            "oci://registry.internal/gcr/project/image:tag",
+			expectedKey:         "registry.internal/gcr",
+			expectedCredentials: "gcruser:gcrpass",
+		},
+		{
+			imageURL:            "oci://registry.internal/other/image:tag",
+			expectedKey:         "registry.internal",
+			expectedCredentials: "defaultuser:defaultpass",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageURL, func(t *testing.T) {
+			creds, err := ResolveRegistryCredentials(secret, tt.imageURL, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.RegistryHost != "registry.internal" || creds.MatchedAuthKey != tt.expectedKey {
+				t.Errorf("unexpected match: RegistryHost=%q MatchedAuthKey=%q", creds.RegistryHost, creds.MatchedAuthKey)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != tt.expectedCredentials {
+				t.Errorf("expected %q, got %q", tt.expectedCredentials, string(decoded))
+			}
+		})
+	}
+
+	// Path-qualified keys never serve images under another prefix.
+	pathOnly := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.internal/quay": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+	})
+	if _, err := ResolveRegistryCredentials(pathOnly, "oci://registry.internal/gcr/project/image:tag", opts); err == nil {
+		t.Error("expected no match for an image under another prefix")
+	}
+}
+
+func TestResolveRegistryCredentials_StringAuthEntry(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		data string
+	}{
+		{
+			name: "plain dockerconfigjson",
+			key:  corev1.DockerConfigJsonKey,
+			data: `{"auths": {"registry.example.com": "testuser:testpass"}}`,
+		},
+		{
+			name: "base64 dockerconfigjson",
+			key:  corev1.DockerConfigJsonKey,
+			data: `{"auths": {"registry.example.com": "dGVzdHVzZXI6dGVzdHBhc3M="}}`,
+		},
+		{
+			name: "plain dockercfg",
+			key:  corev1.DockerConfigKey,
+			data: `{"registry.example.com": "testuser:testpass"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{Data: map[string][]byte{tt.key: []byte(tt.data)}}
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds.Credentials)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != "testuser:testpass" {
+				t.Errorf("expected 'testuser:testpass', got %q", string(decoded))
+			}
+			if len(creds.Warnings) != 1 || !strings.Contains(creds.Warnings[0], "is a string instead of an object") {
+				t.Errorf("expected a warning about the string entry, got %v", creds.Warnings)
+			}
+			if strings.Contains(strings.Join(creds.Warnings, " "), "testpass") {
+				t.Errorf("warnings must not reveal credentials: %v", creds.Warnings)
+			}
+		})
+	}
+
+	// Well-formed entries in the same secret do not produce a warning.
+	mixed := &corev1.Secret{Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+		`{"auths": {"quay.io": "quayuser:quaypass", "registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`)}}
+	creds, err := ResolveRegistryCredentials(mixed, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creds.Warnings) != 0 {
+		t.Errorf("expected no warnings for a well-formed entry, got %v", creds.Warnings)
+	}
+}
+
+func TestResolveRegistryCredentials_DebugMatchingAttemptedKeys(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"https://quay.io": {
+			"username": "quayuser",
+			"password": "quaypass",
+		},
+		"svc@registry.example.com:5000": {
+			"username": "svcuser",
+			"password": "svcpass",
+		},
+	})
+	imageURL := "oci://registry.example.com/repo/image:tag"
+
+	_, err := ResolveRegistryCredentials(secret, imageURL, ExtractOptions{DebugMatching: true})
+	var notFound *RegistryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected RegistryNotFoundError, got: %v", err)
+	}
+	expected := []string{
+		"registry.example.com",
+		"https://quay.io (as quay.io)",
+		"svc@registry.example.com:5000 (as registry.example.com:5000)",
+	}
+	if !reflect.DeepEqual(notFound.AttemptedKeys, expected) {
+		t.Errorf("expected attempted keys %v, got %v", expected, notFound.AttemptedKeys)
+	}
+	if !strings.Contains(err.Error(), "tried registry.example.com, https://quay.io") {
+		t.Errorf("expected the attempted keys in the error, got: %v", err)
+	}
+
+	_, err = ResolveRegistryCredentials(secret, imageURL, ExtractOptions{})
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected RegistryNotFoundError, got: %v", err)
+	}
+	if notFound.AttemptedKeys != nil {
+		t.Errorf("expected no attempted keys without debugging, got %v", notFound.AttemptedKeys)
+	}
+}
+
+func TestExtractRegistryCredentials_DefaultPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		authKey  string
+		imageURL string
+	}{
+		{
+			name:     "key with default port, image without",
+			authKey:  "registry.example.com:443",
+			imageURL: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "image with default port, key without",
+			authKey:  "registry.example.com",
+			imageURL: "oci://registry.example.com:443/repo/image:tag",
+		},
+		{
+			name:     "scheme-qualified key with default port",
+			authKey:  "https://registry.example.com:443/v2/",
+			imageURL: "oci://registry.example.com/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.authKey: {
+					"username": "portuser",
+					"password": "portpass",
+				},
+			})
+
+			creds, err := ExtractRegistryCredentials(secret, tt.imageURL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(creds)
+			if err != nil {
+				t.Fatalf("credentials are not valid base64: %v", err)
+			}
+			if string(decoded) != "portuser:portpass" {
+				t.Errorf("expected 'portuser:portpass', got %q", string(decoded))
+			}
+		})
+	}
+}
+
+func TestExtractRegistryCredentials_NonDefaultPortNotNormalized(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com:5000": {
+			"username": "portuser",
+			"password": "portpass",
+		},
+	})
+
+	if _, err := ExtractRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag"); err == nil {
+		t.Error("expected a key on port 5000 not to match the image on the default port")
+	}
+}
+
+func TestCredentialHelperForImage(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"credHelpers": {"https://registry.example.com:443": "ecr-login"}}`),
+		},
+	}
+
+	helper, err := CredentialHelperForImage(secret, "oci://registry.example.com/repo/image:tag")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if helper != "ecr-login" {
+		t.Errorf("expected helper 'ecr-login', got %q", helper)
+	}
+
+	helper, err = CredentialHelperForImage(secret, "oci://other.example.com/repo/image:tag")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if helper != "" {
+		t.Errorf("expected no helper for another registry, got %q", helper)
+	}
+}
+
+func TestResolveRegistryCredentials_FullDockerCLIConfig(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("cliuser:clipass"))
+	fullConfig := `{
+	"auths": {
+		"registry.example.com": {"auth": "` + auth + `"}
+	},
+	"HttpHeaders": {"User-Agent": "Docker-Client/24.0.7 (linux)"},
+	"psFormat": "table {{.ID}}\t{{.Names}}",
+	"detachKeys": "ctrl-e,e",
+	"credsStore": "desktop",
+	"currentContext": "default"
+}`
+	flatLegacy := `{
+	"registry.example.com": {"auth": "` + auth + `", "email": "cli@example.com"},
+	"HttpHeaders": {"User-Agent": "Docker-Client/1.13.1 (linux)"},
+	"psFormat": "table {{.ID}}",
+	"detachKeys": "ctrl-e,e",
+	"plugins": {"buildx": {"enabled": "true"}}
+}`
+
+	tests := []struct {
+		name       string
+		secretType corev1.SecretType
+		key        string
+		data       string
+	}{
+		{
+			name:       "full config.json as dockerconfigjson",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			key:        corev1.DockerConfigJsonKey,
+			data:       fullConfig,
+		},
+		{
+			name:       "full config.json under the legacy key",
+			secretType: corev1.SecretTypeDockercfg,
+			key:        corev1.DockerConfigKey,
+			data:       fullConfig,
+		},
+		{
+			name:       "legacy map with extra top-level keys",
+			secretType: corev1.SecretTypeDockercfg,
+			key:        corev1.DockerConfigKey,
+			data:       flatLegacy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: tt.secretType,
+				Data: map[string][]byte{tt.key: []byte(tt.data)},
+			}
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.Credentials != auth {
+				t.Errorf("expected credentials %q, got %q", auth, creds.Credentials)
+			}
+			if len(creds.Warnings) != 0 {
+				t.Errorf("expected no warnings, got %v", creds.Warnings)
+			}
+
+			// Top-level config keys must never be taken for registries.
+			cfg, _, err := parseDockerConfig(secret, ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if keys := sortedKeys(cfg.AuthConfigs); !reflect.DeepEqual(keys, []string{"registry.example.com"}) {
+				t.Errorf("expected only registry.example.com in auths, got %v", keys)
+			}
+		})
+	}
+}
+
+func TestRegistryHostForImage_EmptyHost(t *testing.T) {
+	_, err := RegistryHostForImage("oci:///repo/image:tag")
+	if !errors.Is(err, ErrEmptyRegistryHost) {
+		t.Errorf("expected ErrEmptyRegistryHost, got %v", err)
+	}
+}
+
+func TestResolveRegistryCredentials_PortMismatchDiagnostic(t *testing.T) {
+	tests := []struct {
+		name     string
+		authKey  string
+		imageURL string
+	}{
+		{
+			name:     "key with port, image without",
+			authKey:  "registry.example.com:8443",
+			imageURL: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "image with port, key without",
+			authKey:  "https://registry.example.com",
+			imageURL: "oci://registry.example.com:5000/repo/image:tag",
+		},
+		{
+			name:     "different ports",
+			authKey:  "registry.example.com:5000",
+			imageURL: "oci://registry.example.com:8443/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.authKey:               {"username": "testuser", "password": "testpass"},
+				"other.example.com:5000": {"username": "other", "password": "otherpass"},
+			})
+
+			_, err := ResolveRegistryCredentials(secret, tt.imageURL, ExtractOptions{})
+			var notFound *RegistryNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("expected RegistryNotFoundError, got %v", err)
+			}
+			if !reflect.DeepEqual(notFound.PortMismatchKeys, []string{tt.authKey}) {
+				t.Errorf("expected port mismatch on %q, got %v", tt.authKey, notFound.PortMismatchKeys)
+			}
+			if !strings.Contains(err.Error(), "port mismatch") || !strings.Contains(err.Error(), tt.authKey) {
+				t.Errorf("expected the error to point at the port mismatch, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_NoPortMismatchForOtherHosts(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"other.example.com:5000": {"username": "testuser", "password": "testpass"},
+	})
+
+	_, err := ResolveRegistryCredentials(secret, "oci://registry.example.com:5000/repo/image:tag", ExtractOptions{})
+	if err == nil || strings.Contains(err.Error(), "port mismatch") {
+		t.Errorf("expected a plain not found error, got %v", err)
+	}
+}
+
+func TestResolveRegistryCredentials_DeepImagePath(t *testing.T) {
+	imageURLs := []string{
+		"oci://registry.example.com/very/deep/path/image:tag",
+		"oci://registry.example.com/a/b/c/d/e/f/image@sha256:abcdef",
+		"oci://registry.example.com/v2/team/project/image:tag",
+		"oci://registry.example.com/team/v1/image:tag",
+		"oci://registry.example.com/team/v2/",
+		"oci://registry.example.com:5000/org/v2/v1/image:tag",
+	}
+	authKeys := []string{
+		"registry.example.com",
+		"https://registry.example.com/v1/",
+		"https://registry.example.com/v2/",
+	}
+
+	for _, authKey := range authKeys {
+		for _, imageURL := range imageURLs {
+			for _, opts := range []ExtractOptions{{}, {PathPrefixMatching: true}} {
+				t.Run(fmt.Sprintf("%s/%s/prefix=%v", authKey, imageURL, opts.PathPrefixMatching), func(t *testing.T) {
+					registry := "registry.example.com"
+					if strings.Contains(imageURL, ":5000") {
+						registry += ":5000"
+					}
+					key := strings.Replace(authKey, "registry.example.com", registry, 1)
+					secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+						key: {"username": "catalog", "password": "catalogpass"},
+					})
+
+					creds, err := ResolveRegistryCredentials(secret, imageURL, opts)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if creds.RegistryHost != registry {
+						t.Errorf("expected registry host %q, got %q", registry, creds.RegistryHost)
+					}
+					if creds.MatchedAuthKey != key {
+						t.Errorf("expected key %q to be matched, got %q", key, creds.MatchedAuthKey)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestResolveRegistryCredentials_DefaultRegistry(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"mirror.example.com":          {"username": "mirror", "password": "mirrorpass"},
+		"registry.example.com":        {"username": "testuser", "password": "testpass"},
+		"localhost:5000":              {"username": "local", "password": "localpass"},
+		"https://index.docker.io/v1/": {"username": "hubuser", "password": "hubpass"},
+	})
+
+	tests := []struct {
+		name             string
+		imageURL         string
+		defaultRegistry  string
+		expectedRegistry string
+	}{
+		{
+			name:             "name and tag only",
+			imageURL:         "oci://ubuntu:22.04",
+			defaultRegistry:  "mirror.example.com",
+			expectedRegistry: "mirror.example.com",
+		},
+		{
+			name:             "name only",
+			imageURL:         "oci://ubuntu",
+			defaultRegistry:  "mirror.example.com",
+			expectedRegistry: "mirror.example.com",
+		},
+		{
+			name:             "namespaced short name",
+			imageURL:         "oci://library/ubuntu:22.04",
+			defaultRegistry:  "mirror.example.com",
+			expectedRegistry: "mirror.example.com",
+		},
+		{
+			name:             "default registry is Docker Hub",
+			imageURL:         "oci://library/ubuntu:22.04",
+			defaultRegistry:  "docker.io",
+			expectedRegistry: "docker.io",
+		},
+		{
+			name:             "qualified reference unaffected",
+			imageURL:         "oci://registry.example.com/repo/image:tag",
+			defaultRegistry:  "mirror.example.com",
+			expectedRegistry: "registry.example.com",
+		},
+		{
+			name:             "localhost with port unaffected",
+			imageURL:         "oci://localhost:5000/repo/image:tag",
+			defaultRegistry:  "mirror.example.com",
+			expectedRegistry: "localhost:5000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := ResolveRegistryCredentials(secret, tt.imageURL, ExtractOptions{DefaultRegistry: tt.defaultRegistry})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.RegistryHost != tt.expectedRegistry {
+				t.Errorf("expected registry %q, got %q", tt.expectedRegistry, creds.RegistryHost)
+			}
+		})
+	}
+}
+
+func TestPullRegistryHost_DefaultRegistry(t *testing.T) {
+	tests := []struct {
+		imageURL         string
+		expectedRegistry string
+	}{
+		{imageURL: "oci://ubuntu:22.04", expectedRegistry: "docker.io"},
+		{imageURL: "oci://ubuntu@sha256:0123456789abcdef", expectedRegistry: "docker.io"},
+		{imageURL: "oci://library/ubuntu", expectedRegistry: "docker.io"},
+		{imageURL: "oci://registry.example.com", expectedRegistry: "registry.example.com"},
+		{imageURL: "oci://registry.example.com:5000", expectedRegistry: "registry.example.com:5000"},
+		{imageURL: "oci://localhost", expectedRegistry: "localhost"},
+		{imageURL: "oci://localhost:5000", expectedRegistry: "localhost:5000"},
+		{imageURL: "oci://registry.example.com:5000/repo/image:tag", expectedRegistry: "registry.example.com:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageURL, func(t *testing.T) {
+			registry, err := PullRegistryHost(tt.imageURL, ExtractOptions{DefaultRegistry: "docker.io"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if registry != tt.expectedRegistry {
+				t.Errorf("expected registry %q, got %q", tt.expectedRegistry, registry)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_ShortNameWithoutDefaultRegistry(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"mirror.example.com": {"username": "mirror", "password": "mirrorpass"},
+	})
+
+	if _, err := ResolveRegistryCredentials(secret, "oci://ubuntu:22.04", ExtractOptions{}); err == nil {
+		t.Error("expected short names not to resolve without a default registry")
+	}
+}
+
+func TestResolveRegistryCredentials_UsernameAsPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		password      string
+		opts          ExtractOptions
+		expectWarning bool
+	}{
+		{
+			name:          "password equals username",
+			password:      "builder",
+			opts:          ExtractOptions{WarnUsernameAsPassword: true},
+			expectWarning: true,
+		},
+		{
+			name:     "password differs from username",
+			password: "s3cr3t-pass",
+			opts:     ExtractOptions{WarnUsernameAsPassword: true},
+		},
+		{
+			name:     "check not enabled",
+			password: "builder",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				"registry.example.com": {
+					"username": "builder",
+					"password": tt.password,
+				},
+			})
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", tt.opts)
+			if err != nil {
+				t.Fatalf("a matching username and password must not fail, got error: %v", err)
+			}
+			if !tt.expectWarning {
+				if len(creds.Warnings) != 0 {
+					t.Errorf("expected no warnings, got %v", creds.Warnings)
+				}
+				return
+			}
+			if len(creds.Warnings) != 1 || !strings.Contains(creds.Warnings[0], "same as the username") {
+				t.Errorf("expected a username-as-password warning, got %v", creds.Warnings)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_HostNormalizer(t *testing.T) {
+	stripProxy := func(host string) string {
+		return strings.TrimPrefix(host, "proxy-")
+	}
+	tests := []struct {
+		name        string
+		authKey     string
+		imageURL    string
+		normalizer  func(string) string
+		expectFound bool
+		expectHost  string
+	}{
+		{
+			name:        "proxy prefix in the auths key",
+			authKey:     "proxy-registry.example.com",
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			normalizer:  stripProxy,
+			expectFound: true,
+			expectHost:  "registry.example.com",
+		},
+		{
+			name:        "proxy prefix in the image",
+			authKey:     "registry.example.com",
+			imageURL:    "oci://proxy-registry.example.com/repo/image:tag",
+			normalizer:  stripProxy,
+			expectFound: true,
+			expectHost:  "registry.example.com",
+		},
+		{
+			name:        "normalized hosts are lowercased",
+			authKey:     "proxy-Registry.Example.COM",
+			imageURL:    "oci://registry.example.com/repo/image:tag",
+			normalizer:  stripProxy,
+			expectFound: true,
+			expectHost:  "registry.example.com",
+		},
+		{
+			name:     "no normalizer",
+			authKey:  "proxy-registry.example.com",
+			imageURL: "oci://registry.example.com/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.authKey: {
+					"username": "testuser",
+					"password": "testpass",
+				},
+			})
+
+			creds, err := ResolveRegistryCredentials(secret, tt.imageURL, ExtractOptions{HostNormalizer: tt.normalizer})
+			if !tt.expectFound {
+				var notFound *RegistryNotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("expected a RegistryNotFoundError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.RegistryHost != tt.expectHost || creds.MatchedAuthKey != tt.authKey {
+				t.Errorf("expected host %s from key %s, got host %s from key %s",
+					tt.expectHost, tt.authKey, creds.RegistryHost, creds.MatchedAuthKey)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_YAMLDockerConfig(t *testing.T) {
+	yamlConfig := `auths:
+  registry.example.com:
+    username: testuser
+    password: testpass
+`
+	legacyYAMLConfig := `registry.example.com:
+  auth: dGVzdHVzZXI6dGVzdHBhc3M=
+`
+	tests := []struct {
+		name      string
+		key       string
+		data      string
+		opts      ExtractOptions
+		expectErr bool
+	}{
+		{
+			name: "dockerconfigjson as YAML",
+			key:  corev1.DockerConfigJsonKey,
+			data: yamlConfig,
+			opts: ExtractOptions{YAMLFallback: true},
+		},
+		{
+			name: "dockercfg as YAML",
+			key:  corev1.DockerConfigKey,
+			data: legacyYAMLConfig,
+			opts: ExtractOptions{YAMLFallback: true},
+		},
+		{
+			name:      "fallback not enabled",
+			key:       corev1.DockerConfigJsonKey,
+			data:      yamlConfig,
+			expectErr: true,
+		},
+		{
+			name:      "neither JSON nor YAML",
+			key:       corev1.DockerConfigJsonKey,
+			data:      "auths: [registry.example.com",
+			opts:      ExtractOptions{YAMLFallback: true},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{tt.key: []byte(tt.data)},
+			}
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", tt.opts)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := base64.StdEncoding.EncodeToString([]byte("testuser:testpass"))
+			if creds.Credentials != expected {
+				t.Errorf("expected credentials %s, got %s", expected, creds.Credentials)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_OpenShiftInternalRegistry(t *testing.T) {
+	// The shape of the dockercfg secret OpenShift generates for a service
+	// account: one entry per name of the internal registry service.
+	entry := func(password string) map[string]string {
+		return map[string]string{
+			"username": "<token>",
+			"password": password,
+			"email":    "serviceaccount@example.org",
+			"auth":     base64.StdEncoding.EncodeToString([]byte("<token>:" + password)),
+		}
+	}
+	auths := map[string]map[string]string{
+		"172.30.1.1:5000": entry("cluster-ip-token"),
+		"image-registry.openshift-image-registry.svc.cluster.local:5000": entry("fqdn-token"),
+		"image-registry.openshift-image-registry.svc:5000":               entry(testJWT),
+	}
+	data, err := json.Marshal(auths)
+	if err != nil {
+		t.Fatalf("failed to marshal dockercfg: %v", err)
+	}
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockercfg,
+		Data: map[string][]byte{corev1.DockerConfigKey: data},
+	}
+
+	creds, err := ResolveRegistryCredentials(secret,
+		"oci://image-registry.openshift-image-registry.svc:5000/openshift/rhcos:latest", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.MatchedAuthKey != "image-registry.openshift-image-registry.svc:5000" {
+		t.Errorf("expected the service host entry to match, got %s", creds.MatchedAuthKey)
+	}
+	expected := base64.StdEncoding.EncodeToString([]byte("<token>:" + testJWT))
+	if creds.Credentials != expected {
+		t.Errorf("expected the service host credentials, got %s", creds.Credentials)
+	}
+	if !creds.TokenAuth {
+		t.Error("expected service account credentials to be classified as token auth")
+	}
+	if len(creds.Warnings) != 0 {
+		t.Errorf("the internal registry accepts service account tokens, expected no warnings, got %v", creds.Warnings)
+	}
+
+	// The registry listens on 5000 only; an image without the port is a
+	// different registry.
+	_, err = ResolveRegistryCredentials(secret,
+		"oci://image-registry.openshift-image-registry.svc/openshift/rhcos:latest", ExtractOptions{})
+	var notFound *RegistryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected a RegistryNotFoundError without the port, got %v", err)
+	}
+	if !slices.Contains(notFound.PortMismatchKeys, "image-registry.openshift-image-registry.svc:5000") {
+		t.Errorf("expected the port mismatch to be reported, got %v", notFound.PortMismatchKeys)
+	}
+}
+
+func TestResolveRegistryCredentials_WrappedAuths(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		expectErr bool
+	}{
+		{
+			name:   "versioned top-level auths",
+			config: `{"apiVersion": "v1", "auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+		},
+		{
+			name:   "auths wrapped one level down",
+			config: `{"apiVersion": "v1", "config": {"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}}`,
+		},
+		{
+			name:   "wrapped string entry",
+			config: `{"config": {"auths": {"registry.example.com": "testuser:testpass"}}}`,
+		},
+		{
+			name:      "auths wrapped two levels down",
+			config:    `{"spec": {"config": {"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(tt.config)},
+			}
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if tt.expectErr {
+				var notFound *RegistryNotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("expected a RegistryNotFoundError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := base64.StdEncoding.EncodeToString([]byte("testuser:testpass"))
+			if creds.Credentials != expected {
+				t.Errorf("expected credentials %s, got %s", expected, creds.Credentials)
+			}
+		})
+	}
+}
+
+func TestExtractRegistryCredentials_CredentialFormat(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	tests := []struct {
+		name      string
+		format    string
+		expected  string
+		expectErr bool
+	}{
+		{name: "default", expected: "testuser:testpass"},
+		{name: "explicit default", format: "%s:%s", expected: "testuser:testpass"},
+		{name: "other separator", format: "%s|%s", expected: "testuser|testpass"},
+		{name: "reordered", format: "%[2]s@%[1]s", expected: "testpass@testuser"},
+		{name: "literal percent", format: "%s%%%s", expected: "testuser%testpass"},
+		{name: "single verb", format: "%s", expectErr: true},
+		{name: "three verbs", format: "%s:%s:%s", expectErr: true},
+		{name: "username twice", format: "%[1]s:%[1]s", expectErr: true},
+		{name: "other verb", format: "%s:%d", expectErr: true},
+		{name: "width", format: "%5s:%s", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials, err := ExtractRegistryCredentialsWithOptions(secret, "oci://registry.example.com/repo/image:tag",
+				ExtractOptions{CredentialFormat: tt.format})
+			if tt.expectErr {
+				if !errors.Is(err, ErrInvalidCredentialFormat) {
+					t.Fatalf("expected ErrInvalidCredentialFormat, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := base64.StdEncoding.EncodeToString([]byte(tt.expected)); credentials != expected {
+				t.Errorf("expected %s, got %s", expected, credentials)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_EmptyAuthConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		legacy      bool
+		expectEmpty bool
+	}{
+		{name: "empty object", config: `{}`, expectEmpty: true},
+		{name: "unrelated fields only", config: `{"apiVersion": "v1"}`, expectEmpty: true},
+		{name: "empty auths map", config: `{"auths": {}}`, expectEmpty: true},
+		{name: "empty legacy config", config: `{}`, legacy: true, expectEmpty: true},
+		{name: "credential helpers only", config: `{"credHelpers": {"registry.example.com": "ecr-login"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(tt.config)},
+			}
+			if tt.legacy {
+				secret = &corev1.Secret{
+					Type: corev1.SecretTypeDockercfg,
+					Data: map[string][]byte{corev1.DockerConfigKey: []byte(tt.config)},
+				}
+			}
+
+			_, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			var notFound *RegistryNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("expected a RegistryNotFoundError, got %v", err)
+			}
+			if errors.Is(err, ErrEmptyAuthConfig) != tt.expectEmpty {
+				t.Errorf("expected ErrEmptyAuthConfig %v, got %v", tt.expectEmpty, err)
+			}
+		})
+	}
+
+	canAuth, err := CanAuthenticate(&corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{}`)},
+	}, "oci://registry.example.com/repo/image:tag")
+	if err != nil || canAuth {
+		t.Errorf("expected an empty config to not authenticate without error, got %v, %v", canAuth, err)
+	}
+}
+
+func TestResolveRegistryCredentials_MirrorNamespaceParameter(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"https://index.docker.io/v1/": {
+			"username": "hubuser",
+			"password": "hubpass",
+		},
+		"mirror.example.com": {
+			"username": "mirroruser",
+			"password": "mirrorpass",
+		},
+	})
+	tests := []struct {
+		name           string
+		imageURL       string
+		expectHost     string
+		expectUsername string
+	}{
+		{
+			name:           "mirror pull for Docker Hub",
+			imageURL:       "oci://mirror.example.com/library/ubuntu:22.04?ns=docker.io",
+			expectHost:     "docker.io",
+			expectUsername: "hubuser",
+		},
+		{
+			name:           "no ns parameter",
+			imageURL:       "oci://mirror.example.com/library/ubuntu:22.04",
+			expectHost:     "mirror.example.com",
+			expectUsername: "mirroruser",
+		},
+		{
+			name:           "empty ns parameter",
+			imageURL:       "oci://mirror.example.com/library/ubuntu:22.04?ns=",
+			expectHost:     "mirror.example.com",
+			expectUsername: "mirroruser",
+		},
+		{
+			name:           "ns parameter for another registry",
+			imageURL:       "oci://mirror.example.com/repo/image:9.4?ns=quay.io",
+			expectHost:     "mirror.example.com",
+			expectUsername: "mirroruser",
+		},
+	}
+
+	opts := ExtractOptions{UpstreamMirrors: map[string][]string{"docker.io": {"mirror.example.com"}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := ResolveRegistryCredentials(secret, tt.imageURL, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.RegistryHost != tt.expectHost {
+				t.Errorf("expected registry %s, got %s", tt.expectHost, creds.RegistryHost)
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(creds.Credentials)
+			if !strings.HasPrefix(string(decoded), tt.expectUsername+":") {
+				t.Errorf("expected the credentials of %s, got %s", tt.expectUsername, decoded)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_DualKeyConsistency(t *testing.T) {
+	current := map[string]map[string]string{
+		"registry.example.com": {"username": "testuser", "password": "newpass"},
+	}
+	tests := []struct {
+		name         string
+		legacy       map[string]map[string]string
+		expectWarned bool
+	}{
+		{
+			name:   "keys agree",
+			legacy: current,
+		},
+		{
+			name: "keys disagree",
+			legacy: map[string]map[string]string{
+				"registry.example.com": {"username": "testuser", "password": "oldpass"},
+			},
+			expectWarned: true,
+		},
+		{
+			name: "legacy key has no entry for the registry",
+			legacy: map[string]map[string]string{
+				"other.example.com": {"username": "testuser", "password": "oldpass"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", current)
+			secret.Data[corev1.DockerConfigKey] = createLegacyDockerCfgSecret("legacy", tt.legacy).Data[corev1.DockerConfigKey]
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(creds.Credentials)
+			if string(decoded) != "testuser:newpass" {
+				t.Errorf("expected the dockerconfigjson credentials to be used, got %s", decoded)
+			}
+			warned := false
+			for _, warning := range creds.Warnings {
+				if strings.Contains(warning, "different credentials") {
+					warned = true
+				}
+			}
+			if warned != tt.expectWarned {
+				t.Errorf("expected a conflict warning: %v, got warnings %v", tt.expectWarned, creds.Warnings)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_EmailOnlyEntry(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"email": "user@example.com"}}}`)},
+	}
+
+	_, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+	var notFound *RegistryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected a RegistryNotFoundError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "registry.example.com") {
+		t.Errorf("expected the error to name the matched entry, got %v", err)
+	}
+}
+
+func TestResolveRegistryCredentials_CaseVariantKeys(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          string
+		expectAmbiguous bool
+	}{
+		{
+			name:            "different credentials",
+			config:          `{"auths": {"Registry.example.com": {"username": "olduser", "password": "oldpass"}, "registry.example.com": {"username": "testuser", "password": "testpass"}}}`,
+			expectAmbiguous: true,
+		},
+		{
+			name:   "same credentials",
+			config: `{"auths": {"Registry.example.com": {"username": "testuser", "password": "testpass"}, "registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+		},
+		{
+			name:   "different registries",
+			config: `{"auths": {"Other.example.com": {"username": "olduser", "password": "oldpass"}, "registry.example.com": {"username": "testuser", "password": "testpass"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(tt.config)},
+			}
+
+			_, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if errors.Is(err, ErrAmbiguousRegistryEntry) != tt.expectAmbiguous {
+				t.Fatalf("expected ErrAmbiguousRegistryEntry %v, got %v", tt.expectAmbiguous, err)
+			}
+			if !tt.expectAmbiguous && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectAmbiguous && !strings.Contains(err.Error(), "Registry.example.com") {
+				t.Errorf("expected the error to name both keys, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractRegistryCredentialsFromBytes(t *testing.T) {
+	expected := base64.StdEncoding.EncodeToString([]byte("testuser:testpass"))
+	tests := []struct {
+		name      string
+		data      string
+		legacy    bool
+		expectErr bool
+	}{
+		{
+			name: "dockerconfigjson",
+			data: `{"auths": {"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}}`,
+		},
+		{
+			name:   "legacy dockercfg",
+			data:   `{"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}`,
+			legacy: true,
+		},
+		{
+			name:      "legacy data read as dockerconfigjson",
+			data:      `{"registry.example.com": {"auth": "dGVzdHVzZXI6dGVzdHBhc3M="}}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			data:      `not json`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials, err := ExtractRegistryCredentialsFromBytes([]byte(tt.data), "oci://registry.example.com/repo/image:tag", tt.legacy)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if credentials != expected {
+				t.Errorf("expected %s, got %s", expected, credentials)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_KeyWithQueryOrFragment(t *testing.T) {
+	for _, key := range []string{
+		"https://registry.example.com/#frag",
+		"https://registry.example.com#frag",
+		"registry.example.com?tab=tags",
+		"https://registry.example.com:443/?tab=tags#frag",
+	} {
+		t.Run(key, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				key: {"username": "testuser", "password": "testpass"},
+			})
+
+			for _, opts := range []ExtractOptions{{}, {PathPrefixMatching: true}} {
+				creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", opts)
+				if err != nil {
+					t.Fatalf("unexpected error with %+v: %v", opts, err)
+				}
+				if creds.MatchedAuthKey != key {
+					t.Errorf("expected key %q to match with %+v, got %q", key, opts, creds.MatchedAuthKey)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_AuthFieldPrecedence(t *testing.T) {
+	newAuth := base64.StdEncoding.EncodeToString([]byte("testuser:newpass"))
+	tests := []struct {
+		name            string
+		entry           string
+		preferAuthField bool
+		expected        string
+	}{
+		{
+			name:     "username and password win by default",
+			entry:    `{"username": "testuser", "password": "oldpass", "auth": "` + newAuth + `"}`,
+			expected: "testuser:oldpass",
+		},
+		{
+			name:            "auth wins when preferred",
+			entry:           `{"username": "testuser", "password": "oldpass", "auth": "` + newAuth + `"}`,
+			preferAuthField: true,
+			expected:        "testuser:newpass",
+		},
+		{
+			name:            "username and password used without auth when auth is preferred",
+			entry:           `{"username": "testuser", "password": "oldpass"}`,
+			preferAuthField: true,
+			expected:        "testuser:oldpass",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": ` + tt.entry + `}}`)},
+			}
+
+			credentials, err := ExtractRegistryCredentialsWithOptions(secret, "oci://registry.example.com/repo/image:tag",
+				ExtractOptions{PreferAuthField: tt.preferAuthField})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := base64.StdEncoding.EncodeToString([]byte(tt.expected)); credentials != expected {
+				t.Errorf("expected %s, got %s", expected, credentials)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_NotDecrypted(t *testing.T) {
+	tests := []struct {
+		name            string
+		key             string
+		data            []byte
+		expectEncrypted bool
+	}{
+		{
+			name:            "binary ciphertext",
+			key:             corev1.DockerConfigJsonKey,
+			data:            []byte{0x85, 0x02, 0x0c, 0x03, 0xa7, 0x1f, 0x00, 0xd4, 0x9b, 0x11, 0xfe, 0x42},
+			expectEncrypted: true,
+		},
+		{
+			name:            "binary ciphertext under the legacy key",
+			key:             corev1.DockerConfigKey,
+			data:            []byte{0x01, 0x00, 0x7a, 0x33, 0x10, 0x02, 0x7f, 0x05},
+			expectEncrypted: true,
+		},
+		{
+			name:            "SOPS encrypted value",
+			key:             corev1.DockerConfigJsonKey,
+			data:            []byte("ENC[AES256_GCM,data:p673w==,iv:YY=,tag:TA==,type:str]"),
+			expectEncrypted: true,
+		},
+		{
+			name: "malformed JSON",
+			key:  corev1.DockerConfigJsonKey,
+			data: []byte(`{"auths": nope}`),
+		},
+		{
+			name: "base64 text",
+			key:  corev1.DockerConfigJsonKey,
+			data: []byte("eyJhdXRocyI6IHt9fQ=="),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{Data: map[string][]byte{tt.key: tt.data}}
+
+			_, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrNotDecrypted) != tt.expectEncrypted {
+				t.Errorf("expected ErrNotDecrypted %v, got %v", tt.expectEncrypted, err)
+			}
+		})
+	}
+}
+
+// TestResolveRegistryCredentials_UntrustedMirrorNamespaceParameter tests that
+// the ns parameter of an image pulled from a host that is not a configured
+// mirror does not select the credentials of the upstream registry.
+func TestResolveRegistryCredentials_UntrustedMirrorNamespaceParameter(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"docker.io": {
+			"username": "hubuser",
+			"password": "hubpass",
+		},
+	})
+	tests := []struct {
+		name    string
+		mirrors map[string][]string
+	}{
+		{name: "no mirrors configured"},
+		{name: "other mirror configured", mirrors: map[string][]string{"docker.io": {"mirror.example.com"}}},
+		{name: "mirror of another registry", mirrors: map[string][]string{"quay.io": {"evil.example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ExtractOptions{UpstreamMirrors: tt.mirrors}
+			host, err := ResolveRegistryHost("oci://evil.example.com/library/ubuntu:22.04?ns=docker.io", opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if host != "evil.example.com" {
+				t.Errorf("expected the pull host evil.example.com, got %s", host)
+			}
+
+			_, err = ResolveRegistryCredentials(secret, "oci://evil.example.com/library/ubuntu:22.04?ns=docker.io", opts)
+			var notFound *RegistryNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("expected RegistryNotFoundError, got %v", err)
+			}
+		})
+	}
+}
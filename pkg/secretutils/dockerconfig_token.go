@@ -0,0 +1,85 @@
+package secretutils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// tokenRegistries lists the registries known to accept bearer tokens in place
+// of a password, as patterns for RegistryMatches.
+var tokenRegistries = []string{
+	// Azure Container Registry access and refresh tokens
+	"*.azurecr.io",
+	// OpenShift internal registry, which accepts service account tokens
+	"image-registry.openshift-image-registry.svc",
+}
+
+// acrTokenUsername is the username Azure Container Registry expects with a
+// refresh token as the password.
+const acrTokenUsername = "00000000-0000-0000-0000-000000000000"
+
+// openShiftTokenUsername is the username in the dockercfg secrets OpenShift
+// generates for service accounts, with the service account token as the
+// password.
+const openShiftTokenUsername = "<token>"
+
+// isTokenAuth reports whether the credentials are a token rather than a
+// password: an identity token, stored without a username, an ACR refresh
+// token paired with acrTokenUsername or an OpenShift service account token
+// paired with openShiftTokenUsername.
+func isTokenAuth(username string) bool {
+	return username == "" || username == acrTokenUsername || username == openShiftTokenUsername
+}
+
+// acceptsTokens reports whether the registry is known to accept a token in
+// place of a password, either by default or through opts.TokenRegistries.
+func (o ExtractOptions) acceptsTokens(registryHost string) bool {
+	for _, patterns := range [][]string{tokenRegistries, o.TokenRegistries} {
+		for _, pattern := range patterns {
+			if RegistryMatches(registryHost, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// looksLikeJWT reports whether s has the shape of a JSON Web Token: three
+// base64url segments separated by dots, the first one decoding to a JSON
+// header naming an algorithm.
+func looksLikeJWT(s string) bool {
+	segments := strings.Split(s, ".")
+	if len(segments) != 3 {
+		return false
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return false
+		}
+		if _, err := base64.RawURLEncoding.DecodeString(segment); err != nil {
+			return false
+		}
+	}
+
+	header, _ := base64.RawURLEncoding.DecodeString(segments[0])
+	var fields map[string]any
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return false
+	}
+	_, hasAlg := fields["alg"]
+	return hasAlg
+}
+
+// tokenPasswordWarning returns a warning when the password is a JWT, such as
+// a Kubernetes service account token, and neither the registry nor the
+// username indicate token authentication. It returns an empty string
+// otherwise.
+func tokenPasswordWarning(registryHost, username, password string, opts ExtractOptions) string {
+	if !looksLikeJWT(password) || isTokenAuth(username) || opts.acceptsTokens(registryHost) {
+		return ""
+	}
+	return fmt.Sprintf("the password for registry %s looks like a token (JWT), such as a service account token, "+
+		"where a registry password was expected", registryHost)
+}
@@ -0,0 +1,168 @@
+package secretutils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// testJWT is a JWT-shaped value with the layout of a service account token.
+var testJWT = strings.Join([]string{
+	base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"test"}`)),
+	base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:default:builder"}`)),
+	base64.RawURLEncoding.EncodeToString([]byte("signature")),
+}, ".")
+
+func TestLooksLikeJWT(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{testJWT, true},
+		{"testpass", false},
+		{"a.b.c", false},
+		{"my.dotted.password", false},
+		{testJWT + ".extra", false},
+		{"..", false},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeJWT(tt.value); got != tt.expected {
+			t.Errorf("looksLikeJWT(%q) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestResolveRegistryCredentials_JWTPassword(t *testing.T) {
+	tests := []struct {
+		name          string
+		registry      string
+		opts          ExtractOptions
+		expectWarning bool
+	}{
+		{
+			name:          "registry expecting a password",
+			registry:      "registry.example.com",
+			expectWarning: true,
+		},
+		{
+			name:     "registry known to accept tokens",
+			registry: "myregistry.azurecr.io",
+		},
+		{
+			name:     "registry configured to accept tokens",
+			registry: "registry.example.com",
+			opts:     ExtractOptions{TokenRegistries: []string{"registry.example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.registry: {
+					"username": "builder",
+					"password": testJWT,
+				},
+			})
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://"+tt.registry+"/repo/image:tag", tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.expectWarning {
+				if len(creds.Warnings) != 0 {
+					t.Errorf("expected no warnings, got %v", creds.Warnings)
+				}
+				return
+			}
+			if len(creds.Warnings) != 1 || !strings.Contains(creds.Warnings[0], "looks like a token") {
+				t.Errorf("expected a token warning, got %v", creds.Warnings)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_PlainPasswordNoTokenWarning(t *testing.T) {
+	secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creds.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", creds.Warnings)
+	}
+}
+
+func TestResolveRegistryCredentials_ACRTokenUsername(t *testing.T) {
+	tests := []struct {
+		name            string
+		registry        string
+		username        string
+		expectTokenAuth bool
+	}{
+		{
+			name:            "ACR registry",
+			registry:        "myregistry.azurecr.io",
+			username:        "00000000-0000-0000-0000-000000000000",
+			expectTokenAuth: true,
+		},
+		{
+			name:            "ACR behind a custom domain",
+			registry:        "registry.example.com",
+			username:        "00000000-0000-0000-0000-000000000000",
+			expectTokenAuth: true,
+		},
+		{
+			name:     "regular username",
+			registry: "myregistry.azurecr.io",
+			username: "builder",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+				tt.registry: {
+					"username": tt.username,
+					"password": testJWT,
+				},
+			})
+
+			creds, err := ResolveRegistryCredentials(secret, "oci://"+tt.registry+"/repo/image:tag", ExtractOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.TokenAuth != tt.expectTokenAuth {
+				t.Errorf("expected TokenAuth %v, got %v", tt.expectTokenAuth, creds.TokenAuth)
+			}
+			if len(creds.Warnings) != 0 {
+				t.Errorf("expected no warnings, got %v", creds.Warnings)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials_IdentityTokenIsTokenAuth(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"identitytoken": "refresh-token"}}}`),
+		},
+	}
+
+	creds, err := ResolveRegistryCredentials(secret, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !creds.TokenAuth {
+		t.Error("expected identity token credentials to be classified as token auth")
+	}
+}
@@ -0,0 +1,126 @@
+package secretutils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// CanonicalizeImageURL returns the normalized form of an OCI image URL, so
+// that equivalent references compare equal wherever they are stored or
+// matched. The scheme and registry host are lowercased, an explicit default
+// HTTPS port is dropped and trailing slashes are removed from the path. The
+// repository path and tag are kept as is since they are case-sensitive.
+//
+// An error is returned for URLs that are not OCI image URLs or that have no
+// registry host, in which case the error wraps ErrEmptyRegistryHost.
+func CanonicalizeImageURL(imageURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(imageURL))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image URL: %w", err)
+	}
+	if !strings.EqualFold(parsed.Scheme, "oci") {
+		return "", fmt.Errorf("image URL does not have oci:// scheme: %s", imageURL)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrEmptyRegistryHost, imageURL)
+	}
+
+	host := strings.ToLower(parsed.Host)
+	if name, port, splitErr := net.SplitHostPort(host); splitErr == nil && port == "443" {
+		host = name
+		if strings.Contains(name, ":") {
+			host = "[" + name + "]"
+		}
+	}
+
+	canonical := url.URL{
+		Scheme:   "oci",
+		Host:     host,
+		Path:     strings.TrimRight(parsed.Path, "/"),
+		RawQuery: parsed.RawQuery,
+		Fragment: parsed.Fragment,
+	}
+	return canonical.String(), nil
+}
+
+// NormalizeToOCI converts a plain container image reference, such as
+// "quay.io/org/image:tag", into the "oci://quay.io/org/image:tag" URL form
+// the API expects. References that already use the oci:// scheme are
+// returned unchanged apart from surrounding spaces. References with any
+// other scheme, such as http:// image URLs, are not container images and
+// are rejected, as are references without a registry host.
+func NormalizeToOCI(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("image reference is empty")
+	}
+	if !strings.Contains(ref, "://") {
+		ref = "oci://" + ref
+	}
+	if _, err := CanonicalizeImageURL(ref); err != nil {
+		return "", fmt.Errorf("invalid image reference: %w", err)
+	}
+	return ref, nil
+}
+
+// SameRegistry reports whether the two OCI image URLs are hosted on the same
+// registry. Hosts are compared case-insensitively, ignoring an explicit
+// default HTTPS port, and the Docker Hub aliases are all the same registry.
+// An error is returned if either URL is not a valid OCI image URL.
+func SameRegistry(urlA, urlB string) (bool, error) {
+	hostA, err := canonicalRegistryHost(urlA)
+	if err != nil {
+		return false, err
+	}
+	hostB, err := canonicalRegistryHost(urlB)
+	if err != nil {
+		return false, err
+	}
+	return hostA == hostB || (IsDockerHubHost(hostA) && IsDockerHubHost(hostB)), nil
+}
+
+// canonicalRegistryHost returns the registry host of the OCI image URL in
+// the form used for comparisons.
+func canonicalRegistryHost(imageURL string) (string, error) {
+	canonical, err := CanonicalizeImageURL(imageURL)
+	if err != nil {
+		return "", err
+	}
+	host, err := extractRegistryHost(canonical)
+	if err != nil {
+		return "", err
+	}
+	return toPunycode(host), nil
+}
+
+// ImageRepository splits an OCI image URL into the repository path within
+// its registry and the tag or digest to pull, "latest" if it names neither.
+// For example, "oci://registry.example.com/org/image:tag" returns "org/image"
+// and "tag", and the short reference "oci://ubuntu@sha256:abc" returns
+// "ubuntu" and "sha256:abc".
+func ImageRepository(imageURL string) (string, string, error) {
+	scheme, rest, found := strings.Cut(strings.TrimSpace(imageURL), "://")
+	if !found || !strings.EqualFold(scheme, "oci") {
+		return "", "", fmt.Errorf("image URL does not have oci:// scheme: %s", imageURL)
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+	rest, _, _ = strings.Cut(rest, "#")
+	if !isShortImageReference(imageURL) {
+		_, rest, _ = strings.Cut(rest, "/")
+	}
+	rest = strings.Trim(rest, "/")
+
+	repository, reference := rest, "latest"
+	if name, digest, isDigest := strings.Cut(rest, "@"); isDigest {
+		repository, reference = name, digest
+	} else if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		repository, reference = rest[:i], rest[i+1:]
+	}
+	if repository == "" || reference == "" {
+		return "", "", fmt.Errorf("image URL names no repository: %s", imageURL)
+	}
+	return repository, reference, nil
+}
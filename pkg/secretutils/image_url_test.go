@@ -0,0 +1,295 @@
+package secretutils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalizeImageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		imageURL string
+		expected string
+	}{
+		{
+			name:     "already canonical",
+			imageURL: "oci://registry.example.com/repo/image:tag",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "mixed-case scheme",
+			imageURL: "OCI://registry.example.com/repo/image:tag",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "mixed-case host",
+			imageURL: "oci://Registry.Example.COM/repo/image:tag",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "case-sensitive tag kept",
+			imageURL: "oci://registry.example.com/repo/image:Release-1",
+			expected: "oci://registry.example.com/repo/image:Release-1",
+		},
+		{
+			name:     "default port removed",
+			imageURL: "oci://registry.example.com:443/repo/image:tag",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "non-default port kept",
+			imageURL: "oci://registry.example.com:5000/repo/image:tag",
+			expected: "oci://registry.example.com:5000/repo/image:tag",
+		},
+		{
+			name:     "IPv6 host with default port",
+			imageURL: "oci://[2001:db8::1]:443/repo/image:tag",
+			expected: "oci://[2001:db8::1]/repo/image:tag",
+		},
+		{
+			name:     "trailing slash",
+			imageURL: "oci://registry.example.com/repo/image:tag/",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "several trailing slashes and surrounding spaces",
+			imageURL: " oci://registry.example.com/repo/image:tag// ",
+			expected: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name:     "digest reference",
+			imageURL: "oci://registry.example.com/repo/image@sha256:abcdef",
+			expected: "oci://registry.example.com/repo/image@sha256:abcdef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, err := CanonicalizeImageURL(tt.imageURL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if canonical != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, canonical)
+			}
+
+			again, err := CanonicalizeImageURL(canonical)
+			if err != nil || again != canonical {
+				t.Errorf("expected canonicalization to be idempotent, got %q (%v)", again, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeImageURL_Invalid(t *testing.T) {
+	if _, err := CanonicalizeImageURL("http://example.com/image.qcow2"); err == nil {
+		t.Error("expected an error for a non-OCI URL")
+	}
+	if _, err := CanonicalizeImageURL("oci:///repo/image:tag"); !errors.Is(err, ErrEmptyRegistryHost) {
+		t.Errorf("expected ErrEmptyRegistryHost, got %v", err)
+	}
+}
+
+func TestNormalizeToOCI(t *testing.T) {
+	tests := []struct {
+		name      string
+		ref       string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "bare reference",
+			ref:      "quay.io/org/image:tag",
+			expected: "oci://quay.io/org/image:tag",
+		},
+		{
+			name:     "bare reference with a port",
+			ref:      "localhost:5000/image:tag",
+			expected: "oci://localhost:5000/image:tag",
+		},
+		{
+			name:     "bare digest reference",
+			ref:      " quay.io/org/image@sha256:abcdef ",
+			expected: "oci://quay.io/org/image@sha256:abcdef",
+		},
+		{
+			name:     "already oci",
+			ref:      "oci://quay.io/org/image:tag",
+			expected: "oci://quay.io/org/image:tag",
+		},
+		{
+			name:      "http reference",
+			ref:       "http://example.com/image.qcow2",
+			expectErr: true,
+		},
+		{
+			name:      "oci reference without a host",
+			ref:       "oci:///org/image:tag",
+			expectErr: true,
+		},
+		{
+			name:      "empty reference",
+			ref:       "  ",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeToOCI(tt.ref)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", normalized)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if normalized != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, normalized)
+			}
+		})
+	}
+}
+
+func TestSameRegistry(t *testing.T) {
+	tests := []struct {
+		name   string
+		urlA   string
+		urlB   string
+		expect bool
+	}{
+		{
+			name:   "same host, different paths",
+			urlA:   "oci://registry.example.com/team-a/image:1.0",
+			urlB:   "oci://registry.example.com/team-b/other@sha256:abcdef",
+			expect: true,
+		},
+		{
+			name:   "default port and no port",
+			urlA:   "oci://registry.example.com:443/repo/image:tag",
+			urlB:   "oci://registry.example.com/repo/image:tag",
+			expect: true,
+		},
+		{
+			name:   "different case",
+			urlA:   "OCI://Registry.Example.COM/repo/image:tag",
+			urlB:   "oci://registry.example.com/repo/image:tag",
+			expect: true,
+		},
+		{
+			name:   "Docker Hub aliases",
+			urlA:   "oci://docker.io/library/ubuntu:22.04",
+			urlB:   "oci://index.docker.io/library/ubuntu:22.04",
+			expect: true,
+		},
+		{
+			name: "non-default port",
+			urlA: "oci://registry.example.com:5000/repo/image:tag",
+			urlB: "oci://registry.example.com/repo/image:tag",
+		},
+		{
+			name: "different hosts",
+			urlA: "oci://registry.example.com/repo/image:tag",
+			urlB: "oci://quay.io/repo/image:tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same, err := SameRegistry(tt.urlA, tt.urlB)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if same != tt.expect {
+				t.Errorf("expected %v, got %v", tt.expect, same)
+			}
+		})
+	}
+}
+
+func TestSameRegistry_Invalid(t *testing.T) {
+	if _, err := SameRegistry("http://example.com/image.qcow2", "oci://registry.example.com/repo/image:tag"); err == nil {
+		t.Error("expected an error for a non-OCI URL")
+	}
+	if _, err := SameRegistry("oci://registry.example.com/repo/image:tag", "oci:///repo/image:tag"); !errors.Is(err, ErrEmptyRegistryHost) {
+		t.Errorf("expected ErrEmptyRegistryHost, got %v", err)
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		imageURL           string
+		expectedRepository string
+		expectedReference  string
+	}{
+		{
+			name:               "tag",
+			imageURL:           "oci://registry.example.com/org/image:tag",
+			expectedRepository: "org/image",
+			expectedReference:  "tag",
+		},
+		{
+			name:               "no tag",
+			imageURL:           "oci://registry.example.com/org/image",
+			expectedRepository: "org/image",
+			expectedReference:  "latest",
+		},
+		{
+			name:               "registry port",
+			imageURL:           "oci://registry.example.com:5000/image",
+			expectedRepository: "image",
+			expectedReference:  "latest",
+		},
+		{
+			name:               "digest",
+			imageURL:           "oci://registry.example.com/image@sha256:abc",
+			expectedRepository: "image",
+			expectedReference:  "sha256:abc",
+		},
+		{
+			name:               "query",
+			imageURL:           "oci://mirror.example.com/library/ubuntu:22.04?ns=docker.io",
+			expectedRepository: "library/ubuntu",
+			expectedReference:  "22.04",
+		},
+		{
+			name:               "short reference",
+			imageURL:           "oci://ubuntu:22.04",
+			expectedRepository: "ubuntu",
+			expectedReference:  "22.04",
+		},
+		{
+			name:               "short reference with namespace",
+			imageURL:           "oci://org/image",
+			expectedRepository: "org/image",
+			expectedReference:  "latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, reference, err := ImageRepository(tt.imageURL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repository != tt.expectedRepository || reference != tt.expectedReference {
+				t.Errorf("expected %q %q, got %q %q", tt.expectedRepository, tt.expectedReference, repository, reference)
+			}
+		})
+	}
+}
+
+func TestImageRepository_Invalid(t *testing.T) {
+	for _, imageURL := range []string{
+		"http://example.com/image.qcow2",
+		"oci://registry.example.com",
+		"oci://registry.example.com/image@",
+	} {
+		if _, _, err := ImageRepository(imageURL); err == nil {
+			t.Errorf("expected an error for %q", imageURL)
+		}
+	}
+}
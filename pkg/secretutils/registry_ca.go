@@ -0,0 +1,64 @@
+package secretutils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegistryCABundleKey is the secret data key holding the PEM-encoded CA
+// bundle of the registry, alongside the Docker config.
+const RegistryCABundleKey = "ca.crt"
+
+// ExtractRegistryCABundle returns the PEM-encoded registry CA bundle stored
+// under RegistryCABundleKey, or nil if the secret does not carry one. An
+// error is returned if the bundle does not contain any valid certificate.
+func ExtractRegistryCABundle(secret *corev1.Secret) ([]byte, error) {
+	if secret == nil {
+		return nil, errors.New("secret is nil")
+	}
+
+	bundle, ok := secret.Data[RegistryCABundleKey]
+	if !ok {
+		return nil, nil
+	}
+
+	found := false
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid certificate in %s: %w", RegistryCABundleKey, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("%s does not contain any PEM certificate", RegistryCABundleKey)
+	}
+	return bundle, nil
+}
+
+// ExtractRegistryCredentialsAndCABundle returns both the credentials for the
+// image, as ExtractRegistryCredentialsWithOptions does, and the registry CA
+// bundle, for secrets bundling the Docker config with the registry CA. The
+// CA bundle is nil if the secret does not carry one.
+func ExtractRegistryCredentialsAndCABundle(secret *corev1.Secret, imageURL string, opts ExtractOptions) (string, []byte, error) {
+	credentials, err := ExtractRegistryCredentialsWithOptions(secret, imageURL, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	bundle, err := ExtractRegistryCABundle(secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return credentials, bundle, nil
+}
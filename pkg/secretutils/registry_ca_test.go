@@ -0,0 +1,114 @@
+package secretutils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newTestCABundle(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "registry-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestExtractRegistryCredentialsAndCABundle(t *testing.T) {
+	bundle := newTestCABundle(t)
+	combined := createDockerConfigJSONSecret("test-secret", map[string]map[string]string{
+		"registry.example.com": {
+			"username": "testuser",
+			"password": "testpass",
+		},
+	})
+	combined.Data[RegistryCABundleKey] = bundle
+
+	credentials, caBundle, err := ExtractRegistryCredentialsAndCABundle(combined, "oci://registry.example.com/repo/image:tag", ExtractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := base64.StdEncoding.EncodeToString([]byte("testuser:testpass"))
+	if credentials != expected {
+		t.Errorf("expected credentials %q, got %q", expected, credentials)
+	}
+	if !bytes.Equal(caBundle, bundle) {
+		t.Error("expected the CA bundle to be returned")
+	}
+
+	// The CA key must not get in the way of the credential lookup.
+	plainCredentials, err := ExtractRegistryCredentials(combined, "oci://registry.example.com/repo/image:tag")
+	if err != nil || plainCredentials != expected {
+		t.Errorf("expected credentials from a combined secret, got %q, %v", plainCredentials, err)
+	}
+}
+
+func TestExtractRegistryCABundle(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        map[string][]byte
+		expectCA    bool
+		expectError bool
+	}{
+		{
+			name:     "valid bundle",
+			data:     map[string][]byte{RegistryCABundleKey: newTestCABundle(t)},
+			expectCA: true,
+		},
+		{
+			name: "no bundle",
+			data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {}}`)},
+		},
+		{
+			name:        "not PEM",
+			data:        map[string][]byte{RegistryCABundleKey: []byte("not a certificate")},
+			expectError: true,
+		},
+		{
+			name:        "corrupted certificate",
+			data:        map[string][]byte{RegistryCABundleKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("junk")})},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caBundle, err := ExtractRegistryCABundle(&corev1.Secret{Data: tt.data})
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (caBundle != nil) != tt.expectCA {
+				t.Errorf("expected CA bundle present=%v, got %v", tt.expectCA, caBundle != nil)
+			}
+		})
+	}
+}
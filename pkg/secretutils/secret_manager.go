@@ -146,6 +146,28 @@ func (sm *SecretManager) ObtainSecret(ctx context.Context, key types.NamespacedN
 	return sm.obtainSecretForOwner(ctx, key, nil, false)
 }
 
+// ObtainFreshSecret is like ObtainSecret, but always reads the Secret from the
+// k8s API rather than the cache. It is meant for occasional reads that must
+// observe the latest version, such as right after a credential rotation, since
+// every call adds load on the API server.
+func (sm *SecretManager) ObtainFreshSecret(ctx context.Context, key types.NamespacedName) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := sm.apiReader.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to fetch secret %s in namespace %s: %w", key.Name, key.Namespace, err)
+	}
+	if err := sm.claimSecret(ctx, secret, nil, false); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// ReadSecret retrieves a Secret, from the cache if it is available and from
+// the k8s API if not, without labelling or otherwise updating it. It is meant
+// for secrets that are only inspected, not used.
+func (sm *SecretManager) ReadSecret(ctx context.Context, key types.NamespacedName) (*corev1.Secret, error) {
+	return sm.findSecret(ctx, key)
+}
+
 // ReleaseSecret removes secrets manager finalizer from specified secret when needed.
 func (sm *SecretManager) ReleaseSecret(ctx context.Context, secret *corev1.Secret) error {
 	if !slices.Contains(secret.Finalizers, SecretsFinalizer) {
@@ -403,6 +403,43 @@ func TestSecretManager_NotInCacheButInAPI(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+func TestSecretManager_ObtainFreshSecret(t *testing.T) {
+	scheme := newTestScheme()
+
+	newSecret := func(value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rotated-secret",
+				Namespace: "test",
+				Labels: map[string]string{
+					LabelEnvironmentName: LabelEnvironmentValue,
+				},
+			},
+			Data: map[string][]byte{
+				"data": []byte(value),
+			},
+		}
+	}
+
+	// The cache still holds the secret from before the rotation
+	cacheClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newSecret("old")).Build()
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newSecret("new")).Build()
+
+	sm := NewSecretManager(logr.Discard(), cacheClient, apiReader)
+	key := types.NamespacedName{Name: "rotated-secret", Namespace: "test"}
+
+	cached, err := sm.ObtainSecret(t.Context(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(cached.Data["data"]))
+
+	fresh, err := sm.ObtainFreshSecret(t.Context(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(fresh.Data["data"]))
+
+	_, err = sm.ObtainFreshSecret(t.Context(), types.NamespacedName{Name: "nonexistent", Namespace: "test"})
+	require.Error(t, err)
+}
+
 func TestSecretManager_ReleaseSecret(t *testing.T) {
 	scheme := newTestScheme()
 	secret := &corev1.Secret{